@@ -0,0 +1,612 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package arnoldi
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "math/cmplx"
+import   "math/rand"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/krylov"
+import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
+
+/* -------------------------------------------------------------------------- */
+
+// Select the eigenvalues that are computed: largest magnitude ("LM",
+// default), smallest magnitude ("SM"), largest real part ("LR") or
+// smallest real part ("SR")
+type Which struct {
+  Value string
+}
+
+// A Ritz pair (theta, x) is accepted if ||A x - theta x|| is smaller
+// than epsilon times |theta|
+type Epsilon struct {
+  Value float64
+}
+
+// Maximum number of restarts
+type MaxIterations struct {
+  Value int
+}
+
+// Dimension of the Krylov subspace, which must be larger than the number
+// of requested eigenvalues (default max(2k+1, 20))
+type SubspaceDimension struct {
+  Value int
+}
+
+// Initial vector of the Krylov subspace (random by default)
+type V0 struct {
+  Value ConstVector
+}
+
+// Dimension of the linear operator, required if A is given as an
+// operator
+type Dimension struct {
+  Value int
+}
+
+/* -------------------------------------------------------------------------- */
+
+type Info struct {
+  // number of converged eigenpairs
+  Converged  int
+  // number of restarts
+  Iterations int
+  // number of matrix vector products
+  MatVecs    int
+  // residual norms ||A x - lambda x|| of the returned eigenpairs
+  Residuals  []float64
+}
+
+/* -------------------------------------------------------------------------- */
+
+type options struct {
+  which             Which
+  epsilon           Epsilon
+  maxIterations     MaxIterations
+  subspaceDimension SubspaceDimension
+  v0                V0
+  dimension         Dimension
+}
+
+func getOperator(a interface{}, args ...interface{}) (krylov.Operator, int) {
+  n := -1
+  for _, arg := range args {
+    if tmp, ok := arg.(Dimension); ok {
+      n = tmp.Value
+    }
+  }
+  switch a := a.(type) {
+  case ConstMatrix:
+    n1, n2 := a.Dims()
+    if n1 != n2 {
+      panic("Arnoldi(): Not a square matrix!")
+    }
+    return func(r Vector, x ConstVector) { r.MdotV(a, x) }, n1
+  case krylov.Operator:
+    if n < 0 {
+      panic("Arnoldi(): Operator requires Dimension argument!")
+    }
+    return a, n
+  case func(Vector, ConstVector):
+    if n < 0 {
+      panic("Arnoldi(): Operator requires Dimension argument!")
+    }
+    return a, n
+  default:
+    panic("invalid linear operator")
+  }
+}
+
+func getOptions(n, k int, args ...interface{}) options {
+  opts := options{}
+  opts.which             = Which            {"LM"}
+  opts.epsilon           = Epsilon          {1e-10}
+  opts.maxIterations     = MaxIterations    {300}
+  opts.subspaceDimension = SubspaceDimension{2*k+1}
+  if opts.subspaceDimension.Value < 20 {
+    opts.subspaceDimension.Value = 20
+  }
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Which:
+      opts.which = a
+    case Epsilon:
+      opts.epsilon = a
+    case MaxIterations:
+      opts.maxIterations = a
+    case SubspaceDimension:
+      opts.subspaceDimension = a
+    case V0:
+      opts.v0 = a
+    case Dimension:
+      opts.dimension = a
+    default:
+      panic("Arnoldi(): Invalid optional argument!")
+    }
+  }
+  if opts.subspaceDimension.Value > n {
+    opts.subspaceDimension.Value = n
+  }
+  return opts
+}
+
+/* -------------------------------------------------------------------------- */
+
+func dot(a, b DenseFloat64Vector) float64 {
+  r := 0.0
+  for i := range a {
+    r += a[i]*b[i]
+  }
+  return r
+}
+
+func norm(a DenseFloat64Vector) float64 {
+  return math.Sqrt(dot(a, a))
+}
+
+// a = a + c b
+func axpy(a DenseFloat64Vector, c float64, b DenseFloat64Vector) {
+  for i := range a {
+    a[i] += c*b[i]
+  }
+}
+
+func scale(a DenseFloat64Vector, c float64) {
+  for i := range a {
+    a[i] *= c
+  }
+}
+
+// Initialize x with a random unit vector orthogonal to all vectors in V.
+// If V spans the full space, x is set to zero.
+func randomOrthogonal(x DenseFloat64Vector, V []DenseFloat64Vector, rng *rand.Rand) {
+  for i := range x {
+    x[i] = rng.NormFloat64()
+  }
+  orthonormalize(x, V, rng, false)
+}
+
+// Orthogonalize x against all vectors in V and normalize x. If x is
+// linearly dependent on V, a random vector is used instead.
+func orthonormalize(x DenseFloat64Vector, V []DenseFloat64Vector, rng *rand.Rand, retry bool) {
+  r := norm(x)
+  for k := 0; k < 2; k++ {
+    for _, v := range V {
+      axpy(x, -dot(v, x), v)
+    }
+  }
+  if s := norm(x); s > 1e-8*r {
+    scale(x, 1.0/s)
+  } else if retry {
+    randomOrthogonal(x, V, rng)
+  } else {
+    x.Reset()
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Extend the Arnoldi basis from l to m vectors such that A V_m = V_m H_m +
+// h_{m+1,m} v_{m+1} e_m^T
+func expand(f krylov.Operator, V []DenseFloat64Vector, H [][]float64, w DenseFloat64Vector, l int, rng *rand.Rand, info *Info) {
+  m := len(V)-1
+  for j := l; j < m; j++ {
+    f(w, V[j])
+    info.MatVecs++
+    r := norm(w)
+    // classical Gram-Schmidt with reorthogonalization
+    for k := 0; k < 2; k++ {
+      for i := 0; i <= j; i++ {
+        h := dot(V[i], w)
+        H[i][j] += h
+        axpy(w, -h, V[i])
+      }
+    }
+    if beta := norm(w); beta > 1e-12*r {
+      H[j+1][j] = beta
+      V[j+1].Set(w)
+      scale(V[j+1], 1.0/beta)
+    } else {
+      // invariant subspace found, continue with a new
+      // random vector
+      H[j+1][j] = 0.0
+      randomOrthogonal(V[j+1], V[0:j+1], rng)
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+type ritzPair struct {
+  lambda complex128
+  // eigenvector of the projected matrix
+  y      []complex128
+}
+
+// Compute an eigenvector of the quasi upper triangular matrix T for the
+// eigenvalue lambda of the diagonal block starting at row k
+func schurEigenvector(T [][]float64, k int, lambda complex128, smin float64) []complex128 {
+  m := len(T)
+  z := make([]complex128, m)
+  e := k
+  if k < m-1 && T[k+1][k] != 0.0 {
+    // eigenvector of the 2x2 block
+    z[k]   = complex(T[k][k+1], 0.0)
+    z[k+1] = lambda - complex(T[k][k], 0.0)
+    e = k+1
+  } else {
+    z[k] = 1.0
+  }
+  // back substitution
+  for i := k-1; i >= 0; i-- {
+    if i > 0 && T[i][i-1] != 0.0 {
+      // 2x2 block at rows i-1 and i
+      r1 := complex(0.0, 0.0)
+      r2 := complex(0.0, 0.0)
+      for j := i+1; j <= e; j++ {
+        r1 -= complex(T[i-1][j], 0.0)*z[j]
+        r2 -= complex(T[i  ][j], 0.0)*z[j]
+      }
+      a := complex(T[i-1][i-1], 0.0) - lambda
+      b := complex(T[i-1][i  ], 0.0)
+      c := complex(T[i  ][i-1], 0.0)
+      d := complex(T[i  ][i  ], 0.0) - lambda
+      det := a*d - b*c
+      if cmplx.Abs(det) < smin {
+        det = complex(smin, 0.0)
+      }
+      z[i-1] = (r1*d - b*r2)/det
+      z[i  ] = (a*r2 - c*r1)/det
+      i--
+    } else {
+      r := complex(0.0, 0.0)
+      for j := i+1; j <= e; j++ {
+        r -= complex(T[i][j], 0.0)*z[j]
+      }
+      d := complex(T[i][i], 0.0) - lambda
+      if cmplx.Abs(d) < smin {
+        d = complex(smin, 0.0)
+      }
+      z[i] = r/d
+    }
+  }
+  return z
+}
+
+// Compute eigenvalues and eigenvectors of the projected matrix, sorted
+// such that the wanted eigenvalues come first. Complex conjugate pairs
+// are adjacent with the positive imaginary part first.
+func ritzPairs(H [][]float64, m int, which string) ([]ritzPair, error) {
+  var key func(lambda complex128) float64
+  switch which {
+  case "LM": key = func(lambda complex128) float64 { return -cmplx.Abs(lambda) }
+  case "SM": key = func(lambda complex128) float64 { return  cmplx.Abs(lambda) }
+  case "LR": key = func(lambda complex128) float64 { return -real(lambda) }
+  case "SR": key = func(lambda complex128) float64 { return  real(lambda) }
+  default:
+    return nil, fmt.Errorf("invalid eigenvalue selection `%s'", which)
+  }
+  A := NullDenseFloat64Matrix(m, m)
+  for i := 0; i < m; i++ {
+    for j := 0; j < m; j++ {
+      A.At(i, j).SetFloat64(H[i][j])
+    }
+  }
+  h, u, err := qrAlgorithm.Run(A,
+    qrAlgorithm.ComputeU{Value: true},
+    qrAlgorithm.Epsilon {Value: 1e-15})
+  if err != nil {
+    return nil, err
+  }
+  T    := make([][]float64, m)
+  smin := 0.0
+  for i := 0; i < m; i++ {
+    T[i] = make([]float64, m)
+    for j := 0; j < m; j++ {
+      T[i][j] = h.ConstAt(i, j).GetFloat64()
+      smin    = math.Max(smin, math.Abs(T[i][j]))
+    }
+  }
+  smin = math.Max(1e-15*smin, 1e-300)
+  r := []ritzPair{}
+  for k := 0; k < m; k++ {
+    var lambda []complex128
+    if k < m-1 && T[k+1][k] != 0.0 {
+      // complex conjugate pair
+      a, b, c, d := T[k][k], T[k][k+1], T[k+1][k], T[k+1][k+1]
+      im := math.Sqrt(-((a-d)*(a-d) + 4.0*b*c))/2.0
+      lambda = []complex128{complex((a+d)/2.0, im), complex((a+d)/2.0, -im)}
+    } else {
+      lambda = []complex128{complex(T[k][k], 0.0)}
+    }
+    z := schurEigenvector(T, k, lambda[0], smin)
+    // y = U z
+    y := make([]complex128, m)
+    s := 0.0
+    for i := 0; i < m; i++ {
+      for j := 0; j < m; j++ {
+        y[i] += complex(u.ConstAt(i, j).GetFloat64(), 0.0)*z[j]
+      }
+      s += real(y[i])*real(y[i]) + imag(y[i])*imag(y[i])
+    }
+    for i := 0; i < m; i++ {
+      y[i] /= complex(math.Sqrt(s), 0.0)
+    }
+    r = append(r, ritzPair{lambda[0], y})
+    if len(lambda) == 2 {
+      yc := make([]complex128, m)
+      for i := 0; i < m; i++ {
+        yc[i] = cmplx.Conj(y[i])
+      }
+      r = append(r, ritzPair{lambda[1], yc})
+      k++
+    }
+  }
+  sort.SliceStable(r, func(i, j int) bool {
+    if ki, kj := key(r[i].lambda), key(r[j].lambda); ki != kj {
+      return ki < kj
+    }
+    return imag(r[i].lambda) > imag(r[j].lambda)
+  })
+  return r, nil
+}
+
+// Returns true if the ith Ritz value is the first element of a complex
+// conjugate pair
+func isPairStart(r []ritzPair, i int) bool {
+  return imag(r[i].lambda) > 0.0
+}
+
+// Compute x = V y
+func ritzVector(x DenseFloat64Vector, V []DenseFloat64Vector, y []float64) {
+  x.Reset()
+  for i, v := range V {
+    axpy(x, y[i], v)
+  }
+}
+
+// Split complex eigenvectors of the projected matrix into real and
+// imaginary parts, the first column of a conjugate pair stores the
+// real part and the second column the imaginary part.
+func realBasis(r []ritzPair, l int) [][]float64 {
+  Y := make([][]float64, l)
+  for j := 0; j < l; j++ {
+    m := len(r[j].y)
+    Y[j] = make([]float64, m)
+    for i := 0; i < m; i++ {
+      if imag(r[j].lambda) < 0.0 {
+        Y[j][i] = imag(r[j-1].y[i])
+      } else {
+        Y[j][i] = real(r[j].y[i])
+      }
+    }
+  }
+  return Y
+}
+
+/* -------------------------------------------------------------------------- */
+
+func arnoldi(f krylov.Operator, n, k int, opts options) (Vector, Vector, Matrix, Info, error) {
+  m    := opts.subspaceDimension.Value
+  info := Info{}
+  rng  := rand.New(rand.NewSource(1))
+
+  V := make([]DenseFloat64Vector, m+1)
+  for j := range V {
+    V[j] = NullDenseFloat64Vector(n)
+  }
+  H := make([][]float64, m+1)
+  for i := range H {
+    H[i] = make([]float64, m)
+  }
+  w := NullDenseFloat64Vector(n)
+  // initial vector
+  if opts.v0.Value != nil {
+    if opts.v0.Value.Dim() != n {
+      return nil, nil, nil, info, fmt.Errorf("initial vector has invalid dimension")
+    }
+    V[0].Set(opts.v0.Value)
+    if r := norm(V[0]); r == 0.0 {
+      return nil, nil, nil, info, fmt.Errorf("initial vector is zero")
+    } else {
+      scale(V[0], 1.0/r)
+    }
+  } else {
+    randomOrthogonal(V[0], nil, rng)
+  }
+  // number of Ritz vectors kept at a restart
+  l := 0
+  for {
+    expand(f, V, H, w, l, rng, &info)
+
+    r, err := ritzPairs(H, m, opts.which.Value)
+    if err != nil {
+      return nil, nil, nil, info, err
+    }
+    // do not split complex conjugate pairs
+    kk := k
+    if isPairStart(r, kk-1) && kk < m {
+      kk++
+    }
+    beta := H[m][m-1]
+    // check convergence of the wanted Ritz pairs using the residual
+    // estimate ||A x - theta x|| = |beta y_m|
+    info.Converged = 0
+    for i := 0; i < kk; i++ {
+      if beta*cmplx.Abs(r[i].y[m-1]) <= opts.epsilon.Value*math.Max(cmplx.Abs(r[i].lambda), 1e-10) {
+        info.Converged++
+      }
+    }
+    if info.Converged == kk || info.Iterations >= opts.maxIterations.Value {
+      re := NullDenseFloat64Vector(kk)
+      im := NullDenseFloat64Vector(kk)
+      x  := NullDenseFloat64Matrix(n, kk)
+      Y  := realBasis(r, kk)
+      for j := 0; j < kk; j++ {
+        ritzVector(w, V[0:m], Y[j])
+        ColView(x, j).Set(w)
+        re[j] = real(r[j].lambda)
+        im[j] = imag(r[j].lambda)
+      }
+      info.Residuals = residuals(f, re, im, x, &info)
+      if info.Converged < kk {
+        return re, im, x, info, fmt.Errorf("maximum number of iterations reached")
+      }
+      return re, im, x, info, nil
+    }
+    info.Iterations++
+    // restart with an orthonormal basis of the l wanted Ritz vectors, the
+    // residual vector v_m becomes the next basis vector
+    l = kk + (m-kk)/2
+    if l > m-1 {
+      l = m-1
+    }
+    if isPairStart(r, l-1) {
+      if l < m-1 {
+        l++
+      } else {
+        l--
+      }
+    }
+    // orthonormalize Ritz vectors of the projected matrix
+    Q := realBasis(r, l)
+    for j := 0; j < l; j++ {
+      orthonormalize(Q[j], castVectors(Q[0:j]), rng, true)
+    }
+    // S = Q^T H Q
+    S := make([][]float64, l)
+    for i := 0; i < l; i++ {
+      S[i] = make([]float64, l)
+      for j := 0; j < l; j++ {
+        for p := 0; p < m; p++ {
+          for q := 0; q < m; q++ {
+            S[i][j] += Q[i][p]*H[p][q]*Q[j][q]
+          }
+        }
+      }
+    }
+    U := make([]DenseFloat64Vector, l)
+    for j := 0; j < l; j++ {
+      U[j] = NullDenseFloat64Vector(n)
+      ritzVector(U[j], V[0:m], Q[j])
+    }
+    V[l].Set(V[m])
+    for j := 0; j < l; j++ {
+      V[j].Set(U[j])
+    }
+    for i := range H {
+      for j := range H[i] {
+        H[i][j] = 0.0
+      }
+    }
+    for i := 0; i < l; i++ {
+      for j := 0; j < l; j++ {
+        H[i][j] = S[i][j]
+      }
+      H[l][i] = beta*Q[i][m-1]
+    }
+    if beta == 0.0 {
+      randomOrthogonal(V[l], V[0:l], rng)
+    }
+  }
+}
+
+func castVectors(x [][]float64) []DenseFloat64Vector {
+  r := make([]DenseFloat64Vector, len(x))
+  for i := range x {
+    r[i] = x[i]
+  }
+  return r
+}
+
+// Compute residual norms ||A x - lambda x||, where complex eigenvectors
+// are stored as pairs of real and imaginary parts
+func residuals(f krylov.Operator, re, im DenseFloat64Vector, x Matrix, info *Info) []float64 {
+  n, k := x.Dims()
+  r := make([]float64, k)
+  w := NullDenseFloat64Vector(n)
+  for j := 0; j < k; j++ {
+    if im[j] == 0.0 {
+      xj := ColView(x, j)
+      f(w, xj)
+      info.MatVecs++
+      for i := 0; i < n; i++ {
+        w[i] -= re[j]*xj.ConstAt(i).GetFloat64()
+      }
+      r[j] = norm(w)
+    } else {
+      // A (xr + i xi) = (a + ib)(xr + i xi)
+      xr := ColView(x, j)
+      xi := ColView(x, j+1)
+      a, b := re[j], im[j]
+      s := 0.0
+      f(w, xr)
+      for i := 0; i < n; i++ {
+        w[i] -= a*xr.ConstAt(i).GetFloat64() - b*xi.ConstAt(i).GetFloat64()
+      }
+      s += dot(w, w)
+      f(w, xi)
+      for i := 0; i < n; i++ {
+        w[i] -= b*xr.ConstAt(i).GetFloat64() + a*xi.ConstAt(i).GetFloat64()
+      }
+      s += dot(w, w)
+      info.MatVecs += 2
+      r[j  ] = math.Sqrt(s)
+      r[j+1] = math.Sqrt(s)
+      j++
+    }
+  }
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute k eigenvalues and eigenvectors of a large general matrix A with
+// a restarted Arnoldi method. At every restart the Krylov subspace is
+// compressed to the invariant subspace of the projected matrix spanned by
+// the wanted Ritz vectors (Krylov-Schur restart), which is equivalent to
+// implicit restarting with the unwanted Ritz values as shifts. The argument
+// a is either a ConstMatrix (e.g. a SparseFloat64Matrix) or an operator
+// that computes matrix vector products, in which case the Dimension option
+// is required.
+//
+// Real and imaginary parts of the eigenvalues are returned as separate
+// vectors. Complex eigenvalues appear in conjugate pairs with the positive
+// imaginary part first, the corresponding columns j and j+1 of the
+// eigenvector matrix contain the real and imaginary part of the eigenvector
+// of the first eigenvalue. If the kth eigenvalue is the first element of a
+// complex conjugate pair, k+1 eigenpairs are returned. An error is returned
+// if not all eigenpairs converged, in which case the current approximations
+// are returned as well.
+func Run(a interface{}, k int, args ...interface{}) (Vector, Vector, Matrix, Info, error) {
+  f, n := getOperator(a, args...)
+  if k < 1 || k > n {
+    return nil, nil, nil, Info{}, fmt.Errorf("invalid number of eigenvalues")
+  }
+  opts := getOptions(n, k, args...)
+  if m := opts.subspaceDimension.Value; m < k || (m == k && m < n) {
+    return nil, nil, nil, Info{}, fmt.Errorf("subspace dimension must be larger than the number of eigenvalues")
+  }
+  return arnoldi(f, n, k, opts)
+}
@@ -0,0 +1,130 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package arnoldi
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// upper bidiagonal matrix with eigenvalues 1, 2, ..., n-2 and a 2x2 block
+// with eigenvalues n +/- 3i
+func testMatrix(n int) Matrix {
+  r := NullSparseFloat64Matrix(n, n)
+  for i := 0; i < n-2; i++ {
+    r.At(i, i  ).SetFloat64(float64(i+1))
+    r.At(i, i+1).SetFloat64(0.5)
+  }
+  r.At(n-2, n-2).SetFloat64(float64(n))
+  r.At(n-2, n-1).SetFloat64( 3)
+  r.At(n-1, n-2).SetFloat64(-3)
+  r.At(n-1, n-1).SetFloat64(float64(n))
+  return r
+}
+
+func checkEigenpairs(test *testing.T, a ConstMatrix, re, im Vector, x Matrix) {
+  n, k := x.Dims()
+  w := NullDenseFloat64Vector(n)
+  for j := 0; j < k; j++ {
+    xr := x.Col(j)
+    s  := 0.0
+    if im.ConstAt(j).GetFloat64() == 0.0 {
+      w.MdotV(a, xr)
+      w.VsubV(w, xr.VmulS(xr, re.ConstAt(j)))
+      s = dot(w, w)
+    } else {
+      xi := x.Col(j+1)
+      a_ := re.ConstAt(j).GetFloat64()
+      b_ := im.ConstAt(j).GetFloat64()
+      w.MdotV(a, xr)
+      for i := 0; i < n; i++ {
+        w[i] -= a_*xr.ConstAt(i).GetFloat64() - b_*xi.ConstAt(i).GetFloat64()
+      }
+      s += dot(w, w)
+      w.MdotV(a, xi)
+      for i := 0; i < n; i++ {
+        w[i] -= b_*xr.ConstAt(i).GetFloat64() + a_*xi.ConstAt(i).GetFloat64()
+      }
+      s += dot(w, w)
+      j++
+    }
+    if math.Sqrt(s) > 1e-6 {
+      test.Errorf("test failed for eigenvector `%d'", j)
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func TestArnoldi1(test *testing.T) {
+  n := 100
+  a := testMatrix(n)
+
+  re, im, x, info, err := Run(a, 3)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if !re.Equals(NewDenseFloat64Vector([]float64{100, 100, 98}), 1e-8) {
+    test.Error("test failed")
+  }
+  if !im.Equals(NewDenseFloat64Vector([]float64{3, -3, 0}), 1e-8) {
+    test.Error("test failed")
+  }
+  checkEigenpairs(test, a, re, im, x)
+  if info.Converged != 3 || len(info.Residuals) != 3 {
+    test.Error("test failed")
+  }
+}
+
+func TestArnoldi2(test *testing.T) {
+  n := 100
+  a := testMatrix(n)
+  f := func(r Vector, x ConstVector) {
+    r.MdotV(a, x)
+  }
+  // the pair n +/- 3i is not split
+  re, im, x, _, err := Run(f, 1, Dimension{n})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if re.Dim() != 2 || math.Abs(im.ConstAt(0).GetFloat64() - 3) > 1e-8 {
+    test.Error("test failed")
+  }
+  checkEigenpairs(test, a, re, im, x)
+}
+
+func TestArnoldi3(test *testing.T) {
+  n := 100
+  a := testMatrix(n)
+
+  re, im, x, _, err := Run(a, 2, Which{"SR"})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if !re.Equals(NewDenseFloat64Vector([]float64{1, 2}), 1e-8) {
+    test.Error("test failed")
+  }
+  checkEigenpairs(test, a, re, im, x)
+}
@@ -40,13 +40,44 @@ func backSubstitution(inSitu *InSitu, b Vector) (Vector, error) {
 
   _, n := A.Dims()
 
+  // number of superdiagonals that may contain non-zero elements
+  ku := n
+  switch a := A.(type) {
+  case *DiagonalMatrix:
+    ku = 0
+  case *BandMatrix:
+    _, ku = a.Bandwidth()
+  }
   for i := n-1; i >= 0; i-- {
     if b == nil {
       x.At(i).SetFloat64(0.0)
     } else {
       x.At(i).Set(b.ConstAt(i))
     }
-    for j := i+1; j < n; j++ {
+    for j := i+1; j < n && j <= i+ku; j++ {
+      t.Mul(A.ConstAt(i,j), x.ConstAt(j))
+      x.At(i).Sub(x.ConstAt(i), t)
+    }
+    x.At(i).Div(x.ConstAt(i), A.ConstAt(i,i))
+  }
+  return x, nil
+}
+
+func forwardSubstitution(inSitu *InSitu, b Vector) (Vector, error) {
+
+  A := inSitu.A
+  x := inSitu.X
+  t := inSitu.T
+
+  _, n := A.Dims()
+
+  for i := 0; i < n; i++ {
+    if b == nil {
+      x.At(i).SetFloat64(0.0)
+    } else {
+      x.At(i).Set(b.ConstAt(i))
+    }
+    for j := 0; j < i; j++ {
       t.Mul(A.ConstAt(i,j), x.ConstAt(j))
       x.At(i).Sub(x.ConstAt(i), t)
     }
@@ -57,6 +88,9 @@ func backSubstitution(inSitu *InSitu, b Vector) (Vector, error) {
 
 /* -------------------------------------------------------------------------- */
 
+// Solve Ax = b, where A is an upper triangular matrix. Structured matrices
+// are not converted to dense matrices. If A is a LowerTriangularMatrix,
+// the system is solved by forward substitution.
 func Run(A Matrix, b Vector, args ...interface{}) (Vector, error) {
   m, n := A.Dims()
   t    := A.ElementType()
@@ -95,5 +129,8 @@ func Run(A Matrix, b Vector, args ...interface{}) (Vector, error) {
   if inSitu.T == nil {
     inSitu.T = NullScalar(t)
   }
+  if _, ok := inSitu.A.(*LowerTriangularMatrix); ok {
+    return forwardSubstitution(inSitu, b)
+  }
   return backSubstitution(inSitu, b)
 }
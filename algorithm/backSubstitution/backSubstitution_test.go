@@ -43,3 +43,34 @@ func Test1(test *testing.T) {
     test.Error("test failed")
   }
 }
+
+func Test2(test *testing.T) {
+  a := AsUpperTriangularMatrix(Float64Type, NewDenseFloat64Matrix([]float64{
+    1, -2,  1,
+    0,  1,  6,
+    0,  0,  1 }, 3, 3))
+  b := NewDenseFloat64Vector([]float64{
+    4, -1, 2 })
+  r := NewDenseFloat64Vector([]float64{
+    -24, -13, 2 })
+  t := NewFloat64(0.0)
+
+  // upper triangular system
+  if x, err := Run(a, b); err != nil {
+    test.Error(err)
+  } else {
+    if t.Vnorm(x.VsubV(r, x)).GetFloat64() > 1e-8 {
+      test.Error("test failed")
+    }
+  }
+  // lower triangular system
+  if x, err := Run(a.T(), r); err != nil {
+    test.Error(err)
+  } else {
+    s := NullDenseFloat64Vector(3)
+    s.MdotV(a.T(), x)
+    if t.Vnorm(s.VsubV(s, r)).GetFloat64() > 1e-8 {
+      test.Error("test failed")
+    }
+  }
+}
@@ -30,6 +30,7 @@ import   "math"
 import . "github.com/pbenner/autodiff"
 import   "github.com/pbenner/autodiff/algorithm/lineSearch"
 import   "github.com/pbenner/autodiff/algorithm/matrixInverse"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -55,6 +56,10 @@ type Constraints struct {
   Value func(x Vector) bool
 }
 
+// Returned if the line search fails to find a new position. The position
+// of the last iteration is returned together with this error.
+var ErrLineSearch = fmt.Errorf("line search failed")
+
 /* -------------------------------------------------------------------------- */
 
 type ObjectiveInSitu struct {
@@ -256,7 +261,7 @@ func bfgs(f_ Objective, f ObjectiveInSitu, x0 Vector, H0 Matrix, epsilon Epsilon
       // reset H to find a new direction
       if first_update {
         // the initial matrix H seems invalid, stop optimization here
-        return x1, fmt.Errorf("line search failed")
+        return x1, ErrLineSearch
       } else {
         first_update = true
         H2.Set(H0)
@@ -348,3 +353,65 @@ func Run(f Objective, x0 Vector, args ...interface{}) (Vector, error) {
   }
   return bfgs(f, newObjectiveInSitu(f), x0, H, epsilon, maxIterations, hook, constraints)
 }
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. Accepts the same optional arguments as Run and
+// additionally optimize.Trace.
+func Minimize(f Objective, x0 Vector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook    := Hook   { nil}
+  epsilon := Epsilon{1e-8}
+  options := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // the hook is called at the initial value and after every iteration
+  first := true
+  options = append(options, Hook{func(x, gradient ConstVector, y ConstScalar) bool {
+    if first {
+      r.Init(x, y.GetFloat64(), optimize.Norm(gradient)); first = false
+    } else {
+      r.Iteration(x, y.GetFloat64(), optimize.Norm(gradient))
+    }
+    return hook.Value != nil && r.Hook(hook.Value(x, gradient, y))
+  }})
+  x, err := Run(g, x0, options...)
+  if x == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  y, gnorm, e := optimize.Evaluate(f, x)
+  // Run stops without calling the hook if x0 already satisfies the stop
+  // criterion, in which case the initial state is recorded here
+  if first {
+    r.Init(x, y, gnorm)
+  }
+  switch {
+  case err == ErrLineSearch:
+    return r.Result(x, y, gnorm, optimize.Stalled), nil
+  case err != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), err
+  case e != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), e
+  case gnorm < epsilon.Value:
+    return r.Result(x, y, gnorm, optimize.Converged), nil
+  default:
+    return r.Result(x, y, gnorm, optimize.MaxIterationsReached), nil
+  }
+}
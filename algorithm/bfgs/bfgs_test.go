@@ -23,6 +23,7 @@ import   "os"
 import   "testing"
 
 import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -117,3 +118,43 @@ func TestBfgsRosenbrock(test *testing.T) {
   }
   os.Remove("bfgs_test2.table")
 }
+
+func TestBfgsMinimize(test *testing.T) {
+  f := func(x ConstVector) (MagicScalar, error) {
+    // f(x1, x2) = (a - x1)^2 + b(x2 - x1^2)^2
+    a  := ConstFloat64(  1.0)
+    b  := ConstFloat64(100.0)
+    c  := ConstFloat64(  2.0)
+    t1 := NullReal64()
+    t2 := NullReal64()
+    t1.Mul(b, t1.Pow(t1.Sub(x.ConstAt(1), t1.Mul(x.ConstAt(0), x.ConstAt(0))), c))
+    t2.Pow(t2.Sub(a, x.ConstAt(0)), c)
+    t1.Add(t1, t2)
+    return t1, nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{-0.5, 2})
+
+  r, err := Minimize(f, x0, Epsilon{1e-10}, optimize.Trace{Value: true})
+  if err != nil {
+    test.Error(err)
+  }
+  if r.Status != optimize.Converged || r.GradientNorm >= 1e-10 || r.Value > 1e-16 {
+    test.Error("test failed")
+  }
+  if r.Iterations == 0 || r.Evaluations <= r.Iterations || len(r.Trace) != r.Iterations+1 {
+    test.Error("test failed")
+  }
+  if r, _ := Minimize(f, x0, MaxIterations{3}); r.Status != optimize.MaxIterationsReached || r.Iterations > 3 {
+    test.Error("test failed")
+  }
+  hook := Hook{func(x, gradient ConstVector, y ConstScalar) bool { return true }}
+  if r, _ := Minimize(f, x0, hook); r.Status != optimize.HookStopped || r.Iterations != 0 {
+    test.Error("test failed")
+  }
+  // the initial value is recorded if it already satisfies the stop
+  // criterion
+  x1 := NewDenseFloat64Vector([]float64{1, 1})
+  if r, _ := Minimize(f, x1, optimize.Trace{Value: true}); r.Status != optimize.Converged || r.Iterations != 0 || len(r.Trace) != 1 {
+    test.Error("test failed")
+  }
+}
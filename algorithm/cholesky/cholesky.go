@@ -18,9 +18,11 @@ package cholesky
 
 /* -------------------------------------------------------------------------- */
 
-//import   "fmt"
+import   "fmt"
 
 import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/condition"
+import   "github.com/pbenner/autodiff/algorithm/matrixNorm"
 
 /* -------------------------------------------------------------------------- */
 
@@ -41,6 +43,12 @@ type ForcePD struct {
 
 /* -------------------------------------------------------------------------- */
 
+// Compute the Cholesky decomposition of a. If a is a SymmetricPackedMatrix,
+// the factor L is returned as a LowerTriangularMatrix and D as a
+// DiagonalMatrix, unless other matrices are passed with InSitu. The
+// gonum backend is used for DenseFloat64Matrix types if enabled with
+// SetGonumBackend (not for LDL decompositions). The condition number of a
+// is estimated and checked if a condition.Threshold is passed.
 func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, error) {
   n, m := a.Dims()
   if n != m {
@@ -54,8 +62,12 @@ func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, error) {
   ldl     := false
   forcePD := false
 
+  var threshold condition.Threshold
+
   for _, arg := range args {
     switch a := arg.(type) {
+    case condition.Threshold:
+      threshold = a
     case LDL:
       ldl = a.Value
     case ForcePD:
@@ -68,13 +80,23 @@ func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, error) {
       panic("Cholesky(): Invalid optional argument!")
     }
   }
+  // check if the input matrix has compact storage
+  _, packed := a.(*SymmetricPackedMatrix)
   // allocate memory
   if inSitu.L == nil {
-    inSitu.L = NullDenseMatrix(t, n, n)
+    if packed {
+      inSitu.L = NullLowerTriangularMatrix(t, n)
+    } else {
+      inSitu.L = NullDenseMatrix(t, n, n)
+    }
   }
   if ldl {
     if inSitu.D == nil {
-      inSitu.D = NullDenseMatrix(t, n, n)
+      if packed {
+        inSitu.D = NullDiagonalMatrix(t, n)
+      } else {
+        inSitu.D = NullDenseMatrix(t, n, n)
+      }
     } else {
       inSitu.D.Map(func(x Scalar) { x.SetFloat64(0.0) })
     }
@@ -85,6 +107,26 @@ func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, error) {
   if inSitu.T == nil {
     inSitu.T = NewScalar(t, 0.0)
   }
+  L, D, err := factorize(a, inSitu, ldl, forcePD)
+  if err != nil {
+    return nil, nil, err
+  }
+  if threshold.Value > 0.0 {
+    var d ConstMatrix
+    if ldl {
+      d = D
+    }
+    c, _ := Condition(a, L, d)
+    if err := threshold.Check(c); err != nil {
+      return nil, nil, err
+    }
+  }
+  return L, D, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+func factorize(a ConstMatrix, inSitu *InSitu, ldl, forcePD bool) (Matrix, Matrix, error) {
   if ldl {
     { // Float32
       A, ok1 :=        a.(*DenseFloat32Matrix)
@@ -136,9 +178,71 @@ func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, error) {
       s, ok3 := inSitu.S.( Float64)
       t, ok4 := inSitu.T.( Float64)
       if ok1 && ok2 && ok3 && ok4 {
+        if GonumBackend() {
+          return cholesky_gonum(A, L)
+        }
         return cholesky_float64(A, L, s, t)
       }
     }
     return cholesky(a, inSitu.L, inSitu.S, inSitu.T)
   }
 }
+
+/* -------------------------------------------------------------------------- */
+
+// Solve A x = b given the Cholesky decomposition A = L L^T or, if D is not
+// nil, the LDL decomposition A = L D L^T.
+func Solve(L, D ConstMatrix, b ConstVector) (Vector, error) {
+  n, _ := L.Dims()
+  if b.Dim() != n {
+    panic("vector dimensions do not match!")
+  }
+  x := NullDenseVector(L.ElementType(), n)
+  t := NullScalar(L.ElementType())
+  // forward substitution L y = b
+  for i := 0; i < n; i++ {
+    s := x.At(i)
+    s.Set(b.ConstAt(i))
+    for j := 0; j < i; j++ {
+      t.Mul(L.ConstAt(i, j), x.ConstAt(j))
+      s.Sub(s, t)
+    }
+    if D == nil {
+      if L.ConstAt(i, i).GetFloat64() == 0.0 {
+        return nil, fmt.Errorf("matrix is singular")
+      }
+      s.Div(s, L.ConstAt(i, i))
+    }
+  }
+  if D != nil {
+    for i := 0; i < n; i++ {
+      if D.ConstAt(i, i).GetFloat64() == 0.0 {
+        return nil, fmt.Errorf("matrix is singular")
+      }
+      x.At(i).Div(x.At(i), D.ConstAt(i, i))
+    }
+  }
+  // back substitution L^T x = y
+  for i := n-1; i >= 0; i-- {
+    s := x.At(i)
+    for j := i+1; j < n; j++ {
+      t.Mul(L.ConstAt(j, i), x.ConstAt(j))
+      s.Sub(s, t)
+    }
+    if D == nil {
+      s.Div(s, L.ConstAt(i, i))
+    }
+  }
+  return x, nil
+}
+
+// Estimate the 1-norm condition number of a symmetric positive definite
+// matrix A given its Cholesky or LDL decomposition. Returns infinity if A is
+// singular.
+func Condition(a, L, D ConstMatrix) (float64, error) {
+  n, _ := L.Dims()
+  solve := func(b ConstVector) (Vector, error) {
+    return Solve(L, D, b)
+  }
+  return condition.Estimate1(matrixNorm.Norm1(a).GetFloat64(), n, solve, solve)
+}
@@ -0,0 +1,42 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cholesky
+
+/* -------------------------------------------------------------------------- */
+
+import "fmt"
+
+import . "github.com/pbenner/autodiff"
+import   "gonum.org/v1/gonum/mat"
+
+/* -------------------------------------------------------------------------- */
+
+func cholesky_gonum(A *DenseFloat64Matrix, L *DenseFloat64Matrix) (*DenseFloat64Matrix, *DenseFloat64Matrix, error) {
+  n, _ := A.Dims()
+  c := mat.Cholesky{}
+  if !c.Factorize(AsGonumSymDense(A)) {
+    return nil, nil, fmt.Errorf("matrix is not positive definite")
+  }
+  r := mat.TriDense{}
+  c.LTo(&r)
+  for i := 0; i < n; i++ {
+    for j := 0; j < n; j++ {
+      L.AT(i, j).SetFloat64(r.At(i, j))
+    }
+  }
+  return L, nil, nil
+}
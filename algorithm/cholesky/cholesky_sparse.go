@@ -0,0 +1,452 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cholesky
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// Result of the symbolic analysis of a sparse symmetric matrix, which
+// depends only on the sparsity pattern and can be reused for the numeric
+// factorization of all matrices with the same pattern.
+type SparseSymbolic struct {
+  n      int
+  // fill-reducing permutation, perm[k] is the kth row/column of A in the
+  // permuted matrix P A P^T, iperm is the inverse permutation
+  perm   []int
+  iperm  []int
+  // elimination tree of the permuted matrix
+  parent []int
+  // column pointers of L
+  colPtr []int
+  // pattern of the upper triangular part of the permuted matrix in
+  // compressed column format
+  cp     []int
+  ci     []int
+}
+
+// Numeric Cholesky factorization P A P^T = L L^T of a sparse matrix, where
+// L is stored in compressed column format.
+type SparseFactor struct {
+  Symbolic *SparseSymbolic
+  rowIdx   []int
+  values   []float64
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns the adjacency lists of the graph of A (without diagonal)
+func sparseAdjacency(a *SparseFloat64Matrix) ([][]int, error) {
+  n, m := a.Dims()
+  if n != m {
+    return nil, fmt.Errorf("matrix is not square")
+  }
+  adj := make([][]int, n)
+  for it := a.ConstIterator(); it.Ok(); it.Next() {
+    i, j := it.Index()
+    if i == j {
+      continue
+    }
+    if it.GetConst().GetFloat64() != a.ConstAt(j, i).GetFloat64() {
+      return nil, fmt.Errorf("matrix is not symmetric")
+    }
+    adj[i] = append(adj[i], j)
+  }
+  return adj, nil
+}
+
+// Approximate minimum degree ordering (Amestoy, Davis and Duff, 1996). The
+// elimination is simulated on the quotient graph, where eliminated
+// variables become elements. Each variable i is adjacent to variables A_i
+// and elements E_i, the degree of i is approximated by an upper bound that
+// is cheap to update. Supervariable detection and aggressive absorption
+// are not implemented.
+func approximateMinimumDegree(adj [][]int) []int {
+  n := len(adj)
+  // variable adjacency, element adjacency and element patterns
+  A := make([][]int, n)
+  E := make([][]int, n)
+  L := make([][]int, n)
+  for i := 0; i < n; i++ {
+    A[i] = append([]int{}, adj[i]...)
+  }
+  degree     := make([]int, n)
+  eliminated := make([]bool, n)
+  mark       := make([]int, n)
+  w          := make([]int, n)
+  for i := 0; i < n; i++ {
+    degree[i] = len(A[i])
+    mark  [i] = -1
+    w     [i] = -1
+  }
+  // degree lists
+  head := make([]int, n+1)
+  next := make([]int, n)
+  prev := make([]int, n)
+  for d := range head {
+    head[d] = -1
+  }
+  insert := func(i int) {
+    d := degree[i]
+    next[i], prev[i] = head[d], -1
+    if head[d] != -1 {
+      prev[head[d]] = i
+    }
+    head[d] = i
+  }
+  remove := func(i int) {
+    if prev[i] != -1 {
+      next[prev[i]] = next[i]
+    } else {
+      head[degree[i]] = next[i]
+    }
+    if next[i] != -1 {
+      prev[next[i]] = prev[i]
+    }
+  }
+  for i := 0; i < n; i++ {
+    insert(i)
+  }
+  perm := make([]int, 0, n)
+  dmin := 0
+  for k := 0; k < n; k++ {
+    // select variable of minimum approximate degree
+    for head[dmin] == -1 {
+      dmin++
+    }
+    p := head[dmin]
+    remove(p)
+    eliminated[p] = true
+    perm = append(perm, p)
+    // construct the new element L_p = (A_p u L_e for e in E_p) \ {p}
+    Lp := []int{}
+    mark[p] = p
+    for _, i := range A[p] {
+      if !eliminated[i] && mark[i] != p {
+        mark[i] = p
+        Lp = append(Lp, i)
+      }
+    }
+    for _, e := range E[p] {
+      for _, i := range L[e] {
+        if !eliminated[i] && mark[i] != p {
+          mark[i] = p
+          Lp = append(Lp, i)
+        }
+      }
+      // element e is absorbed into p
+      L[e] = nil
+      w[e] = -2
+    }
+    L[p] = Lp
+    A[p] = nil
+    E[p] = nil
+    // compute |L_e \ L_p| for all elements adjacent to L_p
+    for _, i := range Lp {
+      for _, e := range E[i] {
+        if w[e] == -2 {
+          continue
+        }
+        if w[e] < 0 || mark[e] != p {
+          w[e] = len(L[e])
+          mark[e] = p
+        }
+        w[e]--
+      }
+    }
+    // update variables in L_p
+    for _, i := range Lp {
+      remove(i)
+      // prune A_i, remove p and all variables in L_p
+      a := A[i][:0]
+      for _, j := range A[i] {
+        if !eliminated[j] && mark[j] != p {
+          a = append(a, j)
+        }
+      }
+      A[i] = a
+      // prune E_i, remove absorbed elements and add p
+      s := len(A[i]) + len(Lp) - 1
+      e := E[i][:0]
+      for _, j := range E[i] {
+        if w[j] != -2 {
+          e = append(e, j)
+          s += w[j]
+        }
+      }
+      E[i] = append(e, p)
+      // approximate external degree
+      d := degree[i] + len(Lp) - 1
+      if s < d {
+        d = s
+      }
+      if r := n-k-2; r < d {
+        d = r
+      }
+      if d < 0 {
+        d = 0
+      }
+      degree[i] = d
+      insert(i)
+      if d < dmin {
+        dmin = d
+      }
+    }
+    // reset element weights
+    for _, i := range Lp {
+      for _, e := range E[i] {
+        if w[e] >= 0 {
+          w[e] = -1
+        }
+      }
+    }
+  }
+  return perm
+}
+
+// Compute the elimination tree and the number of nonzero elements in each
+// column of L from the pattern of the upper triangular part of the
+// permuted matrix
+func sparseEtree(n int, cp, ci []int) ([]int, []int) {
+  parent   := make([]int, n)
+  ancestor := make([]int, n)
+  for k := 0; k < n; k++ {
+    parent  [k] = -1
+    ancestor[k] = -1
+    for q := cp[k]; q < cp[k+1]; q++ {
+      // follow path from i to the root and compress path
+      for i := ci[q]; i != -1 && i < k; {
+        inext := ancestor[i]
+        ancestor[i] = k
+        if inext == -1 {
+          parent[i] = k
+        }
+        i = inext
+      }
+    }
+  }
+  // column counts from row patterns, the pattern of row k of L are all
+  // nodes on the paths from i to k in the elimination tree for all
+  // nonzero elements i of column k of the upper triangular part
+  count := make([]int, n)
+  flag  := make([]int, n)
+  for k := 0; k < n; k++ {
+    flag [k] = k
+    count[k]++
+    for q := cp[k]; q < cp[k+1]; q++ {
+      for i := ci[q]; flag[i] != k; i = parent[i] {
+        flag [i] = k
+        count[i]++
+      }
+    }
+  }
+  return parent, count
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Symbolic analysis of a sparse symmetric matrix. A fill-reducing
+// permutation is computed with an approximate minimum degree ordering
+// followed by the elimination tree and the sparsity pattern of the
+// Cholesky factor.
+func AnalyzeSparse(a *SparseFloat64Matrix) (*SparseSymbolic, error) {
+  adj, err := sparseAdjacency(a)
+  if err != nil {
+    return nil, err
+  }
+  n     := len(adj)
+  perm  := approximateMinimumDegree(adj)
+  iperm := make([]int, n)
+  for k := 0; k < n; k++ {
+    iperm[perm[k]] = k
+  }
+  // pattern of the upper triangular part of P A P^T (column-wise)
+  cp := make([]int, n+1)
+  for i := 0; i < n; i++ {
+    for _, j := range adj[i] {
+      if iperm[i] < iperm[j] {
+        cp[iperm[j]+1]++
+      }
+    }
+  }
+  for k := 0; k < n; k++ {
+    cp[k+1] += cp[k]
+  }
+  ci  := make([]int, cp[n])
+  pos := append([]int{}, cp[0:n]...)
+  for i := 0; i < n; i++ {
+    for _, j := range adj[i] {
+      if iperm[i] < iperm[j] {
+        ci[pos[iperm[j]]] = iperm[i]
+        pos[iperm[j]]++
+      }
+    }
+  }
+  parent, count := sparseEtree(n, cp, ci)
+  colPtr := make([]int, n+1)
+  for k := 0; k < n; k++ {
+    colPtr[k+1] = colPtr[k] + count[k]
+  }
+  return &SparseSymbolic{n: n, perm: perm, iperm: iperm, parent: parent, colPtr: colPtr, cp: cp, ci: ci}, nil
+}
+
+// Number of nonzero elements in the Cholesky factor L.
+func (s *SparseSymbolic) NNZ() int {
+  return s.colPtr[s.n]
+}
+
+// Fill-reducing permutation, where the kth row/column of P A P^T is the
+// perm[k]th row/column of A.
+func (s *SparseSymbolic) Permutation() []int {
+  return s.perm
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Numeric Cholesky factorization of a sparse symmetric positive definite
+// matrix with an up-looking algorithm. The symbolic analysis s must have
+// been computed for a matrix with the same sparsity pattern as a.
+func FactorizeSparse(a *SparseFloat64Matrix, s *SparseSymbolic) (*SparseFactor, error) {
+  n := s.n
+  if r, c := a.Dims(); r != n || c != n {
+    return nil, fmt.Errorf("matrix has invalid dimension (%dx%d instead of %dx%d)", r, c, n, n)
+  }
+  rowIdx := make([]int, s.colPtr[n])
+  values := make([]float64, s.colPtr[n])
+  // next free position in each column of L
+  c := append([]int{}, s.colPtr[0:n]...)
+  x := make([]float64, n)
+  // pattern of row k of L in topological order
+  stack := make([]int, n)
+  flag  := make([]int, n)
+  for k := 0; k < n; k++ {
+    // scatter upper triangular part of column k of P A P^T into x
+    x[k] = a.ConstAt(s.perm[k], s.perm[k]).GetFloat64()
+    flag[k] = k
+    top := n
+    for q := s.cp[k]; q < s.cp[k+1]; q++ {
+      i := s.ci[q]
+      x[i] = a.ConstAt(s.perm[i], s.perm[k]).GetFloat64()
+      // nonzero pattern of row k of L
+      l := 0
+      for ; flag[i] != k; i = s.parent[i] {
+        stack[l] = i
+        flag [i] = k
+        l++
+      }
+      for l > 0 {
+        l--
+        top--
+        stack[top] = stack[l]
+      }
+    }
+    d := x[k]
+    x[k] = 0.0
+    for ; top < n; top++ {
+      i := stack[top]
+      // L_ki = x_i / L_ii
+      lki := x[i]/values[s.colPtr[i]]
+      x[i] = 0.0
+      for q := s.colPtr[i]+1; q < c[i]; q++ {
+        x[rowIdx[q]] -= values[q]*lki
+      }
+      d -= lki*lki
+      rowIdx[c[i]] = k
+      values[c[i]] = lki
+      c[i]++
+    }
+    if d <= 0.0 {
+      return nil, fmt.Errorf("matrix is not positive definite")
+    }
+    rowIdx[c[k]] = k
+    values[c[k]] = math.Sqrt(d)
+    c[k]++
+  }
+  return &SparseFactor{Symbolic: s, rowIdx: rowIdx, values: values}, nil
+}
+
+// Cholesky factorization of a sparse symmetric positive definite matrix,
+// which combines AnalyzeSparse and FactorizeSparse. If several matrices
+// with the same sparsity pattern must be factorized, the symbolic analysis
+// should be computed only once.
+func RunSparse(a *SparseFloat64Matrix) (*SparseFactor, error) {
+  s, err := AnalyzeSparse(a)
+  if err != nil {
+    return nil, err
+  }
+  return FactorizeSparse(a, s)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns the Cholesky factor L of the permuted matrix P A P^T.
+func (f *SparseFactor) L() *SparseFloat64Matrix {
+  n := f.Symbolic.n
+  r := NullSparseFloat64Matrix(n, n)
+  for j := 0; j < n; j++ {
+    for q := f.Symbolic.colPtr[j]; q < f.Symbolic.colPtr[j+1]; q++ {
+      r.At(f.rowIdx[q], j).SetFloat64(f.values[q])
+    }
+  }
+  return r
+}
+
+// Solve A x = b.
+func (f *SparseFactor) Solve(b ConstVector) (DenseFloat64Vector, error) {
+  s := f.Symbolic
+  n := s.n
+  if b.Dim() != n {
+    return nil, fmt.Errorf("vector has invalid dimension")
+  }
+  y := NullDenseFloat64Vector(n)
+  for k := 0; k < n; k++ {
+    y[k] = b.ConstAt(s.perm[k]).GetFloat64()
+  }
+  // forward substitution L z = P b
+  for j := 0; j < n; j++ {
+    y[j] /= f.values[s.colPtr[j]]
+    for q := s.colPtr[j]+1; q < s.colPtr[j+1]; q++ {
+      y[f.rowIdx[q]] -= f.values[q]*y[j]
+    }
+  }
+  // back substitution L^T w = z
+  for j := n-1; j >= 0; j-- {
+    for q := s.colPtr[j]+1; q < s.colPtr[j+1]; q++ {
+      y[j] -= f.values[q]*y[f.rowIdx[q]]
+    }
+    y[j] /= f.values[s.colPtr[j]]
+  }
+  x := NullDenseFloat64Vector(n)
+  for k := 0; k < n; k++ {
+    x[s.perm[k]] = y[k]
+  }
+  return x, nil
+}
+
+// Log-determinant of A.
+func (f *SparseFactor) LogDet() float64 {
+  r := 0.0
+  for j := 0; j < f.Symbolic.n; j++ {
+    r += math.Log(f.values[f.Symbolic.colPtr[j]])
+  }
+  return 2.0*r
+}
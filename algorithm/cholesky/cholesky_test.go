@@ -19,10 +19,12 @@ package cholesky
 /* -------------------------------------------------------------------------- */
 
 import   "fmt"
+import   "math"
 import   "testing"
 import   "time"
 
 import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/condition"
 
 /* -------------------------------------------------------------------------- */
 
@@ -123,3 +125,244 @@ func TestPerformance(test *testing.T) {
   elapsed = time.Since(start)
   fmt.Printf("Cholesky on DenseReal64Matrix took %s.\n", elapsed)
 }
+
+func TestCholeskyPacked(test *testing.T) {
+  n := 4
+  a := AsSymmetricPackedMatrix(Float64Type, NewDenseFloat64Matrix([]float64{
+    18, 22,  54,  42,
+    22, 70,  86,  62,
+    54, 86, 174, 134,
+    42, 62, 134, 106 }, n, n))
+  {
+    x, _, err := Run(a)
+    if err != nil {
+      test.Error(err); return
+    }
+    if _, ok := x.(*LowerTriangularMatrix); !ok {
+      test.Error("test failed")
+    }
+    r := NullSymmetricPackedMatrix(Float64Type, n)
+    r.MdotM(x, x.T())
+    if !r.Equals(a, 1e-8) {
+      test.Error("test failed")
+    }
+  }
+  {
+    l, d, err := Run(a, LDL{true})
+    if err != nil {
+      test.Error(err); return
+    }
+    if _, ok := d.(*DiagonalMatrix); !ok {
+      test.Error("test failed")
+    }
+    r := NullDenseFloat64Matrix(n, n)
+    r.MdotM(r.MdotM(l, d), l.T())
+    if !r.Equals(a, 1e-8) {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestCholeskyGonum(test *testing.T) {
+  n := 4
+  a := NewDenseFloat64Matrix([]float64{
+    18, 22,  54,  42,
+    22, 70,  86,  62,
+    54, 86, 174, 134,
+    42, 62, 134, 106 }, n, n)
+  r, _, _ := Run(a)
+
+  SetGonumBackend(true)
+  defer SetGonumBackend(false)
+
+  if x, _, err := Run(a); err != nil {
+    test.Error(err)
+  } else {
+    if !x.Equals(r, 1e-8) {
+      test.Error("test failed")
+    }
+  }
+  if _, _, err := Run(NewDenseFloat64Matrix([]float64{1, 2, 2, 1}, 2, 2)); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestCholeskyCondition(test *testing.T) {
+  // condition number of the 3x3 Hilbert matrix is 748
+  a := NewDenseFloat64Matrix([]float64{
+    1.0/1, 1.0/2, 1.0/3,
+    1.0/2, 1.0/3, 1.0/4,
+    1.0/3, 1.0/4, 1.0/5 }, 3, 3)
+
+  for _, ldl := range []bool{false, true} {
+    l, d, err := Run(a, LDL{ldl})
+    if err != nil {
+      test.Error(err)
+      return
+    }
+    if !ldl {
+      d = nil
+    }
+    if c, err := Condition(a, l, d); err != nil {
+      test.Error(err)
+    } else if math.Abs(c - 748) > 1e-8 {
+      test.Error("test failed")
+    }
+  }
+  warning := 0.0
+  if _, _, err := Run(a, condition.Threshold{Value: 100, Warning: func(c float64) { warning = c }}); err != nil {
+    test.Error(err)
+  } else if math.Abs(warning - 748) > 1e-8 {
+    test.Error("test failed")
+  }
+  if _, _, err := Run(a, LDL{true}, condition.Threshold{Value: 100}); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestCholeskyUpdate(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    4, 2, 0, 1,
+    2, 5, 1, 0,
+    0, 1, 6, 2,
+    1, 0, 2, 7 }, 4, 4)
+  x := NewDenseFloat64Vector([]float64{1, -2, 0.5, 3})
+
+  // A + x x^T
+  b := NullDenseFloat64Matrix(4, 4)
+  for i := 0; i < 4; i++ {
+    for j := 0; j < 4; j++ {
+      b.At(i, j).SetFloat64(a.At(i, j).GetFloat64() + x[i]*x[j])
+    }
+  }
+  l, _, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  r, _, _ := Run(b)
+  if err := Update(l, x); err != nil {
+    test.Error(err)
+  } else if !l.Equals(r, 1e-10) {
+    test.Error("test failed")
+  }
+  r, _, _ = Run(a)
+  if err := Downdate(l, x); err != nil {
+    test.Error(err)
+  } else if !l.Equals(r, 1e-10) {
+    test.Error("test failed")
+  }
+  // A - 10 x x^T is not positive definite
+  x.VmulS(x, NewFloat64(math.Sqrt(10)))
+  if err := Downdate(l, x); err == nil {
+    test.Error("test failed")
+  }
+}
+
+// precision matrix of a Gaussian Markov random field on a k x k grid
+func gmrfPrecision(k int) *SparseFloat64Matrix {
+  n := k*k
+  a := NullSparseFloat64Matrix(n, n)
+  for i := 0; i < k; i++ {
+    for j := 0; j < k; j++ {
+      p := i*k + j
+      a.At(p, p).SetFloat64(4.1)
+      if i > 0 {
+        a.At(p, p-k).SetFloat64(-1)
+        a.At(p-k, p).SetFloat64(-1)
+      }
+      if j > 0 {
+        a.At(p, p-1).SetFloat64(-1)
+        a.At(p-1, p).SetFloat64(-1)
+      }
+    }
+  }
+  return a
+}
+
+func TestCholeskySparse1(test *testing.T) {
+  a := gmrfPrecision(6)
+  n, _ := a.Dims()
+
+  f, err := RunSparse(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  // check L L^T = P A P^T
+  l := f.L()
+  p := f.Symbolic.Permutation()
+  r := NullDenseFloat64Matrix(n, n)
+  r.MdotM(l, l.T())
+  for i := 0; i < n; i++ {
+    for j := 0; j < n; j++ {
+      if math.Abs(r.At(i, j).GetFloat64() - a.At(p[i], p[j]).GetFloat64()) > 1e-10 {
+        test.Errorf("test failed for element (%d,%d)", i, j)
+      }
+    }
+  }
+  // solve A x = b
+  x := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    x[i] = float64(i%7) - 3
+  }
+  b := NullDenseFloat64Vector(n)
+  b.MdotV(a, x)
+  if z, err := f.Solve(b); err != nil {
+    test.Error(err)
+  } else if !z.Equals(x, 1e-10) {
+    test.Error("test failed")
+  }
+  // log-determinant
+  lc, _, _ := Run(AsDenseFloat64Matrix(a))
+  s := 0.0
+  for i := 0; i < n; i++ {
+    s += 2*math.Log(lc.At(i, i).GetFloat64())
+  }
+  if math.Abs(f.LogDet() - s) > 1e-10 {
+    test.Error("test failed")
+  }
+}
+
+func TestCholeskySparse2(test *testing.T) {
+  // arrow matrix, which fills in completely without reordering
+  n := 50
+  a := NullSparseFloat64Matrix(n, n)
+  for i := 0; i < n; i++ {
+    a.At(i, i).SetFloat64(float64(n))
+    if i > 0 {
+      a.At(0, i).SetFloat64(1)
+      a.At(i, 0).SetFloat64(1)
+    }
+  }
+  s, err := AnalyzeSparse(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if s.NNZ() != 2*n-1 {
+    test.Errorf("test failed: %d nonzero elements", s.NNZ())
+  }
+  // numeric factorization of a matrix with the same pattern
+  for k := 0; k < 2; k++ {
+    a.At(0, 0).SetFloat64(float64(n + k))
+    f, err := FactorizeSparse(a, s)
+    if err != nil {
+      test.Error(err)
+      return
+    }
+    b := NullDenseFloat64Vector(n)
+    b[0] = 1
+    x, _ := f.Solve(b)
+    r := NullDenseFloat64Vector(n)
+    r.MdotV(a, x)
+    if !r.Equals(b, 1e-12) {
+      test.Error("test failed")
+    }
+  }
+  // not positive definite
+  a.At(0, 0).SetFloat64(0)
+  if _, err := FactorizeSparse(a, s); err == nil {
+    test.Error("test failed")
+  }
+}
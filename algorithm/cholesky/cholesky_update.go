@@ -0,0 +1,92 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cholesky
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func rankOneUpdate(L Matrix, x_ ConstVector, sigma float64) error {
+  n, m := L.Dims()
+  if n != m || x_.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  t := L.ElementType()
+  if s := x_.ElementType(); s == Real32Type || s == Real64Type {
+    t = s
+  }
+  // work vector, which is overwritten
+  x := NullDenseVector(t, n)
+  x.Set(x_)
+  r  := NullScalar(t)
+  c  := NullScalar(t)
+  s  := NullScalar(t)
+  t1 := NullScalar(t)
+  for k := 0; k < n; k++ {
+    // r = sqrt(L_kk^2 +/- x_k^2)
+    r .Mul(L.At(k, k), L.At(k, k))
+    t1.Mul(x.At(k), x.At(k))
+    if sigma > 0.0 {
+      r.Add(r, t1)
+    } else {
+      r.Sub(r, t1)
+    }
+    if r.GetFloat64() <= 0.0 {
+      return fmt.Errorf("matrix is not positive definite")
+    }
+    r.Sqrt(r)
+    // Givens rotation (hyperbolic rotation for downdates)
+    c.Div(r, L.At(k, k))
+    s.Div(x.At(k), L.At(k, k))
+    L.At(k, k).Set(r)
+    for i := k+1; i < n; i++ {
+      // L_ik = (L_ik +/- s x_i)/c
+      t1.Mul(s, x.At(i))
+      if sigma > 0.0 {
+        L.At(i, k).Add(L.At(i, k), t1)
+      } else {
+        L.At(i, k).Sub(L.At(i, k), t1)
+      }
+      L.At(i, k).Div(L.At(i, k), c)
+      // x_i = c x_i - s L_ik
+      t1  .Mul(s, L.At(i, k))
+      x.At(i).Mul(c, x.At(i))
+      x.At(i).Sub(x.At(i), t1)
+    }
+  }
+  return nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Given the Cholesky factor L of A = L L^T, compute the Cholesky factor of
+// A + x x^T in O(n^2) operations. L is overwritten with the result.
+func Update(L Matrix, x ConstVector) error {
+  return rankOneUpdate(L, x, 1.0)
+}
+
+// Given the Cholesky factor L of A = L L^T, compute the Cholesky factor of
+// A - x x^T in O(n^2) operations. L is overwritten with the result. An error
+// is returned if A - x x^T is not positive definite, in which case L is
+// invalid.
+func Downdate(L Matrix, x ConstVector) error {
+  return rankOneUpdate(L, x, -1.0)
+}
@@ -0,0 +1,468 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// References:
+// Hansen, Nikolaus. The CMA evolution strategy: A tutorial.
+// arXiv:1604.00772 (2016).
+// Auger, Anne, and Nikolaus Hansen. A restart CMA evolution strategy with
+// increasing population size. IEEE Congress on Evolutionary Computation,
+// 2005.
+
+/* -------------------------------------------------------------------------- */
+
+package cmaes
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "math/rand"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/eigensystem"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+// Objective function, which does not have to be differentiable
+type Objective func(ConstVector) (ConstScalar, error)
+
+type Epsilon struct {
+  Value float64
+}
+
+// Maximum number of generations of each run
+type MaxIterations struct {
+  Value int
+}
+
+// Initial step size
+type Sigma struct {
+  Value float64
+}
+
+// Number of samples in each generation
+type PopulationSize struct {
+  Value int
+}
+
+// Lower and upper bounds for each coordinate, see optimize.Bounds
+type Bounds = optimize.Bounds
+
+// Number of restarts with doubled population size
+type Restarts struct {
+  Value int
+}
+
+type Seed struct {
+  Value int64
+}
+
+// Called after every generation with the best position found so far
+type Hook struct {
+  Value func(x ConstVector, y ConstScalar) bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+type sample struct {
+  x DenseFloat64Vector
+  // x = m + sigma y
+  y DenseFloat64Vector
+  f float64
+}
+
+type population []sample
+
+func (p population) Len() int {
+  return len(p)
+}
+
+func (p population) Less(i, j int) bool {
+  return p[i].f < p[j].f
+}
+
+func (p population) Swap(i, j int) {
+  p[i], p[j] = p[j], p[i]
+}
+
+/* -------------------------------------------------------------------------- */
+
+type cmaes struct {
+  f      Objective
+  bounds Bounds
+  rng    *rand.Rand
+  // best position found so far
+  xbest  DenseFloat64Vector
+  ybest  float64
+  // total number of generations and the reason for terminating the last
+  // run
+  generations int
+  status      optimize.Status
+}
+
+// evaluate objective function, NaN values are replaced by +Inf
+func (obj *cmaes) eval(x DenseFloat64Vector) (float64, error) {
+  y, err := obj.f(x)
+  if err != nil {
+    return 0.0, err
+  }
+  v := y.GetFloat64()
+  if math.IsNaN(v) {
+    v = math.Inf(1)
+  }
+  if v < obj.ybest {
+    obj.xbest.Set(x)
+    obj.ybest = v
+  }
+  return v, nil
+}
+
+// A single run of the (mu/mu_w, lambda)-CMA-ES. Returns true if the hook
+// requested to stop.
+func (obj *cmaes) run(x0 DenseFloat64Vector, sigma float64, lambda int, epsilon Epsilon, maxIterations MaxIterations, hook Hook) (bool, error) {
+  n  := x0.Dim()
+  nf := float64(n)
+  mu := lambda/2
+  // recombination weights
+  w := make([]float64, mu)
+  for i := 0; i < mu; i++ {
+    w[i] = math.Log(float64(lambda+1)/2.0) - math.Log(float64(i+1))
+  }
+  sw  := 0.0
+  sw2 := 0.0
+  for i := 0; i < mu; i++ {
+    sw += w[i]
+  }
+  for i := 0; i < mu; i++ {
+    w[i] /= sw
+    sw2  += w[i]*w[i]
+  }
+  mueff := 1.0/sw2
+  // adaptation parameters
+  cs    := (mueff + 2.0)/(nf + mueff + 5.0)
+  ds    := 1.0 + 2.0*math.Max(0.0, math.Sqrt((mueff - 1.0)/(nf + 1.0)) - 1.0) + cs
+  cc    := (4.0 + mueff/nf)/(nf + 4.0 + 2.0*mueff/nf)
+  c1    := 2.0/((nf + 1.3)*(nf + 1.3) + mueff)
+  cmu   := math.Min(1.0 - c1, 2.0*(mueff - 2.0 + 1.0/mueff)/((nf + 2.0)*(nf + 2.0) + mueff))
+  // expected norm of a standard normal vector
+  chiN  := math.Sqrt(nf)*(1.0 - 1.0/(4.0*nf) + 1.0/(21.0*nf*nf))
+
+  m  := x0.Clone()
+  m0 := NullDenseFloat64Vector(n)
+  ps := NullDenseFloat64Vector(n)
+  pc := NullDenseFloat64Vector(n)
+  yw := NullDenseFloat64Vector(n)
+  z  := NullDenseFloat64Vector(n)
+  t  := NullDenseFloat64Vector(n)
+  // covariance matrix C = B D^2 B^T
+  C  := NullDenseFloat64Matrix(n, n)
+  B  := NullDenseFloat64Matrix(n, n)
+  D  := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    C.At(i, i).SetFloat64(1.0)
+    B.At(i, i).SetFloat64(1.0)
+    D[i] = 1.0
+  }
+  p := make(population, lambda)
+  for k := 0; k < lambda; k++ {
+    p[k] = sample{NullDenseFloat64Vector(n), NullDenseFloat64Vector(n), 0.0}
+  }
+  obj.status = optimize.MaxIterationsReached
+  for g := 0; g < maxIterations.Value; g++ {
+    obj.generations++
+    // sample and evaluate new generation
+    for k := 0; k < lambda; k++ {
+      for i := 0; i < n; i++ {
+        z[i] = D[i]*obj.rng.NormFloat64()
+      }
+      for i := 0; i < n; i++ {
+        s := 0.0
+        for j := 0; j < n; j++ {
+          s += B.Float64At(i, j)*z[j]
+        }
+        p[k].x[i] = m[i] + sigma*s
+      }
+      // repair infeasible samples
+      obj.bounds.Project(p[k].x)
+      for i := 0; i < n; i++ {
+        p[k].y[i] = (p[k].x[i] - m[i])/sigma
+      }
+      if f, err := obj.eval(p[k].x); err != nil {
+        return false, err
+      } else {
+        p[k].f = f
+      }
+    }
+    sort.Stable(p)
+    // recombination
+    m0.Set(m)
+    yw.Reset()
+    for i := 0; i < mu; i++ {
+      for j := 0; j < n; j++ {
+        yw[j] += w[i]*p[i].y[j]
+      }
+    }
+    for j := 0; j < n; j++ {
+      m[j] = m0[j] + sigma*yw[j]
+    }
+    // cumulation for sigma: ps = (1-cs) ps + sqrt(cs (2-cs) mueff) C^-1/2 yw
+    for i := 0; i < n; i++ {
+      s := 0.0
+      for j := 0; j < n; j++ {
+        s += B.Float64At(j, i)*yw[j]
+      }
+      t[i] = s/D[i]
+    }
+    for i := 0; i < n; i++ {
+      s := 0.0
+      for j := 0; j < n; j++ {
+        s += B.Float64At(i, j)*t[j]
+      }
+      ps[i] = (1.0 - cs)*ps[i] + math.Sqrt(cs*(2.0 - cs)*mueff)*s
+    }
+    psn := 0.0
+    for i := 0; i < n; i++ {
+      psn += ps[i]*ps[i]
+    }
+    psn = math.Sqrt(psn)
+    // stall the update of pc if ps is large
+    hs := 0.0
+    if psn/math.Sqrt(1.0 - math.Pow(1.0 - cs, 2.0*float64(g+1))) < (1.4 + 2.0/(nf + 1.0))*chiN {
+      hs = 1.0
+    }
+    for i := 0; i < n; i++ {
+      pc[i] = (1.0 - cc)*pc[i] + hs*math.Sqrt(cc*(2.0 - cc)*mueff)*yw[i]
+    }
+    // rank-one and rank-mu update of the covariance matrix
+    dh := (1.0 - hs)*cc*(2.0 - cc)
+    for i := 0; i < n; i++ {
+      for j := 0; j <= i; j++ {
+        r := 0.0
+        for k := 0; k < mu; k++ {
+          r += w[k]*p[k].y[i]*p[k].y[j]
+        }
+        c := (1.0 - c1 - cmu + c1*dh)*C.Float64At(i, j) + c1*pc[i]*pc[j] + cmu*r
+        C.At(i, j).SetFloat64(c)
+        C.At(j, i).SetFloat64(c)
+      }
+    }
+    // step size control
+    sigma *= math.Exp(cs/ds*(psn/chiN - 1.0))
+    // eigen decomposition of the covariance matrix
+    if ev, V, err := eigensystem.Run(C, eigensystem.Symmetric{Value: true}); err != nil {
+      return false, err
+    } else {
+      B.Set(V)
+      for i := 0; i < n; i++ {
+        D[i] = math.Sqrt(math.Max(ev.Float64At(i), 0.0))
+      }
+    }
+    // execute hook if available
+    if hook.Value != nil && hook.Value(obj.xbest, ConstFloat64(obj.ybest)) {
+      return true, nil
+    }
+    // evaluate stop criteria
+    sd := 0.0
+    for i := 0; i < n; i++ {
+      sd = math.Max(sd, sigma*math.Max(math.Sqrt(C.Float64At(i, i)), math.Abs(pc[i])))
+    }
+    if sd < epsilon.Value {
+      obj.status = optimize.Converged
+      break
+    }
+    // objective function is flat, i.e. the generation does not provide
+    // any information about the direction of descent
+    if p[0].f == p[lambda-1].f {
+      obj.status = optimize.Stalled
+      break
+    }
+    // numerical problems, i.e. the covariance matrix is ill-conditioned
+    if Dmax, Dmin := maxSlice(D), minSlice(D); Dmin <= 0.0 || Dmax > 1e7*Dmin {
+      obj.status = optimize.Stalled
+      break
+    }
+    if math.IsNaN(sigma) || math.IsInf(sigma, 0) {
+      obj.status = optimize.Stalled
+      break
+    }
+  }
+  return false, nil
+}
+
+func maxSlice(x DenseFloat64Vector) float64 {
+  r := math.Inf(-1)
+  for i := 0; i < x.Dim(); i++ {
+    r = math.Max(r, x[i])
+  }
+  return r
+}
+
+func minSlice(x DenseFloat64Vector) float64 {
+  r := math.Inf(1)
+  for i := 0; i < x.Dim(); i++ {
+    r = math.Min(r, x[i])
+  }
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f with the covariance matrix adaptation evolution strategy
+// (CMA-ES) starting at x0. The method uses only function values, hence f
+// may be non-differentiable or noisy. Optional arguments:
+//
+//   Epsilon       : stop if the standard deviation of the search
+//                   distribution is smaller than epsilon in all coordinates
+//                   (default 1e-8)
+//   MaxIterations : maximum number of generations of each run (default
+//                   1000 (n+5)^2/sqrt(lambda) for population size lambda)
+//   Sigma         : initial step size (default 0.5)
+//   PopulationSize: number of samples in each generation (default
+//                   4 + 3 log n)
+//   Bounds        : lower and upper bounds, samples are projected onto the
+//                   feasible box
+//   Restarts      : number of restarts at x0, where the population size is
+//                   doubled at every restart (IPOP-CMA-ES, default 0)
+//   Seed          : seed of the random number generator
+//   Hook          : called after every generation with the best position
+//                   found so far, stop if it returns true
+//
+// Returns the best position found in all runs.
+func Run(f Objective, x0 ConstVector, args ...interface{}) (Vector, error) {
+  x, _, err := minimize(f, x0, args...)
+  return x, err
+}
+
+func minimize(f Objective, x0 ConstVector, args ...interface{}) (Vector, *cmaes, error) {
+
+  n := x0.Dim()
+
+  epsilon        := Epsilon       {1e-8}
+  maxIterations  := MaxIterations {0}
+  sigma          := Sigma         {0.5}
+  populationSize := PopulationSize{4 + int(3.0*math.Log(float64(n)))}
+  bounds         := Bounds        {}
+  restarts       := Restarts      {0}
+  seed           := Seed          {0}
+  hook           := Hook          {nil}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Sigma:
+      sigma = a
+    case PopulationSize:
+      populationSize = a
+    case Bounds:
+      bounds = a
+    case Restarts:
+      restarts = a
+    case Seed:
+      seed = a
+    case Hook:
+      hook = a
+    default:
+      panic("CMAES(): Invalid optional argument!")
+    }
+  }
+  if n == 0 {
+    return nil, nil, fmt.Errorf("invalid dimension")
+  }
+  if err := bounds.Check(n); err != nil {
+    return nil, nil, err
+  }
+  if sigma.Value <= 0.0 {
+    return nil, nil, fmt.Errorf("initial step size must be positive")
+  }
+  if populationSize.Value < 2 {
+    return nil, nil, fmt.Errorf("population size must be at least two")
+  }
+  x := AsDenseFloat64Vector(x0)
+  bounds.Project(x)
+
+  obj := &cmaes{}
+  obj.f      = f
+  obj.bounds = bounds
+  obj.rng    = rand.New(rand.NewSource(seed.Value))
+  obj.xbest  = x.Clone()
+  obj.ybest  = math.Inf(1)
+
+  if y, err := obj.eval(x); err != nil {
+    return x, obj, fmt.Errorf("invalid initial value: %s", err)
+  } else if math.IsInf(y, 1) {
+    return x, obj, fmt.Errorf("invalid initial value: %v", x)
+  }
+  lambda := populationSize.Value
+  for k := 0; k <= restarts.Value; k++ {
+    maxIter := maxIterations
+    if maxIter.Value == 0 {
+      maxIter.Value = int(1e3*float64((n + 5)*(n + 5))/math.Sqrt(float64(lambda)))
+    }
+    if stop, err := obj.run(x, sigma.Value, lambda, epsilon, maxIter, hook); err != nil {
+      return obj.xbest, obj, err
+    } else if stop {
+      break
+    }
+    lambda *= 2
+  }
+  return obj.xbest, obj, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the best position found together
+// with convergence diagnostics. Iterations are generations of all runs and
+// the status refers to the last run. Accepts the same optional arguments as
+// Run and additionally optimize.Trace.
+func Minimize(f Objective, x0 ConstVector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook    := Hook{nil}
+  options := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (ConstScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // the hook is called after every generation with the best position
+  options = append(options, Hook{func(x ConstVector, y ConstScalar) bool {
+    r.Iteration(x, y.GetFloat64(), math.NaN())
+    return hook.Value != nil && r.Hook(hook.Value(x, y))
+  }})
+  x, obj, err := minimize(g, x0, options...)
+  if obj == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  r.SetIterations(obj.generations)
+  if err != nil {
+    return r.Result(x, obj.ybest, math.NaN(), optimize.Failed), err
+  }
+  return r.Result(x, obj.ybest, math.NaN(), obj.status), nil
+}
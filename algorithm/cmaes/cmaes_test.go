@@ -0,0 +1,163 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmaes
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+func TestCmaesRosenbrock(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    // f(x1, x2) = (a - x1)^2 + b(x2 - x1^2)^2
+    // a = 1
+    // b = 100
+    // minimum: (x1,x2) = (a, a^2)
+    x1 := x.Float64At(0)
+    x2 := x.Float64At(1)
+    return ConstFloat64((1.0 - x1)*(1.0 - x1) + 100.0*(x2 - x1*x1)*(x2 - x1*x1)), nil
+  }
+  t  := NewFloat64(0.0)
+  x0 := NewDenseFloat64Vector([]float64{-0.5, 2})
+  xr := NewDenseFloat64Vector([]float64{   1, 1})
+  xn, err := Run(f, x0, Epsilon{1e-10}, Seed{1})
+  if err != nil {
+    test.Error(err)
+  }
+  if t.Vnorm(xn.VsubV(xn, xr)).GetFloat64() > 1e-8 {
+    test.Error("CMA-ES Rosenbrock test failed!")
+  }
+}
+
+func TestCmaesRastrigin(test *testing.T) {
+
+  // Rastrigin function with many local minima and global minimum at zero
+  f := func(x ConstVector) (ConstScalar, error) {
+    r := 10.0*float64(x.Dim())
+    for i := 0; i < x.Dim(); i++ {
+      xi := x.Float64At(i)
+      r  += xi*xi - 10.0*math.Cos(2.0*math.Pi*xi)
+    }
+    return ConstFloat64(r), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{3, -2, 4, 1})
+  xn, err := Run(f, x0, Sigma{2.0}, Restarts{6}, Seed{1},
+    Bounds{
+      Lower: []float64{-5.12, -5.12, -5.12, -5.12},
+      Upper: []float64{ 5.12,  5.12,  5.12,  5.12} })
+  if err != nil {
+    test.Error(err)
+  }
+  for i := 0; i < xn.Dim(); i++ {
+    if math.Abs(xn.Float64At(i)) > 1e-6 {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestCmaesBounds(test *testing.T) {
+
+  // piecewise constant objective function
+  f := func(x ConstVector) (ConstScalar, error) {
+    r := 0.0
+    for i := 0; i < x.Dim(); i++ {
+      r += math.Abs(math.Floor(10.0*(x.Float64At(i) - 2.0)))
+    }
+    return ConstFloat64(r), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{0, 0, 0})
+  xn, err := Run(f, x0, Seed{1}, Bounds{Upper: []float64{1, 1, 1}})
+  if err != nil {
+    test.Error(err)
+  }
+  for i := 0; i < xn.Dim(); i++ {
+    if xn.Float64At(i) < 0.9 || xn.Float64At(i) > 1.0 {
+      test.Error("test failed")
+    }
+  }
+  if _, err := Run(f, x0, Bounds{Upper: []float64{1}}); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestCmaesSeed(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    r := 0.0
+    for i := 0; i < x.Dim(); i++ {
+      r += math.Abs(x.Float64At(i))
+    }
+    return ConstFloat64(r), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{1, 2, 3})
+  x1, err1 := Run(f, x0, Seed{42}, MaxIterations{20})
+  x2, err2 := Run(f, x0, Seed{42}, MaxIterations{20})
+  x3, err3 := Run(f, x0, Seed{43}, MaxIterations{20})
+  if err1 != nil || err2 != nil || err3 != nil {
+    test.Error("test failed")
+  }
+  equal := true
+  for i := 0; i < x1.Dim(); i++ {
+    if x1.Float64At(i) != x2.Float64At(i) {
+      test.Error("test failed")
+    }
+    if x1.Float64At(i) != x3.Float64At(i) {
+      equal = false
+    }
+  }
+  if equal {
+    test.Error("test failed")
+  }
+}
+
+func TestCmaesMinimize(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    x1 := x.Float64At(0)
+    x2 := x.Float64At(1)
+    return ConstFloat64((x1 - 1.0)*(x1 - 1.0) + (x2 - 2.0)*(x2 - 2.0)), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{0, 0})
+  r, err := Minimize(f, x0, Epsilon{1e-10}, Seed{1})
+  if err != nil {
+    test.Error(err)
+  }
+  if r.Status != optimize.Converged || r.Value > 1e-16 || !math.IsNaN(r.GradientNorm) {
+    test.Error("test failed")
+  }
+  if r.Iterations == 0 || r.Evaluations <= r.Iterations {
+    test.Error("test failed")
+  }
+  hook := Hook{func(x ConstVector, y ConstScalar) bool { return true }}
+  if r, _ := Minimize(f, x0, Seed{1}, hook); r.Status != optimize.HookStopped {
+    test.Error("test failed")
+  }
+  // a flat objective function does not imply convergence
+  g := func(x ConstVector) (ConstScalar, error) {
+    return ConstFloat64(1.0), nil
+  }
+  if r, err := Minimize(g, x0, Seed{1}); err != nil || r.Status != optimize.Stalled {
+    test.Error("test failed")
+  }
+}
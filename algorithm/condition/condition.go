@@ -0,0 +1,156 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package condition
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// Threshold for the estimated condition number of a matrix, which is
+// checked by solvers after factorizing the matrix. If the estimate exceeds
+// the threshold, Warning is called with the estimate. If Warning is nil,
+// an error is returned instead.
+type Threshold struct {
+  Value   float64
+  Warning func(c float64)
+}
+
+// Solves a linear system with a factorized matrix
+type Solver func(b ConstVector) (Vector, error)
+
+/* -------------------------------------------------------------------------- */
+
+func norm1(x ConstVector) float64 {
+  r := 0.0
+  for i := 0; i < x.Dim(); i++ {
+    r += math.Abs(x.ConstAt(i).GetFloat64())
+  }
+  return r
+}
+
+func sign(x float64) float64 {
+  if x < 0.0 {
+    return -1.0
+  }
+  return 1.0
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Estimate the 1-norm of the inverse of an n x n matrix A with the
+// algorithm of Hager (1984) and Higham (1988). Only solves with A (solve)
+// and A^T (solveT) are required, which typically reuse an existing
+// factorization of A. The estimate is a lower bound, which is exact in
+// most cases.
+func EstimateInverseNorm1(n int, solve, solveT Solver) (float64, error) {
+  x := NullDenseFloat64Vector(n)
+  s := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    x[i] = 1.0/float64(n)
+  }
+  r := 0.0
+  // index of the last unit vector
+  k := -1
+  for iter := 0; iter < 5; iter++ {
+    y, err := solve(x)
+    if err != nil {
+      return 0.0, err
+    }
+    if t := norm1(y); iter > 0 && t <= r {
+      break
+    } else {
+      r = t
+    }
+    // stop if the sign vector did not change
+    changed := false
+    for i := 0; i < n; i++ {
+      if t := sign(y.ConstAt(i).GetFloat64()); t != s[i] {
+        s[i] = t
+        changed = true
+      }
+    }
+    if iter > 0 && !changed {
+      break
+    }
+    z, err := solveT(s)
+    if err != nil {
+      return 0.0, err
+    }
+    // check optimality condition ||z||_inf <= z^T x
+    j, zmax, ztx := 0, 0.0, 0.0
+    for i := 0; i < n; i++ {
+      zi := z.ConstAt(i).GetFloat64()
+      if math.Abs(zi) > zmax {
+        j, zmax = i, math.Abs(zi)
+      }
+      ztx += zi*x[i]
+    }
+    if zmax <= ztx || j == k {
+      break
+    }
+    x.Reset()
+    x[j] = 1.0
+    k    = j
+  }
+  // alternative estimate (Higham, 1988), which is used if the iteration
+  // underestimates the norm considerably
+  for i := 0; i < n; i++ {
+    x[i] = 1.0
+    if n > 1 {
+      x[i] += float64(i)/float64(n-1)
+    }
+    if i % 2 == 1 {
+      x[i] = -x[i]
+    }
+  }
+  y, err := solve(x)
+  if err != nil {
+    return 0.0, err
+  }
+  if t := 2.0*norm1(y)/(3.0*float64(n)); t > r {
+    r = t
+  }
+  return r, nil
+}
+
+// Estimate the condition number ||A||_1 ||A^-1||_1 of an n x n matrix A,
+// where anorm is the 1-norm of A. See EstimateInverseNorm1.
+func Estimate1(anorm float64, n int, solve, solveT Solver) (float64, error) {
+  r, err := EstimateInverseNorm1(n, solve, solveT)
+  if err != nil {
+    return math.Inf(1), err
+  }
+  return anorm*r, nil
+}
+
+// Compare the condition number c with the threshold. Returns an error if
+// the threshold is exceeded and no warning function is set.
+func (t Threshold) Check(c float64) error {
+  if t.Value <= 0.0 || !(c > t.Value || math.IsNaN(c)) {
+    return nil
+  }
+  if t.Warning != nil {
+    t.Warning(c)
+    return nil
+  }
+  return fmt.Errorf("matrix is ill-conditioned (estimated condition number %e)", c)
+}
@@ -0,0 +1,64 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package condition
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestCondition1(test *testing.T) {
+  // inverse of the 3x3 Hilbert matrix
+  ainv := NewDenseFloat64Matrix([]float64{
+      9,  -36,   30,
+    -36,  192, -180,
+     30, -180,  180 }, 3, 3)
+  solve := func(b ConstVector) (Vector, error) {
+    r := NullDenseFloat64Vector(3)
+    r.MdotV(ainv, b)
+    return r, nil
+  }
+  r, err := EstimateInverseNorm1(3, solve, solve)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if math.Abs(r - 408) > 1e-10 {
+    test.Error("test failed")
+  }
+  // ||A||_1 = 11/6
+  if c, _ := Estimate1(11.0/6.0, 3, solve, solve); math.Abs(c - 748) > 1e-10 {
+    test.Error("test failed")
+  }
+}
+
+func TestCondition2(test *testing.T) {
+  t := Threshold{Value: 100}
+  if t.Check(10) != nil || t.Check(1000) == nil {
+    test.Error("test failed")
+  }
+  warned := false
+  t.Warning = func(c float64) { warned = true }
+  if t.Check(1000) != nil || !warned {
+    test.Error("test failed")
+  }
+}
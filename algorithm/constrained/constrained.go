@@ -0,0 +1,405 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Reference:
+// Nocedal, Jorge, and Stephen Wright. Numerical optimization.
+// Springer Science & Business Media, 2006. (Chapter 17)
+
+/* -------------------------------------------------------------------------- */
+
+package constrained
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/lbfgs"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+type Objective func(ConstVector) (MagicScalar, error)
+
+// Vector-valued constraint function
+type Constraint func(ConstVector) (MagicVector, error)
+
+// Equality constraints g(x) = 0
+type Equality struct {
+  Value Constraint
+}
+
+// Inequality constraints h(x) <= 0
+type Inequality struct {
+  Value Constraint
+}
+
+// Tolerance for the KKT residuals
+type Epsilon struct {
+  Value float64
+}
+
+// Maximum number of outer iterations
+type MaxIterations struct {
+  Value int
+}
+
+// Initial penalty parameter
+type Penalty struct {
+  Value float64
+}
+
+type Hook struct {
+  Value func(x, lambda, mu ConstVector, y ConstScalar) bool
+}
+
+// Returned if the KKT conditions are not satisfied after the maximum number
+// of outer iterations. The result of the last iteration is returned together
+// with this error.
+var ErrMaxIterations = fmt.Errorf("maximum number of iterations reached")
+
+// maximum number of L-BFGS iterations for minimizing the augmented
+// Lagrangian, which becomes ill-conditioned for large penalty parameters
+const maxInnerIterations = 1000
+
+/* -------------------------------------------------------------------------- */
+
+type Result struct {
+  // solution
+  X               Vector
+  // Lagrange multipliers of equality and inequality constraints
+  Lambda          Vector
+  Mu              Vector
+  // value of the objective function at X
+  Value           float64
+  // KKT residuals: norm of the gradient of the Lagrangian, maximum
+  // constraint violation, and maximum of |mu_i h_i(x)|
+  Stationarity    float64
+  Feasibility     float64
+  Complementarity float64
+}
+
+/* -------------------------------------------------------------------------- */
+
+// evaluate constraints, returns an empty vector if g is nil
+func evalConstraint(g Constraint, x ConstVector) (Vector, error) {
+  if g == nil {
+    return NullDenseFloat64Vector(0), nil
+  }
+  y, err := g(x)
+  if err != nil {
+    return nil, err
+  }
+  r := NullDenseFloat64Vector(y.Dim())
+  r.Set(y)
+  return r, nil
+}
+
+// add J^T lambda to the gradient r, where J is the Jacobian of g at x
+func addJacobian(r Vector, g Constraint, x ConstVector, lambda ConstVector) error {
+  if g == nil || lambda.Dim() == 0 {
+    return nil
+  }
+  var err error
+  f := func(x ConstVector) ConstVector {
+    y, e := g(x)
+    if e != nil {
+      err = e
+      return NullDenseReal64Vector(lambda.Dim())
+    }
+    return y
+  }
+  J := NullDenseFloat64Matrix(lambda.Dim(), x.Dim())
+  J.Jacobian(f, AsDenseReal64Vector(x))
+  if err != nil {
+    return err
+  }
+  t := NullDenseFloat64Vector(x.Dim())
+  t.VdotM(lambda, J)
+  r.VaddV(r, t)
+  return nil
+}
+
+// compute KKT residuals
+func kkt(f Objective, g, h Constraint, r *Result) error {
+  x := r.X
+  X := AsDenseReal64Vector(x)
+  X.Variables(1)
+  y, err := f(X)
+  if err != nil {
+    return err
+  }
+  // gradient of the Lagrangian
+  d := NullDenseFloat64Vector(x.Dim())
+  for i := 0; i < y.GetN(); i++ {
+    d[i] = y.GetDerivative(i)
+  }
+  if err := addJacobian(d, g, x, r.Lambda); err != nil {
+    return err
+  }
+  if err := addJacobian(d, h, x, r.Mu); err != nil {
+    return err
+  }
+  gx, err := evalConstraint(g, x)
+  if err != nil {
+    return err
+  }
+  hx, err := evalConstraint(h, x)
+  if err != nil {
+    return err
+  }
+  r.Value           = y.GetFloat64()
+  r.Stationarity    = NullFloat64().Vnorm(d).GetFloat64()
+  r.Feasibility     = 0.0
+  r.Complementarity = 0.0
+  for i := 0; i < gx.Dim(); i++ {
+    r.Feasibility = math.Max(r.Feasibility, math.Abs(gx.At(i).GetFloat64()))
+  }
+  for i := 0; i < hx.Dim(); i++ {
+    r.Feasibility     = math.Max(r.Feasibility, hx.At(i).GetFloat64())
+    r.Complementarity = math.Max(r.Complementarity, math.Abs(r.Mu.At(i).GetFloat64()*hx.At(i).GetFloat64()))
+  }
+  return nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+func augmentedLagrangian(f Objective, x0 Vector, g, h Constraint, epsilon Epsilon, maxIterations MaxIterations, penalty Penalty, hook Hook) (Result, error) {
+  r := Result{}
+  r.X = x0.CloneVector()
+  // evaluate constraints at the initial value to obtain the number of
+  // constraints
+  gx, err := evalConstraint(g, r.X)
+  if err != nil {
+    return r, fmt.Errorf("invalid initial value: %s", err)
+  }
+  hx, err := evalConstraint(h, r.X)
+  if err != nil {
+    return r, fmt.Errorf("invalid initial value: %s", err)
+  }
+  lambda  := NullDenseFloat64Vector(gx.Dim())
+  mu      := NullDenseFloat64Vector(hx.Dim())
+  r.Lambda = lambda
+  r.Mu     = mu
+
+  rho := penalty.Value
+  // tolerance of the inner solver
+  omega := 1e-2
+  // constraint violation of the previous iteration
+  violation := math.Inf(1)
+
+  // augmented Lagrangian
+  // L(x) = f(x) + sum_i lambda_i g_i(x) + rho/2 sum_i g_i(x)^2
+  //      + 1/(2 rho) sum_j [max(0, mu_j + rho h_j(x))^2 - mu_j^2]
+  lagrangian := func(x ConstVector) (MagicScalar, error) {
+    y, err := f(x)
+    if err != nil {
+      return nil, err
+    }
+    s := NullReal64()
+    t := NullReal64()
+    s.Set(y)
+    if g != nil {
+      gx, err := g(x)
+      if err != nil {
+        return nil, err
+      }
+      for i := 0; i < gx.Dim(); i++ {
+        // (lambda_i + rho/2 g_i) g_i
+        t.Mul(gx.ConstAt(i), ConstFloat64(rho/2.0))
+        t.Add(t, lambda.ConstAt(i))
+        t.Mul(t, gx.ConstAt(i))
+        s.Add(s, t)
+      }
+    }
+    if h != nil {
+      hx, err := h(x)
+      if err != nil {
+        return nil, err
+      }
+      for j := 0; j < hx.Dim(); j++ {
+        t.Mul(hx.ConstAt(j), ConstFloat64(rho))
+        t.Add(t, ConstFloat64(mu[j]))
+        if t.GetFloat64() > 0.0 {
+          t.Mul(t, t)
+        } else {
+          t.Reset()
+        }
+        t.Sub(t, ConstFloat64(mu[j]*mu[j]))
+        t.Div(t, ConstFloat64(2.0*rho))
+        s.Add(s, t)
+      }
+    }
+    return s, nil
+  }
+  for k := 0; k < maxIterations.Value; k++ {
+    // minimize augmented Lagrangian
+    x, err := lbfgs.Run(lagrangian, r.X,
+      lbfgs.Epsilon      {Value: math.Max(omega, 0.1*epsilon.Value)},
+      lbfgs.MaxIterations{Value: maxInnerIterations})
+    if err != nil && err != lbfgs.ErrLineSearch {
+      return r, err
+    }
+    r.X.Set(x)
+    // update multipliers
+    if gx, err = evalConstraint(g, r.X); err != nil {
+      return r, err
+    }
+    if hx, err = evalConstraint(h, r.X); err != nil {
+      return r, err
+    }
+    for i := 0; i < gx.Dim(); i++ {
+      lambda[i] += rho*gx.At(i).GetFloat64()
+    }
+    for j := 0; j < hx.Dim(); j++ {
+      mu[j] = math.Max(0.0, mu[j] + rho*hx.At(j).GetFloat64())
+    }
+    if err := kkt(f, g, h, &r); err != nil {
+      return r, err
+    }
+    // execute hook if available
+    if hook.Value != nil && hook.Value(r.X, r.Lambda, r.Mu, ConstFloat64(r.Value)) {
+      return r, nil
+    }
+    // evaluate stop criterion
+    if r.Stationarity < epsilon.Value && r.Feasibility < epsilon.Value && r.Complementarity < epsilon.Value {
+      return r, nil
+    }
+    // increase penalty if the constraint violation did not decrease
+    // sufficiently
+    if v := math.Max(r.Feasibility, r.Complementarity); v > 0.25*violation {
+      rho *= 10.0
+    } else {
+      violation = v
+    }
+    omega *= 0.1
+  }
+  return r, ErrMaxIterations
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f(x) subject to g(x) = 0 and h(x) <= 0 with an augmented
+// Lagrangian method. In each outer iteration the augmented Lagrangian is
+// minimized with L-BFGS, followed by an update of the multipliers. The
+// result contains the Lagrange multipliers and the KKT residuals, which are
+// computed with Jacobians of the constraint functions. An error is returned
+// together with the last iterate if the KKT conditions are not satisfied
+// after the maximum number of outer iterations. Optional arguments:
+//
+//   Equality     : equality constraints g
+//   Inequality   : inequality constraints h
+//   Epsilon      : stop if all KKT residuals are smaller than epsilon
+//   MaxIterations: maximum number of outer iterations
+//   Penalty      : initial penalty parameter
+//   Hook         : called after every outer iteration, stop if it returns
+//                  true
+func Run(f Objective, x0 Vector, args ...interface{}) (Result, error) {
+
+  equality      := Equality     { nil}
+  inequality    := Inequality   { nil}
+  hook          := Hook         { nil}
+  epsilon       := Epsilon      {1e-6}
+  maxIterations := MaxIterations{ 100}
+  penalty       := Penalty      {10.0}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Equality:
+      equality = a
+    case Inequality:
+      inequality = a
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Penalty:
+      penalty = a
+    default:
+      panic("Constrained(): Invalid optional argument!")
+    }
+  }
+  if penalty.Value <= 0.0 {
+    return Result{}, fmt.Errorf("penalty parameter must be positive")
+  }
+  return augmentedLagrangian(f, x0, equality.Value, inequality.Value, epsilon, maxIterations, penalty, hook)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics, where the gradient norm is the norm of the
+// gradient of the Lagrangian. Accepts the same optional arguments as Run
+// and additionally optimize.Trace. Use Run to obtain the Lagrange
+// multipliers.
+func Minimize(f Objective, x0 Vector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  equality   := Equality  { nil}
+  inequality := Inequality{ nil}
+  hook       := Hook      { nil}
+  epsilon    := Epsilon   {1e-6}
+  options    := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Equality:
+      equality = a
+      options  = append(options, a)
+    case Inequality:
+      inequality = a
+      options    = append(options, a)
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // the hook is called after every outer iteration
+  options = append(options, Hook{func(x, lambda, mu ConstVector, y ConstScalar) bool {
+    s := Result{X: AsDenseFloat64Vector(x), Lambda: AsDenseFloat64Vector(lambda), Mu: AsDenseFloat64Vector(mu)}
+    if err := kkt(f, equality.Value, inequality.Value, &s); err != nil {
+      s.Stationarity = math.NaN()
+    }
+    r.Iteration(x, y.GetFloat64(), s.Stationarity)
+    return hook.Value != nil && r.Hook(hook.Value(x, lambda, mu, y))
+  }})
+  s, err := Run(g, x0, options...)
+  if s.X == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  switch {
+  case err == ErrMaxIterations:
+    return r.Result(s.X, s.Value, s.Stationarity, optimize.MaxIterationsReached), nil
+  case err != nil:
+    return r.Result(s.X, s.Value, s.Stationarity, optimize.Failed), err
+  case s.Stationarity < epsilon.Value && s.Feasibility < epsilon.Value && s.Complementarity < epsilon.Value:
+    return r.Result(s.X, s.Value, s.Stationarity, optimize.Converged), nil
+  default:
+    return r.Result(s.X, s.Value, s.Stationarity, optimize.MaxIterationsReached), nil
+  }
+}
@@ -0,0 +1,170 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package constrained
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+func TestConstrained1(test *testing.T) {
+  // min x1 + x2 subject to x1^2 + x2^2 = 2
+  // solution: x = (-1, -1), lambda = 1/2
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    r.Add(x.ConstAt(0), x.ConstAt(1))
+    return r, nil
+  }
+  g := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(1)
+    t := NullReal64()
+    r.At(0).Mul(x.ConstAt(0), x.ConstAt(0))
+    t      .Mul(x.ConstAt(1), x.ConstAt(1))
+    r.At(0).Add(r.At(0), t)
+    r.At(0).Sub(r.At(0), ConstFloat64(2.0))
+    return r, nil
+  }
+  r, err := Run(f, NewDenseFloat64Vector([]float64{1, 0}), Equality{g}, Epsilon{1e-8})
+  if err != nil {
+    test.Error(err); return
+  }
+  if math.Abs(r.X.At(0).GetFloat64() + 1.0) > 1e-6 || math.Abs(r.X.At(1).GetFloat64() + 1.0) > 1e-6 {
+    test.Error("test failed")
+  }
+  if math.Abs(r.Lambda.At(0).GetFloat64() - 0.5) > 1e-6 {
+    test.Error("test failed")
+  }
+  if r.Stationarity > 1e-8 || r.Feasibility > 1e-8 {
+    test.Error("test failed")
+  }
+}
+
+func TestConstrainedMaxIterations(test *testing.T) {
+  // min x1 + x2 subject to x1^2 + x2^2 = 2
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    r.Add(x.ConstAt(0), x.ConstAt(1))
+    return r, nil
+  }
+  g := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(1)
+    t := NullReal64()
+    r.At(0).Mul(x.ConstAt(0), x.ConstAt(0))
+    t      .Mul(x.ConstAt(1), x.ConstAt(1))
+    r.At(0).Add(r.At(0), t)
+    r.At(0).Sub(r.At(0), ConstFloat64(2.0))
+    return r, nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{1, 0})
+  // the KKT conditions are not satisfied after a single iteration
+  if r, err := Run(f, x0, Equality{g}, Epsilon{1e-8}, MaxIterations{1}); err != ErrMaxIterations || r.X == nil {
+    test.Error("test failed")
+  }
+  if r, err := Minimize(f, x0, Equality{g}, Epsilon{1e-8}, MaxIterations{1}); err != nil || r.Status != optimize.MaxIterationsReached {
+    test.Error("test failed")
+  }
+}
+
+func TestConstrained2(test *testing.T) {
+  // min (x1 - 2)^2 + (x2 - 1)^2 subject to x1^2 - x2 <= 0 and x1 + x2 <= 2
+  // solution: x = (1, 1), mu = (2/3, 2/3)
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    t := NullReal64()
+    r.Sub(x.ConstAt(0), ConstFloat64(2.0))
+    r.Mul(r, r)
+    t.Sub(x.ConstAt(1), ConstFloat64(1.0))
+    t.Mul(t, t)
+    r.Add(r, t)
+    return r, nil
+  }
+  h := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(2)
+    r.At(0).Mul(x.ConstAt(0), x.ConstAt(0))
+    r.At(0).Sub(r.At(0), x.ConstAt(1))
+    r.At(1).Add(x.ConstAt(0), x.ConstAt(1))
+    r.At(1).Sub(r.At(1), ConstFloat64(2.0))
+    return r, nil
+  }
+  r, err := Run(f, NewDenseFloat64Vector([]float64{0, 0}), Inequality{h}, Epsilon{1e-8})
+  if err != nil {
+    test.Error(err); return
+  }
+  if math.Abs(r.X.At(0).GetFloat64() - 1.0) > 1e-6 || math.Abs(r.X.At(1).GetFloat64() - 1.0) > 1e-6 {
+    test.Error("test failed")
+  }
+  if math.Abs(r.Mu.At(0).GetFloat64() - 2.0/3.0) > 1e-6 || math.Abs(r.Mu.At(1).GetFloat64() - 2.0/3.0) > 1e-6 {
+    test.Error("test failed")
+  }
+  if r.Stationarity > 1e-8 || r.Feasibility > 1e-8 || r.Complementarity > 1e-8 {
+    test.Error("test failed")
+  }
+}
+
+func TestConstrained3(test *testing.T) {
+  // maximum entropy distribution on {0,1,2} with mean 1.5 and p_i >= 0
+  // solution: p_i proportional to exp(-theta i)
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    t := NullReal64()
+    for i := 0; i < x.Dim(); i++ {
+      if x.ConstAt(i).GetFloat64() <= 0.0 {
+        continue
+      }
+      t.Log(x.ConstAt(i))
+      t.Mul(t, x.ConstAt(i))
+      r.Add(r, t)
+    }
+    return r, nil
+  }
+  g := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(2)
+    t := NullReal64()
+    for i := 0; i < x.Dim(); i++ {
+      r.At(0).Add(r.At(0), x.ConstAt(i))
+      t.Mul(x.ConstAt(i), ConstFloat64(float64(i)))
+      r.At(1).Add(r.At(1), t)
+    }
+    r.At(0).Sub(r.At(0), ConstFloat64(1.0))
+    r.At(1).Sub(r.At(1), ConstFloat64(1.5))
+    return r, nil
+  }
+  h := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(x.Dim())
+    for i := 0; i < x.Dim(); i++ {
+      r.At(i).Neg(x.ConstAt(i))
+    }
+    return r, nil
+  }
+  r, err := Run(f, NewDenseFloat64Vector([]float64{0.3, 0.3, 0.4}), Equality{g}, Inequality{h}, Epsilon{1e-8})
+  if err != nil {
+    test.Error(err); return
+  }
+  // p_1^2 = p_0 p_2 for geometric distributions
+  p0 := r.X.At(0).GetFloat64()
+  p1 := r.X.At(1).GetFloat64()
+  p2 := r.X.At(2).GetFloat64()
+  if math.Abs(p1*p1 - p0*p2) > 1e-6 || math.Abs(p1 + 2*p2 - 1.5) > 1e-6 {
+    test.Error("test failed")
+  }
+}
@@ -23,6 +23,7 @@ package determinant
 
 import . "github.com/pbenner/autodiff"
 import   "github.com/pbenner/autodiff/algorithm/cholesky"
+import   "github.com/pbenner/autodiff/algorithm/lu"
 
 /* -------------------------------------------------------------------------- */
 
@@ -36,47 +37,21 @@ type LogScale struct {
 
 type InSitu struct {
   Cholesky cholesky.InSitu
+  LU       lu.InSitu
 }
 
 /* -------------------------------------------------------------------------- */
 
-func determinantNaive(a ConstMatrix) Scalar {
-  n, _ := a.Dims()
-  t1   := NullScalar(a.ElementType())
-  t2   := NullScalar(a.ElementType())
-  det  := NullScalar(a.ElementType())
-
-  if (n < 1) {
-    /* nothing to do */
-  } else if n == 1 {
-    det.Set(a.ConstAt(0, 0))
-  } else if n == 2 {
-    t1.Mul(a.ConstAt(0, 0), a.ConstAt(1, 1))
-    t2.Mul(a.ConstAt(1, 0), a.ConstAt(0, 1))
-    det.Sub(t1, t2)
-  } else {
-    m := NullDenseMatrix(a.ElementType(), n-1, n-1)
-    for j1 := 0; j1 < n; j1++ {
-      for i := 1; i < n; i++ {
-        j2 := 0
-        for j := 0; j < n; j++ {
-          if j == j1 {
-            continue
-          }
-          m.At(i-1, j2).Set(a.ConstAt(i, j))
-          j2++;
-        }
-      }
-      if j1 % 2 == 0 {
-        t1.Mul(a.ConstAt(0, j1), determinantNaive(m))
-        det.Add(det, t1)
-      } else {
-        t1.Mul(a.ConstAt(0, j1), determinantNaive(m))
-        det.Sub(det, t1)
-      }
-    }
+func determinantLU(a ConstMatrix, inSitu *InSitu) (Scalar, error) {
+  n, m := a.Dims()
+  if n != m {
+    panic("Matrix is not a square matrix!")
+  }
+  _, U, p, err := lu.Run(a, &inSitu.LU)
+  if err != nil {
+    return nil, err
   }
-  return det
+  return lu.Determinant(U, p), nil
 }
 
 func determinantPD(a ConstMatrix, logScale bool, inSitu *InSitu) (Scalar, error) {
@@ -111,7 +86,7 @@ func determinant(a ConstMatrix, positiveDefinite, logScale bool, inSitu *InSitu)
   if positiveDefinite {
     return determinantPD(a, logScale, inSitu)
   } else {
-    return determinantNaive(a), nil
+    return determinantLU(a, inSitu)
   }
 }
 
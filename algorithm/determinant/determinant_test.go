@@ -40,7 +40,7 @@ func TestDeterminant2(t *testing.T) {
 
   m := NewDenseFloat64Matrix([]float64{1,2,3,4,5,6,7,8,9}, 3, 3)
 
-  if r, _ := Run(m); r.GetFloat64() != 0 {
+  if r, _ := Run(m); math.Abs(r.GetFloat64()) > 1e-10 {
     t.Error("Matrix determinant failed!")
   }
 
@@ -50,7 +50,7 @@ func TestDeterminant3(t *testing.T) {
 
   m := NewDenseFloat64Matrix([]float64{3,2,0,1, 4,0,1,2, 3,0,2,1, 9,2,3,1}, 4, 4)
 
-  if r, _ := Run(m); r.GetFloat64() != 24 {
+  if r, _ := Run(m); math.Abs(r.GetFloat64() - 24) > 1e-10 {
     t.Error("Matrix determinant failed!")
   }
 
@@ -142,7 +142,16 @@ func sortEigensystem(eigenvectors Matrix, eigenvalues Vector) {
     sortEigenvalues(eigenvalues)
   } else {
     p := sortEigenvalues(eigenvalues)
-    eigenvectors.PermuteColumns(p)
+    // PermuteColumns only handles permutations that are products of
+    // disjoint transpositions, hence copy columns explicitly
+    n, m := eigenvectors.Dims()
+    r := NullDenseMatrix(eigenvectors.ElementType(), n, m)
+    for j := 0; j < m; j++ {
+      for i := 0; i < n; i++ {
+        r.At(i, j).Set(eigenvectors.ConstAt(i, p[j]))
+      }
+    }
+    eigenvectors.Set(r)
   }
 }
 
@@ -179,6 +188,9 @@ func eigensystem(a Matrix, inSitu *InSitu, computeEigenvectors, symmetric bool,
 
 /* -------------------------------------------------------------------------- */
 
+// Compute eigenvalues and eigenvectors of a real matrix. Eigenvalues are
+// sorted by absolute value in descending order. For complex eigenvalues only
+// the real part is returned, use RunComplex to obtain also imaginary parts.
 func Run(a Matrix, args_ ...interface{}) (Vector, Matrix, error) {
   n, _ := a.Dims()
   t    := a.ElementType()
@@ -212,5 +224,8 @@ func Run(a Matrix, args_ ...interface{}) (Vector, Matrix, error) {
       inSitu.QrAlgorithm.U = inSitu.Eigenvectors
     }
   }
+  if a_, ok := a.(*DenseFloat64Matrix); ok && symmetric && GonumBackend() {
+    return gonumEigensystemSymmetric(a_, inSitu, computeEigenvectors)
+  }
   return eigensystem(a, inSitu, computeEigenvectors, symmetric, args)
 }
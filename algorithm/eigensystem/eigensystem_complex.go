@@ -0,0 +1,358 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eigensystem
+
+/* -------------------------------------------------------------------------- */
+
+import   "math"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
+
+/* complex arithmetic on pairs of scalars
+ * -------------------------------------------------------------------------- */
+
+type complexScalar struct {
+  re Scalar
+  im Scalar
+}
+
+func nullComplexScalar(t ScalarType) complexScalar {
+  return complexScalar{NullScalar(t), NullScalar(t)}
+}
+
+func (a complexScalar) abs() float64 {
+  return math.Hypot(a.re.GetFloat64(), a.im.GetFloat64())
+}
+
+// r = a b, where r must be different from a and b
+func complexMul(r, a, b complexScalar, t Scalar) {
+  r.re.Mul(a.re, b.re)
+  t   .Mul(a.im, b.im)
+  r.re.Sub(r.re, t)
+  r.im.Mul(a.re, b.im)
+  t   .Mul(a.im, b.re)
+  r.im.Add(r.im, t)
+}
+
+// r = a/b, where r must be different from a and b
+func complexDiv(r, a, b complexScalar, t1, t2 Scalar) {
+  // t1 = |b|^2
+  t1  .Mul(b.re, b.re)
+  t2  .Mul(b.im, b.im)
+  t1  .Add(t1, t2)
+  r.re.Mul(a.re, b.re)
+  t2  .Mul(a.im, b.im)
+  r.re.Add(r.re, t2)
+  r.re.Div(r.re, t1)
+  r.im.Mul(a.im, b.re)
+  t2  .Mul(a.re, b.im)
+  r.im.Sub(r.im, t2)
+  r.im.Div(r.im, t1)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Eigenvalues of the quasi upper triangular matrix h. Complex conjugate
+// pairs correspond to 2x2 blocks on the diagonal, the eigenvalue with
+// positive imaginary part comes first.
+func getComplexEigenvalues(re, im Vector, h Matrix, t Scalar) {
+  n, _ := h.Dims()
+  c2 := ConstFloat64(2.0)
+  c4 := ConstFloat64(4.0)
+  for i := 0; i < n; i++ {
+    if i == n-1 || h.ConstAt(i+1, i).GetFloat64() == 0.0 {
+      re.At(i).Set(h.ConstAt(i, i))
+      im.At(i).Reset()
+      continue
+    }
+    h11 := h.ConstAt(i+0, i+0)
+    h12 := h.ConstAt(i+0, i+1)
+    h21 := h.ConstAt(i+1, i+0)
+    h22 := h.ConstAt(i+1, i+1)
+    // t = (h11 - h22)^2 + 4 h12 h21
+    t.Sub(h11, h22)
+    t.Mul(t, t)
+    im.At(i).Mul(h12, h21)
+    im.At(i).Mul(im.At(i), c4)
+    t.Add(t, im.At(i))
+    re.At(i).Add(h11, h22)
+    re.At(i).Div(re.At(i), c2)
+    if t.GetFloat64() >= 0.0 {
+      // block with real eigenvalues
+      t.Sqrt(t)
+      t.Div(t, c2)
+      re.At(i+1).Sub(re.At(i), t)
+      re.At(i+0).Add(re.At(i), t)
+      im.At(i+0).Reset()
+      im.At(i+1).Reset()
+    } else {
+      t.Neg(t)
+      t.Sqrt(t)
+      im.At(i+0).Div(t, c2)
+      im.At(i+1).Neg(im.At(i))
+      re.At(i+1).Set(re.At(i))
+    }
+    i++
+  }
+}
+
+// Compute the eigenvector z of the quasi upper triangular matrix h for the
+// kth eigenvalue lambda by back substitution.
+func getSchurEigenvector(zr, zi Vector, lambda complexScalar, h Matrix, k int, smin float64) {
+  n, _ := h.Dims()
+  t  := zr.ElementType()
+  t1 := NullScalar(t)
+  t2 := NullScalar(t)
+  s1 := nullComplexScalar(t)
+  s2 := nullComplexScalar(t)
+  d1 := nullComplexScalar(t)
+  d2 := nullComplexScalar(t)
+  c1 := nullComplexScalar(t)
+  c2 := nullComplexScalar(t)
+  zr.Reset()
+  zi.Reset()
+  // last index of the block that contains the eigenvalue
+  e := k
+  if k > 0 && h.ConstAt(k, k-1).GetFloat64() != 0.0 {
+    // second eigenvalue of a 2x2 block: (h22 - lambda) z_k = -h21 z_{k-1}
+    zr.At(k-1).Sub(lambda.re, h.ConstAt(k, k))
+    zi.At(k-1).Set(lambda.im)
+    zr.At(k  ).Set(h.ConstAt(k, k-1))
+    k = k-1
+  } else if k < n-1 && h.ConstAt(k+1, k).GetFloat64() != 0.0 {
+    // first eigenvalue of a 2x2 block: h21 z_k + (h22 - lambda) z_{k+1} = 0
+    zr.At(k  ).Sub(lambda.re, h.ConstAt(k+1, k+1))
+    zi.At(k  ).Set(lambda.im)
+    zr.At(k+1).Set(h.ConstAt(k+1, k))
+    e = k+1
+  } else {
+    zr.At(k).SetFloat64(1.0)
+  }
+  // s = - sum_j h_ij z_j
+  rhs := func(s complexScalar, i int) {
+    s.re.Reset()
+    s.im.Reset()
+    for j := i+1; j <= e; j++ {
+      t1.Mul(h.ConstAt(i, j), zr.ConstAt(j))
+      t2.Mul(h.ConstAt(i, j), zi.ConstAt(j))
+      s.re.Sub(s.re, t1)
+      s.im.Sub(s.im, t2)
+    }
+  }
+  // d = h_ij - lambda delta_ij
+  diag := func(d complexScalar, i, j int) {
+    if i == j {
+      d.re.Sub(h.ConstAt(i, j), lambda.re)
+      d.im.Neg(lambda.im)
+    } else {
+      d.re.Set(h.ConstAt(i, j))
+      d.im.Reset()
+    }
+  }
+  for i := k-1; i >= 0; i-- {
+    if i > 0 && h.ConstAt(i, i-1).GetFloat64() != 0.0 {
+      // 2x2 block at rows i-1 and i, solve with Cramer's rule
+      rhs(s1, i-1)
+      rhs(s2, i)
+      // det = (h11 - lambda)(h22 - lambda) - h12 h21
+      diag(d1, i-1, i-1)
+      diag(d2, i  , i  )
+      complexMul(c1, d1, d2, t1)
+      c1.re.Sub(c1.re, t2.Mul(h.ConstAt(i-1, i), h.ConstAt(i, i-1)))
+      if c1.abs() < smin {
+        c1.re.SetFloat64(smin)
+        c1.im.Reset()
+      }
+      // z_{i-1} = (s1 (h22 - lambda) - h12 s2)/det
+      complexMul(c2, s1, d2, t1)
+      t1.Mul(h.ConstAt(i-1, i), s2.re)
+      c2.re.Sub(c2.re, t1)
+      t1.Mul(h.ConstAt(i-1, i), s2.im)
+      c2.im.Sub(c2.im, t1)
+      complexDiv(d2, c2, c1, t1, t2)
+      zr.At(i-1).Set(d2.re)
+      zi.At(i-1).Set(d2.im)
+      // z_i = ((h11 - lambda) s2 - h21 s1)/det
+      complexMul(c2, d1, s2, t1)
+      t1.Mul(h.ConstAt(i, i-1), s1.re)
+      c2.re.Sub(c2.re, t1)
+      t1.Mul(h.ConstAt(i, i-1), s1.im)
+      c2.im.Sub(c2.im, t1)
+      complexDiv(d1, c2, c1, t1, t2)
+      zr.At(i).Set(d1.re)
+      zi.At(i).Set(d1.im)
+      i--
+    } else {
+      rhs(s1, i)
+      diag(d1, i, i)
+      if d1.abs() < smin {
+        d1.re.SetFloat64(smin)
+        d1.im.Reset()
+      }
+      complexDiv(c1, s1, d1, t1, t2)
+      zr.At(i).Set(c1.re)
+      zi.At(i).Set(c1.im)
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+type sortComplexEigenvaluesType struct {
+  re []float64
+  im []float64
+  p  []int
+}
+
+func (obj sortComplexEigenvaluesType) Len() int {
+  return len(obj.p)
+}
+
+func (obj sortComplexEigenvaluesType) Less(i, j int) bool {
+  a := math.Hypot(obj.re[obj.p[i]], obj.im[obj.p[i]])
+  b := math.Hypot(obj.re[obj.p[j]], obj.im[obj.p[j]])
+  if a != b {
+    return a > b
+  }
+  // keep complex conjugate pairs together
+  if obj.re[obj.p[i]] != obj.re[obj.p[j]] {
+    return obj.re[obj.p[i]] > obj.re[obj.p[j]]
+  }
+  return obj.im[obj.p[i]] > obj.im[obj.p[j]]
+}
+
+func (obj sortComplexEigenvaluesType) Swap(i, j int) {
+  obj.p[i], obj.p[j] = obj.p[j], obj.p[i]
+}
+
+// Returns a permutation that sorts eigenvalues by absolute value in
+// descending order
+func sortComplexEigenvalues(re, im Vector) []int {
+  n   := re.Dim()
+  obj := sortComplexEigenvaluesType{make([]float64, n), make([]float64, n), make([]int, n)}
+  for i := 0; i < n; i++ {
+    obj.re[i] = re.ConstAt(i).GetFloat64()
+    obj.im[i] = im.ConstAt(i).GetFloat64()
+    obj.p [i] = i
+  }
+  sort.Stable(obj)
+  return obj.p
+}
+
+/* -------------------------------------------------------------------------- */
+
+func eigensystemComplex(a Matrix, computeEigenvectors bool, inSitu *InSitu, args ...interface{}) (Vector, Vector, Matrix, Matrix, error) {
+  n, _ := a.Dims()
+  t    := a.ElementType()
+
+  args = append(args, qrAlgorithm.ComputeU{Value: computeEigenvectors})
+  args = append(args, &inSitu.QrAlgorithm)
+  h, u, err := qrAlgorithm.Run(a, args...)
+  if err != nil {
+    return nil, nil, nil, nil, err
+  }
+  re := NullDenseVector(t, n)
+  im := NullDenseVector(t, n)
+  getComplexEigenvalues(re, im, h, NullScalar(t))
+
+  p := sortComplexEigenvalues(re, im)
+
+  re_ := NullDenseVector(t, n)
+  im_ := NullDenseVector(t, n)
+  for i := 0; i < n; i++ {
+    re_.At(i).Set(re.ConstAt(p[i]))
+    im_.At(i).Set(im.ConstAt(p[i]))
+  }
+  if !computeEigenvectors {
+    return re_, im_, nil, nil, nil
+  }
+  // threshold for perturbing singular diagonal elements during back
+  // substitution
+  smin := 0.0
+  for i := 0; i < n; i++ {
+    for j := 0; j < n; j++ {
+      smin = math.Max(smin, math.Abs(h.ConstAt(i, j).GetFloat64()))
+    }
+  }
+  smin = math.Max(1e-15*smin, math.SmallestNonzeroFloat64)
+
+  vr := NullDenseMatrix(t, n, n)
+  vi := NullDenseMatrix(t, n, n)
+  zr := NullDenseVector(t, n)
+  zi := NullDenseVector(t, n)
+  s  := NullScalar(t)
+  s1 := NullScalar(t)
+  for j := 0; j < n; j++ {
+    lambda := complexScalar{re.At(p[j]), im.At(p[j])}
+    getSchurEigenvector(zr, zi, lambda, h, p[j], smin)
+    xr := ColView(vr, j)
+    xi := ColView(vi, j)
+    xr.MdotV(u, zr)
+    xi.MdotV(u, zi)
+    // normalize eigenvector
+    s .VdotV(xr, xr)
+    s1.VdotV(xi, xi)
+    s .Add(s, s1)
+    s .Sqrt(s)
+    xr.VdivS(xr, s)
+    xi.VdivS(xi, s)
+  }
+  return re_, im_, vr, vi, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute eigenvalues and eigenvectors of a general real matrix. The
+// eigenvalues are obtained from the 1x1 and 2x2 blocks of the real Schur
+// form computed by the QR algorithm. Real and imaginary parts of the
+// eigenvalues are returned as separate vectors, sorted by absolute value in
+// descending order, where complex conjugate pairs are adjacent with the
+// positive imaginary part first. The jth columns of the two eigenvector
+// matrices contain the real and imaginary part of the eigenvector of the
+// jth eigenvalue, normalized to unit length.
+func RunComplex(a Matrix, args_ ...interface{}) (Vector, Vector, Matrix, Matrix, error) {
+  n, m := a.Dims()
+  if n != m {
+    panic("RunComplex(): Not a square matrix!")
+  }
+  // default values for optional arguments
+  computeEigenvectors := true
+  inSitu              := &InSitu{}
+  // arguments passed on to the qrAlgorithm
+  var args []interface{}
+  // loop over optional arguments
+  for _, arg := range args_ {
+    switch tmp := arg.(type) {
+    case ComputeEigenvectors:
+      computeEigenvectors = tmp.Value
+    case Symmetric:
+      // eigenvalues of symmetric matrices are real, but the
+      // general algorithm is used nevertheless
+    case qrAlgorithm.ComputeU:
+      // drop this option
+    case *InSitu:
+      inSitu = tmp
+    case InSitu:
+      panic("InSitu must be passed by reference")
+    default:
+      args = append(args, arg)
+    }
+  }
+  return eigensystemComplex(a, computeEigenvectors, inSitu, args...)
+}
@@ -0,0 +1,47 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eigensystem
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+import   "gonum.org/v1/gonum/mat"
+
+/* -------------------------------------------------------------------------- */
+
+func gonumEigensystemSymmetric(a *DenseFloat64Matrix, inSitu *InSitu, computeEigenvectors bool) (Vector, Matrix, error) {
+  eigenvalues  := inSitu.Eigenvalues
+  eigenvectors := inSitu.Eigenvectors
+
+  r := mat.EigenSym{}
+  if !r.Factorize(AsGonumSymDense(a), computeEigenvectors) {
+    return nil, nil, fmt.Errorf("eigen decomposition failed")
+  }
+  for i, v := range r.Values(nil) {
+    eigenvalues.At(i).SetFloat64(v)
+  }
+  if computeEigenvectors {
+    v := mat.Dense{}
+    r.VectorsTo(&v)
+    eigenvectors.Set(FromGonumDense(&v))
+  }
+  sortEigensystem(eigenvectors, eigenvalues)
+
+  return eigenvalues, eigenvectors, nil
+}
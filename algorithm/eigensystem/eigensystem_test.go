@@ -78,3 +78,127 @@ func Test2(test *testing.T) {
     }
   }
 }
+
+func TestGonum(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    2, 1, 0,
+    1, 3, 1,
+    0, 1, 4 }, 3, 3)
+  e1, v1, _ := Run(a, Symmetric{true})
+
+  SetGonumBackend(true)
+  defer SetGonumBackend(false)
+
+  e2, v2, err := Run(a, Symmetric{true})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if !e1.Equals(e2, 1e-8) {
+    test.Error("test failed")
+  }
+  // eigenvectors are unique up to their sign
+  for j := 0; j < 3; j++ {
+    s := v1.At(0, j).GetFloat64()*v2.At(0, j).GetFloat64()
+    for i := 0; i < 3; i++ {
+      if math.Abs(v1.At(i, j).GetFloat64() - math.Copysign(1, s)*v2.At(i, j).GetFloat64()) > 1e-8 {
+        test.Error("test failed")
+      }
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func checkComplexEigenpairs(test *testing.T, a Matrix, re, im Vector, vr, vi Matrix) {
+  n, _ := a.Dims()
+  for j := 0; j < n; j++ {
+    l_re := re.ConstAt(j).GetFloat64()
+    l_im := im.ConstAt(j).GetFloat64()
+    s := 0.0
+    for i := 0; i < n; i++ {
+      // (A x)_i - lambda x_i
+      r1 := 0.0
+      r2 := 0.0
+      for k := 0; k < n; k++ {
+        r1 += a.ConstAt(i, k).GetFloat64()*vr.ConstAt(k, j).GetFloat64()
+        r2 += a.ConstAt(i, k).GetFloat64()*vi.ConstAt(k, j).GetFloat64()
+      }
+      r1 -= l_re*vr.ConstAt(i, j).GetFloat64() - l_im*vi.ConstAt(i, j).GetFloat64()
+      r2 -= l_re*vi.ConstAt(i, j).GetFloat64() + l_im*vr.ConstAt(i, j).GetFloat64()
+      s  += r1*r1 + r2*r2
+    }
+    if math.Sqrt(s) > 1e-8 {
+      test.Errorf("test failed for eigenvector `%d'", j)
+    }
+  }
+}
+
+func TestComplex1(test *testing.T) {
+  // transition matrix of a Markov chain that cycles through three states
+  a := NewDenseFloat64Matrix([]float64{
+    0.1, 0.8, 0.1,
+    0.1, 0.1, 0.8,
+    0.8, 0.1, 0.1 }, 3, 3)
+
+  re, im, vr, vi, err := RunComplex(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  // eigenvalues are 1 and -0.35 +/- 0.35 sqrt(3) i
+  if !re.Equals(NewDenseFloat64Vector([]float64{1.0, -0.35, -0.35}), 1e-10) {
+    test.Error("test failed")
+  }
+  if !im.Equals(NewDenseFloat64Vector([]float64{0.0, 0.35*math.Sqrt(3), -0.35*math.Sqrt(3)}), 1e-10) {
+    test.Error("test failed")
+  }
+  checkComplexEigenpairs(test, a, re, im, vr, vi)
+}
+
+func TestComplex2(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+     7,  3,  4, -11, -9, -2,
+    -6,  4, -5,   7,  1, 12,
+    -1, -9,  2,   2,  9,  1,
+    -8,  0, -1,   5,  0,  8,
+    -4,  3, -5,   7,  2, 10,
+    6,  1,  4, -11, -7, -1}, 6, 6)
+
+  re, im, vr, vi, err := RunComplex(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  // eigenvalues are 5 +/- 6i, 4, 3, 1 +/- 2i
+  if !re.Equals(NewDenseFloat64Vector([]float64{5, 5, 4, 3, 1, 1}), 1e-8) {
+    test.Error("test failed")
+  }
+  if !im.Equals(NewDenseFloat64Vector([]float64{6, -6, 0, 0, 2, -2}), 1e-8) {
+    test.Error("test failed")
+  }
+  checkComplexEigenpairs(test, a, re, im, vr, vi)
+}
+
+func TestComplex3(test *testing.T) {
+  // rotation matrix, eigenvalues are cos(phi) +/- sin(phi) i
+  phi := NewReal64(0.3)
+  Variables(1, phi)
+  c := NewReal64(0.0); c.Cos(phi)
+  s := NewReal64(0.0); s.Sin(phi)
+  t := NewReal64(0.0); t.Neg(s)
+  a := NullDenseReal64Matrix(2, 2)
+  a.At(0, 0).Set(c); a.At(0, 1).Set(t)
+  a.At(1, 0).Set(s); a.At(1, 1).Set(c)
+
+  _, im, _, _, err := RunComplex(a, ComputeEigenvectors{false})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if r := im.ConstAt(0); math.Abs(r.GetFloat64() - math.Sin(0.3)) > 1e-10 {
+    test.Error("test failed")
+  } else if math.Abs(r.GetDerivative(0) - math.Cos(0.3)) > 1e-8 {
+    test.Error("test failed")
+  }
+}
@@ -0,0 +1,149 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package expm
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/lu"
+import   "github.com/pbenner/autodiff/algorithm/matrixNorm"
+
+/* -------------------------------------------------------------------------- */
+
+// Degrees of the Padé approximants, the corresponding maximal 1-norms
+// and coefficients (Higham, 2005)
+var padeDegree = []int{3, 5, 7, 9, 13}
+
+var padeTheta = []float64{
+  1.495585217958292e-2,
+  2.539398330063230e-1,
+  9.504178996162932e-1,
+  2.097847961257068e+0,
+  5.371920351148152e+0 }
+
+var padeCoefficients = [][]float64{
+  {120, 60, 12, 1},
+  {30240, 15120, 3360, 420, 30, 1},
+  {17297280, 8648640, 1995840, 277200, 25200, 1512, 56, 1},
+  {17643225600, 8821612800, 2075673600, 302702400, 30270240, 2162160, 110880, 3960, 90, 1},
+  {64764752532480000, 32382376266240000, 7771770303897600, 1187353796428800, 129060195264000,
+    10559470521600, 670442572800, 33522128640, 1323241920, 40840800, 960960, 16380, 182, 1} }
+
+/* -------------------------------------------------------------------------- */
+
+// Evaluate the Padé approximant r(A) = (V - U)^-1 (V + U), where U contains
+// the odd and V the even terms of the numerator polynomial
+func pade(a ConstMatrix, b []float64) (Matrix, error) {
+  n, _ := a.Dims()
+  e := a.ElementType()
+  t := NullScalar(e)
+  // even powers of A
+  a2 := NullDenseMatrix(e, n, n)
+  a2.MdotM(a, a)
+  p  := NullDenseMatrix(e, n, n)
+  p.SetIdentity()
+  q  := NullDenseMatrix(e, n, n)
+  u  := NullDenseMatrix(e, n, n)
+  v  := NullDenseMatrix(e, n, n)
+  w  := NullDenseMatrix(e, n, n)
+  for k := 0; k < len(b); k += 2 {
+    if k > 0 {
+      q.MdotM(p, a2)
+      p, q = q, p
+    }
+    // V = V + b_k A^k
+    t.SetFloat64(b[k])
+    w.MmulS(p, t)
+    v.MaddM(v, w)
+    // U = U + b_k+1 A^k
+    if k+1 < len(b) {
+      t.SetFloat64(b[k+1])
+      w.MmulS(p, t)
+      u.MaddM(u, w)
+    }
+  }
+  w.MdotM(a, u)
+  u.Set(w)
+  // solve (V - U) X = (V + U)
+  w.MsubM(v, u)
+  v.MaddM(v, u)
+  l, r, pi, err := lu.Run(w)
+  if err != nil {
+    return nil, err
+  }
+  x := NullDenseMatrix(e, n, n)
+  for j := 0; j < n; j++ {
+    if y, err := lu.Solve(l, r, pi, v.Col(j)); err != nil {
+      return nil, err
+    } else {
+      ColView(x, j).Set(y)
+    }
+  }
+  return x, nil
+}
+
+func expm(a Matrix) (Matrix, error) {
+  n, _ := a.Dims()
+  e := a.ElementType()
+  c := matrixNorm.Norm1(a).GetFloat64()
+  for i := 0; i < len(padeDegree)-1; i++ {
+    if c <= padeTheta[i] {
+      return pade(a, padeCoefficients[i])
+    }
+  }
+  // scaling
+  s := 0
+  if c > padeTheta[len(padeTheta)-1] {
+    s = int(math.Ceil(math.Log2(c/padeTheta[len(padeTheta)-1])))
+  }
+  t := NewScalar(e, math.Pow(2.0, -float64(s)))
+  b := NullDenseMatrix(e, n, n)
+  b.MmulS(a, t)
+  x, err := pade(b, padeCoefficients[len(padeCoefficients)-1])
+  if err != nil {
+    return nil, err
+  }
+  // squaring
+  for i := 0; i < s; i++ {
+    b.MdotM(x, x)
+    x, b = b, x
+  }
+  return x, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the matrix exponential of A with the scaling and squaring
+// algorithm of Higham (2005). The degree of the Padé approximant is
+// selected based on the 1-norm of A, which is scaled by a power of two if
+// necessary.
+func Run(a Matrix, args ...interface{}) (Matrix, error) {
+  n, m := a.Dims()
+  if n != m {
+    return nil, fmt.Errorf("`a' must be a square matrix")
+  }
+  if n == 0 {
+    return nil, fmt.Errorf("`a' is an empty matrix")
+  }
+  if len(args) > 0 {
+    panic("Expm(): Invalid optional argument!")
+  }
+  return expm(a)
+}
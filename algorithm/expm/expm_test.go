@@ -0,0 +1,95 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package expm
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestExpm1(test *testing.T) {
+  // exp([0 -t; t 0]) is a rotation by t, test different norms to cover
+  // all Padé approximants and the scaling and squaring phase
+  for _, t := range []float64{0.01, 0.2, 0.9, 2.0, 5.0, 30.0} {
+    a := NewDenseFloat64Matrix([]float64{
+      0, -t,
+      t,  0 }, 2, 2)
+    b := NewDenseFloat64Matrix([]float64{
+      math.Cos(t), -math.Sin(t),
+      math.Sin(t),  math.Cos(t) }, 2, 2)
+    if x, err := Run(a); err != nil {
+      test.Error(err)
+    } else if !x.Equals(b, 1e-12) {
+      test.Errorf("test failed for t = %v", t)
+    }
+  }
+}
+
+func TestExpm2(test *testing.T) {
+  // transition probabilities of a continuous-time Markov chain with two
+  // states, P(t) = exp(Q t)
+  alpha := 0.3
+  beta  := 0.7
+  q := NewDenseFloat64Matrix([]float64{
+    -alpha,  alpha,
+      beta, -beta }, 2, 2)
+  x, err := Run(q)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  s := math.Exp(-(alpha+beta))
+  r := NewDenseFloat64Matrix([]float64{
+    (beta + alpha*s)/(alpha+beta), (alpha - alpha*s)/(alpha+beta),
+    (beta -  beta*s)/(alpha+beta), (alpha +  beta*s)/(alpha+beta) }, 2, 2)
+  if !x.Equals(r, 1e-12) {
+    test.Error("test failed")
+  }
+}
+
+func TestExpm3(test *testing.T) {
+  // derivative of exp(Q t) with respect to t is Q exp(Q t)
+  t := NewReal64(3.0)
+  Variables(1, t)
+  v := []float64{
+    -1.0,  0.5,  0.5,
+     0.2, -0.4,  0.2,
+     0.1,  0.6, -0.7 }
+  q := NewDenseFloat64Matrix(v, 3, 3)
+  a := NullDenseReal64Matrix(3, 3)
+  a.MmulS(q, t)
+
+  x, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  d := NullDenseFloat64Matrix(3, 3)
+  d.MdotM(q, x)
+  for i := 0; i < 3; i++ {
+    for j := 0; j < 3; j++ {
+      if math.Abs(x.At(i, j).GetDerivative(0) - d.At(i, j).GetFloat64()) > 1e-10 {
+        test.Errorf("test failed for element (%d,%d)", i, j)
+      }
+    }
+  }
+}
@@ -22,6 +22,7 @@ import   "math"
 
 import . "github.com/pbenner/autodiff"
 import . "github.com/pbenner/autodiff/algorithm"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -29,13 +30,17 @@ type Epsilon struct {
   Value float64
 }
 
+type MaxIterations struct {
+  Value int
+}
+
 type Hook struct {
   Value func([]float64, ConstVector, ConstScalar) bool
 }
 
 /* -------------------------------------------------------------------------- */
 
-func gradientDescent(f func(ConstVector) (MagicScalar, error), x0 Vector, step, epsilon float64,
+func gradientDescent(f func(ConstVector) (MagicScalar, error), x0 Vector, step, epsilon float64, maxIterations int,
   hook func([]float64, ConstVector, ConstScalar) bool) (Vector, error) {
 
   // copy variables
@@ -44,7 +49,7 @@ func gradientDescent(f func(ConstVector) (MagicScalar, error), x0 Vector, step,
   // slice containing the gradient
   gradient := make([]float64, x.Dim())
 
-  for {
+  for i := 0; i < maxIterations; i++ {
     // evaluate objective function
     s, err := f(x)
     if err != nil {
@@ -78,8 +83,9 @@ func gradientDescent(f func(ConstVector) (MagicScalar, error), x0 Vector, step,
 
 func Run(f func(ConstVector) (MagicScalar, error), x0 Vector, step float64, args ...interface{}) (Vector, error) {
 
-  hook    := Hook   { nil}.Value
-  epsilon := Epsilon{1e-8}.Value
+  hook          := Hook         { nil}.Value
+  epsilon       := Epsilon      {1e-8}.Value
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}.Value
 
   for _, arg := range args {
     switch a := arg.(type) {
@@ -87,9 +93,71 @@ func Run(f func(ConstVector) (MagicScalar, error), x0 Vector, step float64, args
       hook = a.Value
     case Epsilon:
       epsilon = a.Value
+    case MaxIterations:
+      maxIterations = a.Value
     default:
       panic("GradientDescent(): Invalid optional argument!")
     }
   }
-  return gradientDescent(f, x0, step, epsilon, hook)
+  return gradientDescent(f, x0, step, epsilon, maxIterations, hook)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. Accepts the same arguments as Run and
+// additionally optimize.Trace.
+func Minimize(f func(ConstVector) (MagicScalar, error), x0 Vector, step float64, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook          := Hook         { nil}
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  options       := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    case MaxIterations:
+      maxIterations = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // the hook is called at the beginning of every iteration
+  first := true
+  options = append(options, Hook{func(gradient []float64, x ConstVector, y ConstScalar) bool {
+    if first {
+      r.Init(x, y.GetFloat64(), Norm(gradient)); first = false
+    } else {
+      r.Iteration(x, y.GetFloat64(), Norm(gradient))
+    }
+    return hook.Value != nil && r.Hook(hook.Value(gradient, x, y))
+  }})
+  x, err := Run(g, x0, step, options...)
+  y, gnorm, e := optimize.Evaluate(f, x)
+  switch {
+  case err != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), err
+  case e != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), e
+  case gnorm < epsilon.Value:
+    return r.Result(x, y, gnorm, optimize.Converged), nil
+  default:
+    // the hook is not called after the last iteration
+    if !r.Stopped() {
+      r.SetIterations(maxIterations.Value)
+    }
+    return r.Result(x, y, gnorm, optimize.MaxIterationsReached), nil
+  }
 }
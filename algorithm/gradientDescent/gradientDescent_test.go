@@ -22,6 +22,7 @@ package gradientDescent
 import   "testing"
 
 import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -51,3 +52,27 @@ func TestRProp(test *testing.T) {
     test.Error("Inverting matrix failed!")
   }
 }
+
+func TestMinimize(test *testing.T) {
+  // f(x) = x1^2 + x2^2
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    t := NullReal64()
+    r.Mul(x.ConstAt(0), x.ConstAt(0))
+    t.Mul(x.ConstAt(1), x.ConstAt(1))
+    r.Add(r, t)
+    return r, nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{1, 2})
+
+  if r, err := Minimize(f, x0, 0.1, Epsilon{1e-10}); err != nil || r.Status != optimize.Converged || r.GradientNorm >= 1e-10 {
+    test.Error("test failed")
+  }
+  if r, err := Minimize(f, x0, 0.1, MaxIterations{5}); err != nil || r.Status != optimize.MaxIterationsReached || r.Iterations != 5 {
+    test.Error("test failed")
+  }
+  hook := Hook{func(gradient []float64, x ConstVector, y ConstScalar) bool { return y.GetFloat64() < 1.0 }}
+  if r, err := Minimize(f, x0, 0.1, hook); err != nil || r.Status != optimize.HookStopped || r.Iterations >= 5 {
+    test.Error("test failed")
+  }
+}
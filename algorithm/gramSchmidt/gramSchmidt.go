@@ -37,17 +37,18 @@ func gramSchmidt(a, q, r Matrix, t ScalarType, n, m int) (Matrix, Matrix, error)
   s := NullScalar(t)
 
   for i := 0; i < m; i++ {
+    // columns are updated in place through strided views
+    vi := ColView(v, i)
+    qi := ColView(q, i)
     // r_ii = ||v_i||
-    r.At(i, i).Vnorm(v.ConstCol(i))
-    for k := 0; k < n; k++ {
-      q.At(k, i).Div(v.ConstAt(k, i), r.ConstAt(i, i))
-    }
+    r.At(i, i).Vnorm(vi)
+    qi.VdivS(vi, r.ConstAt(i, i))
     for j := i+1; j < m; j++ {
-      w := v.ConstCol(j)
-      r.At(i, j).VdotV(q.ConstCol(i), w)
+      vj := ColView(v, j)
+      r.At(i, j).VdotV(qi, vj)
       for k := 0; k < n; k++ {
-        s.Mul(r.ConstAt(i, j), q.ConstAt(k, i))
-        v.At(k, j).Sub(w.ConstAt(k), s)
+        s.Mul(r.ConstAt(i, j), qi.ConstAt(k))
+        vj.At(k).Sub(vj.ConstAt(k), s)
       }
     }
   }
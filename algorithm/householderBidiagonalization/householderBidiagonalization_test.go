@@ -52,3 +52,23 @@ func Test1(test *testing.T) {
     test.Error("test failed")
   }
 }
+
+func TestRunAccumulateV(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+     1,  2,  3,  4,
+     4,  5,  6, -1,
+     7,  8,  9,  2,
+    10, 11, 12,  3,
+    -1,  0,  2,  1}, 5, 4)
+
+  b, u, v, _ := Run(a, ComputeU{true}, ComputeV{true})
+
+  r := NullDenseFloat64Matrix(5, 4)
+  r.MdotM(a,v)
+  r.MdotM(u.T(), r)
+  t := NewFloat64(0.0)
+
+  if t.Mnorm(r.MsubM(r, b)).GetFloat64() > 1e-8 {
+    test.Error("test failed")
+  }
+}
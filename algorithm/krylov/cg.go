@@ -0,0 +1,105 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package krylov
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func cg(f Operator, b ConstVector, t ScalarType, opts options) (Vector, error) {
+  n := b.Dim()
+  x := NullDenseVector(t, n)
+  r := NullDenseVector(t, n)
+  z := NullDenseVector(t, n)
+  p := NullDenseVector(t, n)
+  q := NullDenseVector(t, n)
+
+  alpha := NullScalar(t)
+  beta  := NullScalar(t)
+  rz1   := NullScalar(t)
+  rz2   := NullScalar(t)
+  rnorm := NullScalar(t)
+  bnorm := NullScalar(t)
+
+  bnorm.Vnorm(b)
+  tol := opts.epsilon.Value*bnorm.GetFloat64()
+
+  // r = b - A x
+  initialize(f, b, x, r, opts.x0)
+  // z = M^-1 r
+  if opts.preconditioner.Value != nil {
+    opts.preconditioner.Value(z, r)
+  } else {
+    z.Set(r)
+  }
+  p.Set(z)
+  rz1.VdotV(r, z)
+
+  for i := 0; ; i++ {
+    rnorm.Vnorm(r)
+    if opts.hook.Value != nil && opts.hook.Value(x, rnorm) {
+      break
+    }
+    if rnorm.GetFloat64() <= tol {
+      break
+    }
+    if i >= opts.maxIterations.Value {
+      return x, fmt.Errorf("maximum number of iterations reached")
+    }
+    // alpha = r^T z / p^T A p
+    f(q, p)
+    alpha.VdotV(p, q)
+    if alpha.GetFloat64() <= 0.0 {
+      return x, fmt.Errorf("matrix is not positive definite")
+    }
+    alpha.Div(rz1, alpha)
+    // x = x + alpha p
+    z.VmulS(p, alpha)
+    x.VaddV(x, z)
+    // r = r - alpha A p
+    q.VmulS(q, alpha)
+    r.VsubV(r, q)
+    // z = M^-1 r
+    if opts.preconditioner.Value != nil {
+      opts.preconditioner.Value(z, r)
+    } else {
+      z.Set(r)
+    }
+    rz2.VdotV(r, z)
+    // p = z + beta p
+    beta.Div(rz2, rz1)
+    p.VmulS(p, beta)
+    p.VaddV(p, z)
+    rz1.Set(rz2)
+  }
+  return x, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Solve A x = b with the (preconditioned) conjugate gradient method, where
+// A must be symmetric positive definite. The argument a is either a
+// ConstMatrix or an Operator that computes matrix vector products.
+func CG(a interface{}, b ConstVector, args ...interface{}) (Vector, error) {
+  f, t := getOperator(a, b)
+  opts := getOptions("CG", b.Dim(), args...)
+  return cg(f, b, t, opts)
+}
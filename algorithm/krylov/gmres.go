@@ -0,0 +1,193 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package krylov
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func gmres(f Operator, b ConstVector, t ScalarType, opts options) (Vector, error) {
+  n := b.Dim()
+  m := opts.restart.Value
+  x := NullDenseVector(t, n)
+  r := NullDenseVector(t, n)
+  w := NullDenseVector(t, n)
+  z := NullDenseVector(t, n)
+  y := NullDenseVector(t, m)
+  g := NullDenseVector(t, m+1)
+  c := NullDenseVector(t, m)
+  s := NullDenseVector(t, m)
+  // orthonormal basis of the Krylov subspace
+  V := NullDenseMatrix(t, n, m+1)
+  // Hessenberg matrix
+  H := NullDenseMatrix(t, m+1, m)
+  // current iterate passed to the hook
+  X := NullDenseVector(t, n)
+
+  t1    := NullScalar(t)
+  t2    := NullScalar(t)
+  rnorm := NullScalar(t)
+  bnorm := NullScalar(t)
+
+  bnorm.Vnorm(b)
+  tol := opts.epsilon.Value*bnorm.GetFloat64()
+
+  // z = M^-1 v
+  precondition := func(r Vector, x ConstVector) {
+    if opts.preconditioner.Value != nil {
+      opts.preconditioner.Value(r, x)
+    } else {
+      r.Set(x)
+    }
+  }
+  // y = H^-1 g, where H is the upper triangular matrix of the first k
+  // Arnoldi steps
+  solve := func(k int) {
+    for i := k-1; i >= 0; i-- {
+      yi := y.At(i)
+      yi.Set(g.At(i))
+      for l := i+1; l < k; l++ {
+        t1.Mul(H.At(i, l), y.At(l))
+        yi.Sub(yi, t1)
+      }
+      yi.Div(yi, H.At(i, i))
+    }
+  }
+  // x = x + M^-1 V y
+  update := func(x Vector, k int) {
+    w.Reset()
+    for i := 0; i < k; i++ {
+      vi := ColView(V, i)
+      for l := 0; l < n; l++ {
+        t1.Mul(y.At(i), vi.ConstAt(l))
+        w.At(l).Add(w.At(l), t1)
+      }
+    }
+    precondition(z, w)
+    x.VaddV(x, z)
+  }
+  // r = b - A x
+  initialize(f, b, x, r, opts.x0)
+  rnorm.Vnorm(r)
+
+  if opts.hook.Value != nil && opts.hook.Value(x, rnorm) {
+    return x, nil
+  }
+  for iter := 0; ; {
+    if rnorm.GetFloat64() <= tol {
+      break
+    }
+    if iter >= opts.maxIterations.Value {
+      return x, fmt.Errorf("maximum number of iterations reached")
+    }
+    H.Reset()
+    g.Reset()
+    g.At(0).Set(rnorm)
+    ColView(V, 0).VdivS(r, rnorm)
+    // number of Arnoldi steps in this cycle
+    k := 0
+    for k < m && iter < opts.maxIterations.Value {
+      j := k
+      iter++
+      k++
+      // w = A M^-1 v_j
+      precondition(z, ColView(V, j))
+      f(w, z)
+      // modified Gram-Schmidt
+      for i := 0; i <= j; i++ {
+        vi := ColView(V, i)
+        h  := H.At(i, j)
+        h.VdotV(w, vi)
+        for l := 0; l < n; l++ {
+          t1.Mul(h, vi.ConstAt(l))
+          w.At(l).Sub(w.At(l), t1)
+        }
+      }
+      H.At(j+1, j).Vnorm(w)
+      breakdown := H.At(j+1, j).GetFloat64() == 0.0
+      if !breakdown {
+        ColView(V, j+1).VdivS(w, H.At(j+1, j))
+      }
+      // apply previous Givens rotations to the new column
+      for i := 0; i < j; i++ {
+        h1 := H.At(i  , j)
+        h2 := H.At(i+1, j)
+        t1.Mul(c.At(i), h1)
+        t2.Mul(s.At(i), h2)
+        t1.Add(t1, t2)
+        t2.Mul(s.At(i), h1)
+        h2.Mul(c.At(i), h2)
+        h2.Sub(h2, t2)
+        h1.Set(t1)
+      }
+      // compute new rotation that eliminates H[j+1, j]
+      h1 := H.At(j  , j)
+      h2 := H.At(j+1, j)
+      t1.Mul(h1, h1)
+      t2.Mul(h2, h2)
+      t1.Add(t1, t2)
+      t1.Sqrt(t1)
+      c.At(j).Div(h1, t1)
+      s.At(j).Div(h2, t1)
+      h1.Set(t1)
+      h2.Reset()
+      // apply rotation to g
+      t1.Mul(s.At(j), g.At(j))
+      g.At(j+1).Neg(t1)
+      g.At(j).Mul(c.At(j), g.At(j))
+
+      // |g[j+1]| is the norm of the residual of the current iterate
+      rnorm.Abs(g.At(j+1))
+      if opts.hook.Value != nil {
+        // the current iterate is only computed explicitly if a hook is
+        // given
+        X.Set(x)
+        solve(k)
+        update(X, k)
+        if opts.hook.Value(X, rnorm) {
+          return X, nil
+        }
+      }
+      if rnorm.GetFloat64() <= tol || breakdown {
+        break
+      }
+    }
+    solve(k)
+    update(x, k)
+    // r = b - A x
+    f(r, x)
+    r.VsubV(b, r)
+    rnorm.Vnorm(r)
+  }
+  return x, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Solve A x = b with the restarted GMRES method and right preconditioning.
+// The argument a is either a ConstMatrix or an Operator that computes
+// matrix vector products. GMRES is restarted after a given number of
+// iterations (Restart option, 30 by default).
+func GMRES(a interface{}, b ConstVector, args ...interface{}) (Vector, error) {
+  f, t := getOperator(a, b)
+  opts := getOptions("GMRES", b.Dim(), args...)
+  return gmres(f, b, t, opts)
+}
@@ -0,0 +1,256 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package krylov
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// A linear operator that computes r = A x
+type Operator func(r Vector, x ConstVector)
+
+/* -------------------------------------------------------------------------- */
+
+// Stop if the norm of the residual is smaller than epsilon times the
+// norm of b (MINRES measures both norms with respect to the inverse of the
+// preconditioner)
+type Epsilon struct {
+  Value float64
+}
+
+type MaxIterations struct {
+  Value int
+}
+
+// Number of iterations after which GMRES is restarted
+type Restart struct {
+  Value int
+}
+
+// Initial guess for the solution (zero by default)
+type X0 struct {
+  Value ConstVector
+}
+
+// The preconditioner computes r = M^-1 x, where M is an approximation
+// of A. CG and MINRES require M to be symmetric positive definite.
+type Preconditioner struct {
+  Value Operator
+}
+
+// The hook is called at every iteration with the current solution x and
+// the norm of the residual. The algorithm stops if the hook returns true.
+type Hook struct {
+  Value func(x ConstVector, r ConstScalar) bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+type options struct {
+  epsilon        Epsilon
+  maxIterations  MaxIterations
+  restart        Restart
+  x0             X0
+  preconditioner Preconditioner
+  hook           Hook
+}
+
+func getOptions(name string, n int, args ...interface{}) options {
+  opts := options{}
+  opts.epsilon       = Epsilon      {1e-8}
+  opts.maxIterations = MaxIterations{10*n}
+  opts.restart       = Restart      {30}
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Epsilon:
+      opts.epsilon = a
+    case MaxIterations:
+      opts.maxIterations = a
+    case Restart:
+      opts.restart = a
+    case X0:
+      opts.x0 = a
+    case Preconditioner:
+      opts.preconditioner = a
+    case Hook:
+      opts.hook = a
+    default:
+      panic(fmt.Sprintf("%s(): Invalid optional argument!", name))
+    }
+  }
+  if opts.restart.Value > n {
+    opts.restart.Value = n
+  }
+  if opts.restart.Value < 1 {
+    opts.restart.Value = 1
+  }
+  return opts
+}
+
+// Convert the first argument of a solver to an operator and determine the
+// scalar type of the solution.
+func getOperator(a interface{}, b ConstVector) (Operator, ScalarType) {
+  t := b.ElementType()
+  switch a := a.(type) {
+  case ConstMatrix:
+    n, m := a.Dims()
+    if n != m || n != b.Dim() {
+      panic("matrix/vector dimensions do not match!")
+    }
+    if s := a.ElementType(); s == Real32Type || s == Real64Type {
+      t = s
+    }
+    return func(r Vector, x ConstVector) { r.MdotV(a, x) }, t
+  case Operator:
+    return a, t
+  case func(Vector, ConstVector):
+    return a, t
+  default:
+    panic("invalid linear operator")
+  }
+}
+
+// Compute the initial residual r = b - A x0. The initial solution is
+// stored in x.
+func initialize(f Operator, b ConstVector, x, r Vector, x0 X0) {
+  if x0.Value == nil {
+    x.Reset()
+    r.Set(b)
+  } else {
+    if x0.Value.Dim() != b.Dim() {
+      panic("initial value has invalid dimension")
+    }
+    x.Set(x0.Value)
+    f(r, x)
+    r.VsubV(b, r)
+  }
+}
+
+/* preconditioners
+ * -------------------------------------------------------------------------- */
+
+// Jacobi preconditioner M = diag(A)
+func Jacobi(a ConstMatrix) (Operator, error) {
+  n, m := a.Dims()
+  if n != m {
+    return nil, fmt.Errorf("Jacobi(): not a square matrix")
+  }
+  d := make([]float64, n)
+  for i := 0; i < n; i++ {
+    d[i] = a.ConstAt(i, i).GetFloat64()
+    if d[i] == 0.0 {
+      return nil, fmt.Errorf("Jacobi(): matrix has zero on the diagonal")
+    }
+    d[i] = 1.0/d[i]
+  }
+  f := func(r Vector, x ConstVector) {
+    for i := 0; i < n; i++ {
+      r.At(i).Mul(x.ConstAt(i), ConstFloat64(d[i]))
+    }
+  }
+  return f, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+type sparseRow struct {
+  index []int
+  value []float64
+}
+
+func (row sparseRow) at(j int) float64 {
+  for k, i := range row.index {
+    if i == j {
+      return row.value[k]
+    }
+  }
+  return 0.0
+}
+
+// Incomplete Cholesky preconditioner M = L L^T without fill-in, i.e. L has
+// the same sparsity pattern as the lower triangular part of A. The matrix
+// A must be symmetric positive definite.
+func IncompleteCholesky(a ConstMatrix) (Operator, error) {
+  n, m := a.Dims()
+  if n != m {
+    return nil, fmt.Errorf("IncompleteCholesky(): not a square matrix")
+  }
+  // sparsity pattern of the lower triangular part of a, the
+  // diagonal element is stored at the end of each row
+  L := make([]sparseRow, n)
+  for it := a.ConstIterator(); it.Ok(); it.Next() {
+    i, j := it.Index()
+    if j < i {
+      L[i].index = append(L[i].index, j)
+      L[i].value = append(L[i].value, it.GetConst().GetFloat64())
+    }
+  }
+  for i := 0; i < n; i++ {
+    L[i].index = append(L[i].index, i)
+    L[i].value = append(L[i].value, a.ConstAt(i, i).GetFloat64())
+  }
+  for i := 0; i < n; i++ {
+    row := L[i]
+    for k, j := range row.index {
+      // s = sum_{l < j} L_il L_jl
+      s := 0.0
+      for k2, l := range row.index[0:k] {
+        s += row.value[k2]*L[j].at(l)
+      }
+      if j < i {
+        row.value[k] = (row.value[k] - s)/L[j].value[len(L[j].value)-1]
+      } else {
+        if row.value[k] - s <= 0.0 {
+          return nil, fmt.Errorf("IncompleteCholesky(): matrix is not positive definite")
+        }
+        row.value[k] = math.Sqrt(row.value[k] - s)
+      }
+    }
+  }
+  f := func(r Vector, x ConstVector) {
+    t := NullScalar(r.ElementType())
+    // solve L y = x
+    for i := 0; i < n; i++ {
+      row := L[i]
+      k   := len(row.index)-1
+      s   := r.At(i)
+      s.Set(x.ConstAt(i))
+      for k2, j := range row.index[0:k] {
+        t.Mul(r.ConstAt(j), ConstFloat64(row.value[k2]))
+        s.Sub(s, t)
+      }
+      s.Div(s, ConstFloat64(row.value[k]))
+    }
+    // solve L^T r = y
+    for i := n-1; i >= 0; i-- {
+      row := L[i]
+      k   := len(row.index)-1
+      s   := r.At(i)
+      s.Div(s, ConstFloat64(row.value[k]))
+      for k2, j := range row.index[0:k] {
+        t.Mul(s, ConstFloat64(row.value[k2]))
+        r.At(j).Sub(r.At(j), t)
+      }
+    }
+  }
+  return f, nil
+}
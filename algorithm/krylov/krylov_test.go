@@ -0,0 +1,199 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package krylov
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// sparse matrix with a on the diagonal and b, c on the first
+// sub- and superdiagonal
+func tridiagonal(n int, a, b, c float64) Matrix {
+  r := NullSparseFloat64Matrix(n, n)
+  for i := 0; i < n; i++ {
+    r.At(i, i).SetFloat64(a)
+    if i > 0 {
+      r.At(i, i-1).SetFloat64(b)
+    }
+    if i < n-1 {
+      r.At(i, i+1).SetFloat64(c)
+    }
+  }
+  return r
+}
+
+func checkSolution(test *testing.T, a ConstMatrix, x, b ConstVector, err error) {
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  r := NullDenseFloat64Vector(b.Dim())
+  r.MdotV(a, x)
+  if !r.Equals(b, 1e-6) {
+    test.Error("test failed")
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func TestCG(test *testing.T) {
+  n := 50
+  a := tridiagonal(n, 2.5, -1, -1)
+  b := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    b[i] = float64(i % 7)
+  }
+  jacobi, err := Jacobi(a)
+  if err != nil {
+    test.Error(err)
+  }
+  ichol, err := IncompleteCholesky(a)
+  if err != nil {
+    test.Error(err)
+  }
+  {
+    x, err := CG(a, b)
+    checkSolution(test, a, x, b, err)
+  }
+  {
+    x, err := CG(a, b, Preconditioner{jacobi})
+    checkSolution(test, a, x, b, err)
+  }
+  {
+    // incomplete Cholesky is exact for tridiagonal matrices
+    i := 0
+    x, err := CG(a, b, Preconditioner{ichol}, Hook{func(x ConstVector, r ConstScalar) bool { i++; return false }})
+    checkSolution(test, a, x, b, err)
+    if i > 3 {
+      test.Errorf("test failed: CG required %d iterations", i)
+    }
+  }
+  {
+    f := func(r Vector, x ConstVector) {
+      r.MdotV(a, x)
+    }
+    x, err := CG(f, b, X0{NewDenseFloat64Vector(make([]float64, n))})
+    checkSolution(test, a, x, b, err)
+  }
+  if _, err := CG(a, b, MaxIterations{2}); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestMINRES(test *testing.T) {
+  n := 50
+  // symmetric indefinite matrix
+  a := tridiagonal(n, 0.5, 1, 1)
+  b := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    b[i] = float64(i % 5) - 2
+  }
+  {
+    x, err := MINRES(a, b, Epsilon{1e-10})
+    checkSolution(test, a, x, b, err)
+  }
+  // positive definite matrix with preconditioner
+  a = tridiagonal(n, 2.5, -1, -1)
+  ichol, _ := IncompleteCholesky(a)
+  {
+    x, err := MINRES(a, b, Preconditioner{ichol})
+    checkSolution(test, a, x, b, err)
+  }
+  {
+    // the tolerance is relative to the norm of b, so no iterations are
+    // required if the initial guess is already accurate enough
+    x, _ := MINRES(a, b, Epsilon{1e-12})
+    i := 0
+    _, err := MINRES(a, b, Epsilon{1e-8}, X0{x}, Hook{func(x ConstVector, r ConstScalar) bool { i++; return false }})
+    if err != nil {
+      test.Error(err)
+    }
+    if i != 1 {
+      test.Errorf("test failed: MINRES required %d iterations", i-1)
+    }
+  }
+}
+
+func TestGMRES(test *testing.T) {
+  n := 50
+  // non-symmetric matrix
+  a := tridiagonal(n, 3, -1, -1.5)
+  b := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    b[i] = float64(i % 3)
+  }
+  jacobi, _ := Jacobi(a)
+  {
+    x, err := GMRES(a, b, Epsilon{1e-10})
+    checkSolution(test, a, x, b, err)
+  }
+  {
+    x, err := GMRES(a, b, Epsilon{1e-10}, Restart{5}, Preconditioner{jacobi}, MaxIterations{1000})
+    checkSolution(test, a, x, b, err)
+  }
+  {
+    // the hook is called at every iteration with the residual norm of
+    // the current iterate
+    i := 0
+    r := NullDenseFloat64Vector(n)
+    hook := Hook{func(x ConstVector, rnorm ConstScalar) bool {
+      r.MdotV(a, x)
+      r.VsubV(b, r)
+      if math.Abs(NullFloat64().Vnorm(r).GetFloat64() - rnorm.GetFloat64()) > 1e-8 {
+        test.Errorf("test failed: invalid residual norm at iteration %d", i)
+      }
+      i++
+      return false
+    }}
+    x, err := GMRES(a, b, Epsilon{1e-10}, Restart{n}, Preconditioner{jacobi}, hook)
+    checkSolution(test, a, x, b, err)
+    if i <= 2 {
+      test.Errorf("test failed: hook was called only %d times", i)
+    }
+  }
+}
+
+func TestCGReal(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    4, 1,
+    1, 3 }, 2, 2)
+  b := NewDenseReal64Vector([]float64{1, 2})
+  b.Variables(1)
+
+  x, err := CG(a, b, Epsilon{1e-12})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  // dx/db = A^-1
+  ainv := NewDenseFloat64Matrix([]float64{
+     3, -1,
+    -1,  4 }, 2, 2)
+  for i := 0; i < 2; i++ {
+    for j := 0; j < 2; j++ {
+      if r := x.ConstAt(i).GetDerivative(j); math.Abs(r - ainv.At(i, j).GetFloat64()/11) > 1e-8 {
+        test.Errorf("test failed for derivative (%d,%d): %v", i, j, r)
+      }
+    }
+  }
+}
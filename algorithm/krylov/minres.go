@@ -0,0 +1,177 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package krylov
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+/* Preconditioned MINRES, see
+ * H. Elman, D. Silvester, A. Wathen: Finite Elements and Fast Iterative
+ * Solvers. Oxford University Press, 2005
+ */
+
+func minres(f Operator, b ConstVector, t ScalarType, opts options) (Vector, error) {
+  n := b.Dim()
+  x  := NullDenseVector(t, n)
+  v0 := NullDenseVector(t, n)
+  v1 := NullDenseVector(t, n)
+  v2 := NullDenseVector(t, n)
+  w0 := NullDenseVector(t, n)
+  w1 := NullDenseVector(t, n)
+  w2 := NullDenseVector(t, n)
+  z1 := NullDenseVector(t, n)
+  z2 := NullDenseVector(t, n)
+  az := NullDenseVector(t, n)
+  tv := NullDenseVector(t, n)
+
+  gamma0 := NewScalar(t, 1.0)
+  gamma1 := NullScalar(t)
+  gamma2 := NullScalar(t)
+  delta  := NullScalar(t)
+  eta    := NullScalar(t)
+  c0     := NewScalar(t, 1.0)
+  c1     := NewScalar(t, 1.0)
+  c2     := NullScalar(t)
+  s0     := NullScalar(t)
+  s1     := NullScalar(t)
+  s2     := NullScalar(t)
+  alpha0 := NullScalar(t)
+  alpha1 := NullScalar(t)
+  alpha2 := NullScalar(t)
+  alpha3 := NullScalar(t)
+  t1     := NullScalar(t)
+  t2     := NullScalar(t)
+  rnorm  := NullScalar(t)
+
+  precondition := func(r Vector, x ConstVector) {
+    if opts.preconditioner.Value != nil {
+      opts.preconditioner.Value(r, x)
+    } else {
+      r.Set(x)
+    }
+  }
+  // the norm of b with respect to the inner product induced by the
+  // preconditioner
+  precondition(z2, b)
+  t1.VdotV(z2, b)
+  if t1.GetFloat64() < 0.0 {
+    return x, fmt.Errorf("preconditioner is not positive definite")
+  }
+  t1.Sqrt(t1)
+  tol := opts.epsilon.Value*t1.GetFloat64()
+
+  // v1 = b - A x
+  initialize(f, b, x, v1, opts.x0)
+  precondition(z1, v1)
+  gamma1.VdotV(z1, v1)
+  if gamma1.GetFloat64() < 0.0 {
+    return x, fmt.Errorf("preconditioner is not positive definite")
+  }
+  gamma1.Sqrt(gamma1)
+  eta.Set(gamma1)
+
+  for i := 0; ; i++ {
+    // |eta| is the norm of the residual with respect to the
+    // inner product induced by the preconditioner
+    rnorm.Abs(eta)
+    if opts.hook.Value != nil && opts.hook.Value(x, rnorm) {
+      break
+    }
+    if rnorm.GetFloat64() <= tol || gamma1.GetFloat64() == 0.0 {
+      break
+    }
+    if i >= opts.maxIterations.Value {
+      return x, fmt.Errorf("maximum number of iterations reached")
+    }
+    // Lanczos step
+    z1.VdivS(z1, gamma1)
+    f(az, z1)
+    delta.VdotV(az, z1)
+    // v2 = A z1 - (delta/gamma1) v1 - (gamma1/gamma0) v0
+    t1.Div(delta, gamma1)
+    tv.VmulS(v1, t1)
+    v2.VsubV(az, tv)
+    t1.Div(gamma1, gamma0)
+    tv.VmulS(v0, t1)
+    v2.VsubV(v2, tv)
+    precondition(z2, v2)
+    gamma2.VdotV(z2, v2)
+    if gamma2.GetFloat64() < 0.0 {
+      return x, fmt.Errorf("preconditioner is not positive definite")
+    }
+    gamma2.Sqrt(gamma2)
+    // QR step
+    t1.Mul(c1, delta)
+    t2.Mul(c0, s1)
+    t2.Mul(t2, gamma1)
+    alpha0.Sub(t1, t2)
+    t1.Mul(alpha0, alpha0)
+    t2.Mul(gamma2, gamma2)
+    alpha1.Add(t1, t2)
+    alpha1.Sqrt(alpha1)
+    if alpha1.GetFloat64() == 0.0 || math.IsNaN(alpha1.GetFloat64()) {
+      return x, fmt.Errorf("MINRES breakdown")
+    }
+    t1.Mul(s1, delta)
+    t2.Mul(c0, c1)
+    t2.Mul(t2, gamma1)
+    alpha2.Add(t1, t2)
+    alpha3.Mul(s0, gamma1)
+    c2.Div(alpha0, alpha1)
+    s2.Div(gamma2, alpha1)
+    // w2 = (z1 - alpha3 w0 - alpha2 w1)/alpha1
+    tv.VmulS(w0, alpha3)
+    w2.VsubV(z1, tv)
+    tv.VmulS(w1, alpha2)
+    w2.VsubV(w2, tv)
+    w2.VdivS(w2, alpha1)
+    // x = x + c2 eta w2
+    t1.Mul(c2, eta)
+    tv.VmulS(w2, t1)
+    x.VaddV(x, tv)
+    eta.Mul(s2, eta)
+    eta.Neg(eta)
+    // shift variables
+    v0, v1, v2 = v1, v2, v0
+    w0, w1, w2 = w1, w2, w0
+    z1, z2 = z2, z1
+    gamma0.Set(gamma1)
+    gamma1.Set(gamma2)
+    c0.Set(c1); c1.Set(c2)
+    s0.Set(s1); s1.Set(s2)
+  }
+  return x, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Solve A x = b with the (preconditioned) MINRES method, where A must be
+// symmetric but may be indefinite. The argument a is either a ConstMatrix
+// or an Operator that computes matrix vector products. The convergence
+// criterion is evaluated for the norms of the residual and b with respect
+// to the inverse of the preconditioner.
+func MINRES(a interface{}, b ConstVector, args ...interface{}) (Vector, error) {
+  f, t := getOperator(a, b)
+  opts := getOptions("MINRES", b.Dim(), args...)
+  return minres(f, b, t, opts)
+}
@@ -0,0 +1,404 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lanczos
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "math/rand"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/krylov"
+import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
+
+/* -------------------------------------------------------------------------- */
+
+// Select the eigenvalues that are computed: largest algebraic ("LA",
+// default), smallest algebraic ("SA"), largest magnitude ("LM") or
+// smallest magnitude ("SM")
+type Which struct {
+  Value string
+}
+
+// A Ritz pair (theta, x) is accepted if ||A x - theta x|| is smaller
+// than epsilon times |theta|
+type Epsilon struct {
+  Value float64
+}
+
+// Maximum number of restarts
+type MaxIterations struct {
+  Value int
+}
+
+// Dimension of the Krylov subspace, which must be larger than the number
+// of requested eigenvalues (default max(2k+1, 20))
+type SubspaceDimension struct {
+  Value int
+}
+
+// Initial vector of the Krylov subspace (random by default)
+type V0 struct {
+  Value ConstVector
+}
+
+// Dimension of the linear operator, required if A is given as an
+// operator
+type Dimension struct {
+  Value int
+}
+
+/* -------------------------------------------------------------------------- */
+
+type Info struct {
+  // number of converged eigenpairs
+  Converged  int
+  // number of restarts
+  Iterations int
+  // number of matrix vector products
+  MatVecs    int
+  // residual norms ||A x - lambda x|| of the returned eigenpairs
+  Residuals  []float64
+}
+
+/* -------------------------------------------------------------------------- */
+
+type options struct {
+  which             Which
+  epsilon           Epsilon
+  maxIterations     MaxIterations
+  subspaceDimension SubspaceDimension
+  v0                V0
+  dimension         Dimension
+}
+
+func getOperator(a interface{}, args ...interface{}) (krylov.Operator, int) {
+  n := -1
+  for _, arg := range args {
+    if tmp, ok := arg.(Dimension); ok {
+      n = tmp.Value
+    }
+  }
+  switch a := a.(type) {
+  case ConstMatrix:
+    n1, n2 := a.Dims()
+    if n1 != n2 {
+      panic("Lanczos(): Not a square matrix!")
+    }
+    return func(r Vector, x ConstVector) { r.MdotV(a, x) }, n1
+  case krylov.Operator:
+    if n < 0 {
+      panic("Lanczos(): Operator requires Dimension argument!")
+    }
+    return a, n
+  case func(Vector, ConstVector):
+    if n < 0 {
+      panic("Lanczos(): Operator requires Dimension argument!")
+    }
+    return a, n
+  default:
+    panic("invalid linear operator")
+  }
+}
+
+func getOptions(n, k int, args ...interface{}) options {
+  opts := options{}
+  opts.which             = Which            {"LA"}
+  opts.epsilon           = Epsilon          {1e-10}
+  opts.maxIterations     = MaxIterations    {300}
+  opts.subspaceDimension = SubspaceDimension{2*k+1}
+  if opts.subspaceDimension.Value < 20 {
+    opts.subspaceDimension.Value = 20
+  }
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Which:
+      opts.which = a
+    case Epsilon:
+      opts.epsilon = a
+    case MaxIterations:
+      opts.maxIterations = a
+    case SubspaceDimension:
+      opts.subspaceDimension = a
+    case V0:
+      opts.v0 = a
+    case Dimension:
+      opts.dimension = a
+    default:
+      panic("Lanczos(): Invalid optional argument!")
+    }
+  }
+  if opts.subspaceDimension.Value > n {
+    opts.subspaceDimension.Value = n
+  }
+  return opts
+}
+
+/* -------------------------------------------------------------------------- */
+
+func dot(a, b DenseFloat64Vector) float64 {
+  r := 0.0
+  for i := range a {
+    r += a[i]*b[i]
+  }
+  return r
+}
+
+func norm(a DenseFloat64Vector) float64 {
+  return math.Sqrt(dot(a, a))
+}
+
+// a = a + c b
+func axpy(a DenseFloat64Vector, c float64, b DenseFloat64Vector) {
+  for i := range a {
+    a[i] += c*b[i]
+  }
+}
+
+func scale(a DenseFloat64Vector, c float64) {
+  for i := range a {
+    a[i] *= c
+  }
+}
+
+// Initialize x with a random unit vector orthogonal to all vectors in V.
+// If V spans the full space, x is set to zero.
+func randomOrthogonal(x DenseFloat64Vector, V []DenseFloat64Vector, rng *rand.Rand) {
+  for i := range x {
+    x[i] = rng.NormFloat64()
+  }
+  for k := 0; k < 2; k++ {
+    for _, v := range V {
+      axpy(x, -dot(v, x), v)
+    }
+  }
+  if r := norm(x); r > 1e-8*math.Sqrt(float64(len(x))) {
+    scale(x, 1.0/r)
+  } else {
+    x.Reset()
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Extend the Lanczos basis from l to m vectors. Vectors are fully
+// reorthogonalized, so that H = V^T A V also holds after restarts.
+func expand(f krylov.Operator, V []DenseFloat64Vector, H [][]float64, w DenseFloat64Vector, l int, rng *rand.Rand, info *Info) {
+  m := len(V)-1
+  for j := l; j < m; j++ {
+    f(w, V[j])
+    info.MatVecs++
+    r := norm(w)
+    // classical Gram-Schmidt with reorthogonalization
+    for k := 0; k < 2; k++ {
+      for i := 0; i <= j; i++ {
+        h := dot(V[i], w)
+        H[i][j] += h
+        axpy(w, -h, V[i])
+      }
+    }
+    if beta := norm(w); beta > 1e-12*r {
+      H[j+1][j] = beta
+      V[j+1].Set(w)
+      scale(V[j+1], 1.0/beta)
+    } else {
+      // invariant subspace found, continue with a new
+      // random vector
+      H[j+1][j] = 0.0
+      randomOrthogonal(V[j+1], V[0:j+1], rng)
+    }
+  }
+}
+
+// Compute eigenvalues and eigenvectors of the projected matrix, sorted
+// such that the wanted eigenvalues come first.
+func ritzPairs(H [][]float64, m int, which string) ([]float64, *DenseFloat64Matrix, error) {
+  T := NullDenseFloat64Matrix(m, m)
+  for i := 0; i < m; i++ {
+    for j := 0; j < m; j++ {
+      T.At(i, j).SetFloat64((H[i][j] + H[j][i])/2.0)
+    }
+  }
+  h, u, err := qrAlgorithm.Run(T,
+    qrAlgorithm.Symmetric{Value: true},
+    qrAlgorithm.ComputeU {Value: true},
+    qrAlgorithm.Epsilon  {Value: 1e-15})
+  if err != nil {
+    return nil, nil, err
+  }
+  theta := make([]float64, m)
+  p     := make([]int, m)
+  for i := 0; i < m; i++ {
+    theta[i] = h.ConstAt(i, i).GetFloat64()
+    p    [i] = i
+  }
+  var less func(a, b float64) bool
+  switch which {
+  case "LA": less = func(a, b float64) bool { return a > b }
+  case "SA": less = func(a, b float64) bool { return a < b }
+  case "LM": less = func(a, b float64) bool { return math.Abs(a) > math.Abs(b) }
+  case "SM": less = func(a, b float64) bool { return math.Abs(a) < math.Abs(b) }
+  default:
+    return nil, nil, fmt.Errorf("invalid eigenvalue selection `%s'", which)
+  }
+  sort.SliceStable(p, func(i, j int) bool { return less(theta[p[i]], theta[p[j]]) })
+  // permute eigenvalues and eigenvectors
+  r := make([]float64, m)
+  Y := NullDenseFloat64Matrix(m, m)
+  for j := 0; j < m; j++ {
+    r[j] = theta[p[j]]
+    for i := 0; i < m; i++ {
+      Y.At(i, j).Set(u.ConstAt(i, p[j]))
+    }
+  }
+  return r, Y, nil
+}
+
+// Compute x = V y, where y is the jth column of Y
+func ritzVector(x DenseFloat64Vector, V []DenseFloat64Vector, Y *DenseFloat64Matrix, j int) {
+  x.Reset()
+  for i, v := range V {
+    axpy(x, Y.ConstAt(i, j).GetFloat64(), v)
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func lanczos(f krylov.Operator, n, k int, opts options) (Vector, Matrix, Info, error) {
+  m    := opts.subspaceDimension.Value
+  info := Info{}
+  rng  := rand.New(rand.NewSource(1))
+
+  V := make([]DenseFloat64Vector, m+1)
+  for j := range V {
+    V[j] = NullDenseFloat64Vector(n)
+  }
+  H := make([][]float64, m+1)
+  for i := range H {
+    H[i] = make([]float64, m)
+  }
+  w := NullDenseFloat64Vector(n)
+  // initial vector
+  if opts.v0.Value != nil {
+    if opts.v0.Value.Dim() != n {
+      return nil, nil, info, fmt.Errorf("initial vector has invalid dimension")
+    }
+    V[0].Set(opts.v0.Value)
+    if r := norm(V[0]); r == 0.0 {
+      return nil, nil, info, fmt.Errorf("initial vector is zero")
+    } else {
+      scale(V[0], 1.0/r)
+    }
+  } else {
+    randomOrthogonal(V[0], nil, rng)
+  }
+  // number of Ritz vectors kept at a restart
+  l := 0
+  for {
+    expand(f, V, H, w, l, rng, &info)
+
+    theta, Y, err := ritzPairs(H, m, opts.which.Value)
+    if err != nil {
+      return nil, nil, info, err
+    }
+    beta := H[m][m-1]
+    // check convergence of the wanted Ritz pairs using the residual
+    // estimate ||A x - theta x|| = |beta y_m|
+    info.Converged = 0
+    for i := 0; i < k; i++ {
+      if math.Abs(beta*Y.ConstAt(m-1, i).GetFloat64()) <= opts.epsilon.Value*math.Max(math.Abs(theta[i]), 1e-10) {
+        info.Converged++
+      }
+    }
+    if info.Converged == k || info.Iterations >= opts.maxIterations.Value {
+      lambda := NullDenseFloat64Vector(k)
+      x      := NullDenseFloat64Matrix(n, k)
+      info.Residuals = make([]float64, k)
+      for j := 0; j < k; j++ {
+        xj := ColView(x, j)
+        ritzVector(w, V[0:m], Y, j)
+        xj.Set(w)
+        lambda[j] = theta[j]
+        // compute residual
+        f(w, xj)
+        info.MatVecs++
+        for i := 0; i < n; i++ {
+          w[i] -= theta[j]*xj.ConstAt(i).GetFloat64()
+        }
+        info.Residuals[j] = norm(w)
+      }
+      if info.Converged < k {
+        return lambda, x, info, fmt.Errorf("maximum number of iterations reached")
+      }
+      return lambda, x, info, nil
+    }
+    info.Iterations++
+    // thick restart: keep the l wanted Ritz vectors, the residual vector
+    // v_m becomes the next basis vector
+    l = k + (m-k)/2
+    if l > m-1 {
+      l = m-1
+    }
+    U := make([]DenseFloat64Vector, l)
+    for j := 0; j < l; j++ {
+      U[j] = NullDenseFloat64Vector(n)
+      ritzVector(U[j], V[0:m], Y, j)
+    }
+    V[l].Set(V[m])
+    for j := 0; j < l; j++ {
+      V[j].Set(U[j])
+    }
+    for i := range H {
+      for j := range H[i] {
+        H[i][j] = 0.0
+      }
+    }
+    // projected matrix has arrowhead form
+    for j := 0; j < l; j++ {
+      H[j][j] = theta[j]
+      H[l][j] = beta*Y.ConstAt(m-1, j).GetFloat64()
+    }
+    if beta == 0.0 {
+      randomOrthogonal(V[l], V[0:l], rng)
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute k eigenvalues and eigenvectors of a large symmetric matrix A
+// with the thick-restart Lanczos method, which is mathematically
+// equivalent to the implicitly restarted Lanczos method. The argument
+// a is either a ConstMatrix (e.g. a SparseFloat64Matrix) or an operator
+// that computes matrix vector products, in which case the Dimension option
+// is required. The eigenvalues are selected with the Which option and
+// sorted accordingly, the eigenvectors are returned as columns of a
+// matrix. An error is returned if not all eigenpairs converged, in which
+// case the current approximations are returned as well.
+func Run(a interface{}, k int, args ...interface{}) (Vector, Matrix, Info, error) {
+  f, n := getOperator(a, args...)
+  if k < 1 || k > n {
+    return nil, nil, Info{}, fmt.Errorf("invalid number of eigenvalues")
+  }
+  opts := getOptions(n, k, args...)
+  if m := opts.subspaceDimension.Value; m < k || (m == k && m < n) {
+    return nil, nil, Info{}, fmt.Errorf("subspace dimension must be larger than the number of eigenvalues")
+  }
+  return lanczos(f, n, k, opts)
+}
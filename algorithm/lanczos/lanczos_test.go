@@ -0,0 +1,120 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lanczos
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// one-dimensional Laplacian with eigenvalues 2 - 2 cos(j pi/(n+1))
+func laplacian(n int) Matrix {
+  r := NullSparseFloat64Matrix(n, n)
+  for i := 0; i < n; i++ {
+    r.At(i, i).SetFloat64(2)
+    if i > 0 {
+      r.At(i, i-1).SetFloat64(-1)
+    }
+    if i < n-1 {
+      r.At(i, i+1).SetFloat64(-1)
+    }
+  }
+  return r
+}
+
+func laplacianEigenvalue(n, j int) float64 {
+  return 2.0 - 2.0*math.Cos(float64(j)*math.Pi/float64(n+1))
+}
+
+func checkEigenpairs(test *testing.T, a ConstMatrix, lambda Vector, x Matrix, expected []float64) {
+  n, _ := a.Dims()
+  r := NullDenseFloat64Vector(n)
+  for j, e := range expected {
+    if math.Abs(lambda.ConstAt(j).GetFloat64() - e) > 1e-8 {
+      test.Errorf("test failed for eigenvalue `%d'", j)
+    }
+    xj := x.Col(j)
+    r.MdotV(a, xj)
+    r.VsubV(r, xj.VmulS(xj, lambda.ConstAt(j)))
+    if norm(r) > 1e-6 {
+      test.Errorf("test failed for eigenvector `%d'", j)
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func TestLanczos1(test *testing.T) {
+  n := 100
+  a := laplacian(n)
+
+  lambda, x, info, err := Run(a, 3)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkEigenpairs(test, a, lambda, x, []float64{
+    laplacianEigenvalue(n, n), laplacianEigenvalue(n, n-1), laplacianEigenvalue(n, n-2) })
+  if info.Converged != 3 {
+    test.Error("test failed")
+  }
+  for _, r := range info.Residuals {
+    if r > 1e-6 {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestLanczos2(test *testing.T) {
+  n := 100
+  a := laplacian(n)
+  f := func(r Vector, x ConstVector) {
+    r.MdotV(a, x)
+  }
+  lambda, x, _, err := Run(f, 2, Dimension{n}, Which{"SA"}, SubspaceDimension{30})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkEigenpairs(test, a, lambda, x, []float64{
+    laplacianEigenvalue(n, 1), laplacianEigenvalue(n, 2) })
+}
+
+func TestLanczos3(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+     4, 1, 0, 0,
+     1, 3, 1, 0,
+     0, 1, -5, 1,
+     0, 0, 1, 1 }, 4, 4)
+  // subspace spans the full space
+  lambda, x, _, err := Run(a, 1, Which{"LM"})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  r := NullDenseFloat64Vector(4)
+  r.MdotV(a, x.Col(0))
+  r.VsubV(r, x.Col(0).VmulS(x.Col(0), lambda.ConstAt(0)))
+  if lambda.ConstAt(0).GetFloat64() > -5 || norm(r) > 1e-8 {
+    test.Error("test failed")
+  }
+}
@@ -0,0 +1,406 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Reference:
+// Nocedal, Jorge, and Stephen Wright. Numerical optimization.
+// Springer Science & Business Media, 2006.
+
+/* -------------------------------------------------------------------------- */
+
+package lbfgs
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/lineSearch"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+type Objective func(ConstVector) (MagicScalar, error)
+
+// Number of correction pairs used to approximate the inverse Hessian
+type History struct {
+  Value int
+}
+
+type Epsilon struct {
+  Value float64
+}
+
+type MaxIterations struct {
+  Value int
+}
+
+type Hook struct {
+  Value func(x, gradient ConstVector, y ConstScalar) bool
+}
+
+type Constraints struct {
+  Value func(x Vector) bool
+}
+
+// Returned if the line search fails to find a new position. The position
+// of the last iteration is returned together with this error.
+var ErrLineSearch = fmt.Errorf("line search failed")
+
+/* -------------------------------------------------------------------------- */
+
+// evaluate objective function and copy value and gradient
+func differentiate(f Objective, x ConstVector, X MagicVector, g Vector, y Scalar) error {
+  X.Set(x)
+  X.Variables(1)
+  z, err := f(X)
+  if err != nil {
+    return err
+  }
+  // copy value
+  y.Set(z)
+  // copy gradient
+  g.Reset()
+  for i := 0; i < z.GetN(); i++ {
+    g.At(i).SetFloat64(z.GetDerivative(i))
+  }
+  return nil
+}
+
+func equals(x1, x2 Vector) bool {
+  for i := 0; i < x1.Dim(); i++ {
+    if x1.At(i).GetFloat64() != x2.At(i).GetFloat64() {
+      return false
+    }
+  }
+  return true
+}
+
+/* -------------------------------------------------------------------------- */
+
+/* Limited-memory Broyden–Fletcher–Goldfarb–Shanno (L-BFGS) algorithm:
+ */
+
+// history of correction pairs s = x2 - x1 and y = g2 - g1
+type history struct {
+  s   []Vector
+  y   []Vector
+  rho []Scalar
+  // number of stored pairs
+  k   int
+}
+
+func newHistory(t ScalarType, n, m int) history {
+  h := history{}
+  h.s   = make([]Vector, m)
+  h.y   = make([]Vector, m)
+  h.rho = make([]Scalar, m)
+  for i := 0; i < m; i++ {
+    h.s  [i] = NullDenseVector(t, n)
+    h.y  [i] = NullDenseVector(t, n)
+    h.rho[i] = NullScalar(t)
+  }
+  return h
+}
+
+// add a new correction pair, the oldest pair is dropped if the history is
+// full
+func (h *history) push(x1, x2, g1, g2 Vector, t Scalar) bool {
+  m := len(h.s)
+  // recycle memory of the oldest pair
+  s, y, rho := h.s[m-1], h.y[m-1], h.rho[m-1]
+  s.VsubV(x2, x1)
+  y.VsubV(g2, g1)
+  t.VdotV(s, y)
+  // skip update if curvature condition is violated
+  if t.GetFloat64() <= 0.0 {
+    return false
+  }
+  rho.Div(ConstFloat64(1.0), t)
+  copy(h.s  [1:], h.s  [0:m-1])
+  copy(h.y  [1:], h.y  [0:m-1])
+  copy(h.rho[1:], h.rho[0:m-1])
+  h.s[0], h.y[0], h.rho[0] = s, y, rho
+  if h.k < m {
+    h.k++
+  }
+  return true
+}
+
+func (h *history) reset() {
+  h.k = 0
+}
+
+// two-loop recursion for computing the search direction p = -H g, where H
+// is the approximation of the inverse Hessian (pairs are stored from newest
+// to oldest)
+func lbfgs_computeDirection(g Vector, h history, p Vector, alpha []Scalar, t1, t2 Scalar) {
+  q := p
+  q.Set(g)
+  for i := 0; i < h.k; i++ {
+    // alpha_i = rho_i s_i^T q
+    alpha[i].VdotV(h.s[i], q)
+    alpha[i].Mul(alpha[i], h.rho[i])
+    // q = q - alpha_i y_i
+    for j := 0; j < q.Dim(); j++ {
+      t1.Mul(alpha[i], h.y[i].At(j))
+      q.At(j).Sub(q.At(j), t1)
+    }
+  }
+  if h.k > 0 {
+    // initial approximation H0 = s^T y / (y^T y) I
+    t1.VdotV(h.y[0], h.y[0])
+    t1.Mul(t1, h.rho[0])
+    t1.Div(ConstFloat64(1.0), t1)
+    q.VmulS(q, t1)
+  }
+  r := q
+  for i := h.k-1; i >= 0; i-- {
+    // beta = rho_i y_i^T r
+    t2.VdotV(h.y[i], r)
+    t2.Mul(t2, h.rho[i])
+    // r = r + s_i (alpha_i - beta)
+    t2.Sub(alpha[i], t2)
+    for j := 0; j < r.Dim(); j++ {
+      t1.Mul(h.s[i].At(j), t2)
+      r.At(j).Add(r.At(j), t1)
+    }
+  }
+  for i := 0; i < p.Dim(); i++ {
+    p.At(i).Neg(p.At(i))
+  }
+}
+
+func lbfgs(f Objective, x0 Vector, history_ History, epsilon Epsilon, maxIterations MaxIterations, hook Hook, constraints Constraints) (Vector, error) {
+
+  n := x0.Dim()
+  t := Float64Type
+
+  p  := NullDenseVector(t, n)
+  x1 := x0.CloneVector()
+  x2 := x1.CloneVector()
+  y1 := NullScalar(t)
+  y2 := NullScalar(t)
+  g1 := NullDenseVector(t, n)
+  g2 := NullDenseVector(t, n)
+  h  := newHistory(t, n, history_.Value)
+  // some temporary variables
+  t1 := NullScalar(t)
+  t2 := NullScalar(t)
+  a  := make([]Scalar, history_.Value)
+  for i := 0; i < len(a); i++ {
+    a[i] = NullScalar(t)
+  }
+
+  // here comes the magic!
+  X1 := NullDenseReal64Vector(n)
+  P2 := NullDenseReal64Vector(n)
+  X2 := AsDenseReal64Vector(x1)
+
+  // line search objective
+  phi := func(alpha ConstScalar) (MagicScalar, error) {
+    P2.VmulS(p, alpha)
+    X2.VaddV(x1, P2)
+    return f(X2)
+  }
+  var args []interface{}
+  args = append(args, lineSearch.Parameters{Alpha1: 1, MaxEval: 100})
+  if constraints.Value != nil {
+    // restrict step size such that constraints are satisfied
+    args = append(args, lineSearch.Constraints{Value: func(alpha ConstScalar) bool {
+      x2.VmulS(p, alpha)
+      x2.VaddV(x1, x2)
+      return constraints.Value(x2)
+    }})
+  }
+  // check initial value
+  if constraints.Value != nil && !constraints.Value(x1) {
+    return x1, fmt.Errorf("invalid initial value: %v", x1)
+  }
+  // evaluate objective function
+  if err := differentiate(f, x1, X1, g1, y1); err != nil {
+    return x1, fmt.Errorf("invalid initial value: %s", err)
+  }
+  // evaluate stop criterion
+  if t1.Vnorm(g1).GetFloat64() < epsilon.Value {
+    return x1, nil
+  }
+  // execute hook if available
+  if hook.Value != nil && hook.Value(x1, g1, y1) {
+    return x1, nil
+  }
+  for i := 0; i < maxIterations.Value; i++ {
+    lbfgs_computeDirection(g1, h, p, a, t1, t2)
+    // perform line search to find a new point x2
+    alpha, err := lineSearch.Run(phi, Float64Type, args...)
+    // compute new position
+    x2.VmulS(p, alpha)
+    x2.VaddV(x1, x2)
+
+    if err != nil || equals(x1, x2) {
+      if h.k == 0 {
+        // steepest descent failed, stop optimization here
+        return x1, ErrLineSearch
+      }
+      // drop history to find a new direction
+      h.reset()
+      continue
+    }
+    // evaluate objective at new position
+    if err := differentiate(f, x2, X1, g2, y2); err != nil {
+      return x1, fmt.Errorf("invalid value: %s", err)
+    }
+    // execute hook if available
+    if hook.Value != nil && hook.Value(x2, g2, y2) {
+      return x2, nil
+    }
+    // evaluate stop criterion
+    if t1.Vnorm(g2).GetFloat64() < epsilon.Value {
+      return x2, nil
+    }
+    h.push(x1, x2, g1, g2, t1)
+
+    g1.Set(g2)
+    x1.Set(x2)
+    y1.Set(y2)
+  }
+  return x1, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f with the limited-memory BFGS algorithm starting at x0. Only the
+// last m correction pairs (History option, default 10) are stored, which
+// requires O(mn) memory instead of O(n^2) for the full BFGS algorithm. The
+// step size is selected with a line search that satisfies the strong Wolfe
+// conditions. Optional arguments:
+//
+//   History      : number of correction pairs m
+//   Epsilon      : stop if the norm of the gradient is smaller than epsilon
+//   MaxIterations: maximum number of iterations
+//   Hook         : called after every iteration, stop if it returns true
+//   Constraints  : restricts the domain of f, the step size is reduced
+//                  until the constraints are satisfied
+//   Bounds       : lower and upper bounds for each coordinate, which are
+//                  handled with a projected L-BFGS method
+func Run(f Objective, x0 Vector, args ...interface{}) (Vector, error) {
+
+  history       := History      {  10}
+  hook          := Hook         { nil}
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  constraints   := Constraints  { nil}
+  bounds        := Bounds       {}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case History:
+      history = a
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Constraints:
+      constraints = a
+    case Bounds:
+      bounds = a
+    default:
+      panic("Lbfgs(): Invalid optional argument!")
+    }
+  }
+  if history.Value < 1 {
+    return nil, fmt.Errorf("invalid history size: %d", history.Value)
+  }
+  if bounds.IsSet() {
+    return lbfgsb(f, x0, history, bounds, epsilon, maxIterations, hook, constraints)
+  }
+  return lbfgs(f, x0, history, epsilon, maxIterations, hook, constraints)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. Accepts the same optional arguments as Run and
+// additionally optimize.Trace. If bounds are given, the gradient norm is
+// the norm of the projected gradient.
+func Minimize(f Objective, x0 Vector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook    := Hook   { nil}
+  epsilon := Epsilon{1e-8}
+  bounds  := Bounds {}
+  options := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    case Bounds:
+      bounds = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  norm := func(x, gradient ConstVector) float64 {
+    return projectedGradientNorm(bounds, AsDenseFloat64Vector(x), AsDenseFloat64Vector(gradient))
+  }
+  // the hook is called at the initial value and after every iteration
+  first := true
+  options = append(options, Hook{func(x, gradient ConstVector, y ConstScalar) bool {
+    if first {
+      r.Init(x, y.GetFloat64(), norm(x, gradient)); first = false
+    } else {
+      r.Iteration(x, y.GetFloat64(), norm(x, gradient))
+    }
+    return hook.Value != nil && r.Hook(hook.Value(x, gradient, y))
+  }})
+  x, err := Run(g, x0, options...)
+  if x == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  // evaluate objective function at the solution
+  y  := NullFloat64()
+  gx := NullDenseFloat64Vector(x.Dim())
+  if e := differentiate(f, x, NullDenseReal64Vector(x.Dim()), gx, y); e != nil && err == nil {
+    err = e
+  }
+  // Run stops without calling the hook if x0 already satisfies the stop
+  // criterion, in which case the initial state is recorded here
+  if first {
+    r.Init(x, y.GetFloat64(), norm(x, gx))
+  }
+  switch {
+  case err == ErrLineSearch:
+    return r.Result(x, y.GetFloat64(), norm(x, gx), optimize.Stalled), nil
+  case err != nil:
+    return r.Result(x, y.GetFloat64(), norm(x, gx), optimize.Failed), err
+  case norm(x, gx) < epsilon.Value:
+    return r.Result(x, y.GetFloat64(), norm(x, gx), optimize.Converged), nil
+  default:
+    return r.Result(x, y.GetFloat64(), norm(x, gx), optimize.MaxIterationsReached), nil
+  }
+}
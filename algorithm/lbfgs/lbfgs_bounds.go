@@ -0,0 +1,206 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Reference:
+// Bertsekas, Dimitri P. Projected Newton methods for optimization problems
+// with simple constraints. SIAM Journal on control and Optimization 20.2
+// (1982): 221-246.
+// Kelley, Carl T. Iterative methods for optimization. SIAM, 1999.
+
+/* -------------------------------------------------------------------------- */
+
+package lbfgs
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+// Lower and upper bounds for each coordinate, see optimize.Bounds
+type Bounds = optimize.Bounds
+
+/* -------------------------------------------------------------------------- */
+
+// compute the set of active coordinates, i.e. coordinates at a bound where
+// the negative gradient points outside the feasible box
+func active(b Bounds, x, g Vector, r []bool) {
+  for i := 0; i < x.Dim(); i++ {
+    xi := x.At(i).GetFloat64()
+    gi := g.At(i).GetFloat64()
+    r[i] = (xi <= b.LowerBound(i) && gi > 0.0) || (xi >= b.UpperBound(i) && gi < 0.0)
+  }
+}
+
+// norm of the projected gradient P(x - g) - x, which is zero at stationary
+// points of the bound constrained problem
+func projectedGradientNorm(b Bounds, x, g Vector) float64 {
+  r := 0.0
+  for i := 0; i < x.Dim(); i++ {
+    xi := x.At(i).GetFloat64()
+    di := math.Min(math.Max(xi - g.At(i).GetFloat64(), b.LowerBound(i)), b.UpperBound(i)) - xi
+    r += di*di
+  }
+  return math.Sqrt(r)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Projected L-BFGS algorithm for bound constrained problems. The quasi-Newton
+// step is restricted to the free coordinates, while the active coordinates
+// are fixed at their bounds. The step size is selected with a backtracking
+// line search along the projected path P(x + alpha p).
+func lbfgsb(f Objective, x0 Vector, history_ History, bounds Bounds, epsilon Epsilon, maxIterations MaxIterations, hook Hook, constraints Constraints) (Vector, error) {
+
+  n := x0.Dim()
+  t := Float64Type
+
+  // constant for the Armijo condition
+  c1 := 1e-4
+
+  p  := NullDenseVector(t, n)
+  x1 := x0.CloneVector()
+  x2 := x1.CloneVector()
+  y1 := NullScalar(t)
+  y2 := NullScalar(t)
+  g1 := NullDenseVector(t, n)
+  g2 := NullDenseVector(t, n)
+  gf := NullDenseVector(t, n)
+  h  := newHistory(t, n, history_.Value)
+  // active set
+  b  := make([]bool, n)
+  // some temporary variables
+  t1 := NullScalar(t)
+  t2 := NullScalar(t)
+  a  := make([]Scalar, history_.Value)
+  for i := 0; i < len(a); i++ {
+    a[i] = NullScalar(t)
+  }
+  X1 := NullDenseReal64Vector(n)
+
+  // evaluate objective function without derivatives, returns false if x is
+  // outside the domain of f
+  eval := func(x Vector) (float64, bool) {
+    if constraints.Value != nil && !constraints.Value(x) {
+      return 0.0, false
+    }
+    y, err := f(x)
+    if err != nil || math.IsNaN(y.GetFloat64()) {
+      return 0.0, false
+    }
+    return y.GetFloat64(), true
+  }
+  // projected backtracking line search
+  search := func() bool {
+    alpha := 1.0
+    if h.k == 0 {
+      // no curvature information available, limit the length of the
+      // first step
+      alpha = math.Min(1.0, 1.0/t1.Vnorm(p).GetFloat64())
+    }
+    for i := 0; i < 100; i++ {
+      x2.VmulS(p, ConstFloat64(alpha))
+      x2.VaddV(x1, x2)
+      bounds.Project(x2)
+      if y, ok := eval(x2); ok {
+        // Armijo condition along the projected path
+        d := 0.0
+        for j := 0; j < n; j++ {
+          d += g1.At(j).GetFloat64()*(x2.At(j).GetFloat64() - x1.At(j).GetFloat64())
+        }
+        if y <= y1.GetFloat64() + c1*d {
+          return true
+        }
+      }
+      alpha *= 0.5
+    }
+    return false
+  }
+  if err := bounds.Check(n); err != nil {
+    return x1, err
+  }
+  bounds.Project(x1)
+  // check initial value
+  if constraints.Value != nil && !constraints.Value(x1) {
+    return x1, fmt.Errorf("invalid initial value: %v", x1)
+  }
+  // evaluate objective function
+  if err := differentiate(f, x1, X1, g1, y1); err != nil {
+    return x1, fmt.Errorf("invalid initial value: %s", err)
+  }
+  // evaluate stop criterion
+  if projectedGradientNorm(bounds, x1, g1) < epsilon.Value {
+    return x1, nil
+  }
+  // execute hook if available
+  if hook.Value != nil && hook.Value(x1, g1, y1) {
+    return x1, nil
+  }
+  for i := 0; i < maxIterations.Value; i++ {
+    // reduced gradient on free coordinates
+    active(bounds, x1, g1, b)
+    gf.Set(g1)
+    for j := 0; j < n; j++ {
+      if b[j] {
+        gf.At(j).SetFloat64(0.0)
+      }
+    }
+    lbfgs_computeDirection(gf, h, p, a, t1, t2)
+    for j := 0; j < n; j++ {
+      if b[j] {
+        p.At(j).SetFloat64(0.0)
+      }
+    }
+    // the direction is not guaranteed to be a descent direction once the
+    // active coordinates are fixed, in this case drop the history and use
+    // projected steepest descent
+    if t1.VdotV(g1, p).GetFloat64() >= 0.0 && h.k > 0 {
+      h.reset()
+      p.VmulS(gf, ConstFloat64(-1.0))
+    }
+    if ok := search(); !ok || equals(x1, x2) {
+      if h.k == 0 {
+        // projected steepest descent failed, stop optimization here
+        return x1, ErrLineSearch
+      }
+      // drop history to find a new direction
+      h.reset()
+      continue
+    }
+    // evaluate objective at new position
+    if err := differentiate(f, x2, X1, g2, y2); err != nil {
+      return x1, fmt.Errorf("invalid value: %s", err)
+    }
+    // execute hook if available
+    if hook.Value != nil && hook.Value(x2, g2, y2) {
+      return x2, nil
+    }
+    // evaluate stop criterion
+    if projectedGradientNorm(bounds, x2, g2) < epsilon.Value {
+      return x2, nil
+    }
+    h.push(x1, x2, g1, g2, t1)
+
+    g1.Set(g2)
+    x1.Set(x2)
+    y1.Set(y2)
+  }
+  return x1, nil
+}
@@ -0,0 +1,213 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lbfgs
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+func TestLbfgsRosenbrock(test *testing.T) {
+
+  f := func(x ConstVector) (MagicScalar, error) {
+    // f(x1, x2) = (a - x1)^2 + b(x2 - x1^2)^2
+    // a = 1
+    // b = 100
+    // minimum: (x1,x2) = (a, a^2)
+    a  := ConstFloat64(  1.0)
+    b  := ConstFloat64(100.0)
+    c  := ConstFloat64(  2.0)
+    t1 := NullReal64()
+    t2 := NullReal64()
+    t1.Mul(b, t1.Pow(t1.Sub(x.ConstAt(1), t1.Mul(x.ConstAt(0), x.ConstAt(0))), c))
+    t2.Pow(t2.Sub(a, x.ConstAt(0)), c)
+    t1.Add(t1, t2)
+    return t1, nil
+  }
+  t  := NewFloat64(0.0)
+  x0 := NewDenseFloat64Vector([]float64{-0.5, 2})
+  xr := NewDenseFloat64Vector([]float64{   1, 1})
+  xn, err := Run(f, x0, Epsilon{1e-10})
+  if err != nil {
+    test.Error(err)
+  }
+  if t.Vnorm(xn.VsubV(xn, xr)).GetFloat64() > 1e-8 {
+    test.Error("L-BFGS Rosenbrock test failed!")
+  }
+}
+
+func TestLbfgsHighDimensional(test *testing.T) {
+  // extended Rosenbrock function
+  // f(x) = sum_i 100 (x_{2i} - x_{2i-1}^2)^2 + (1 - x_{2i-1})^2
+  n := 1000
+  f := func(x ConstVector) (MagicScalar, error) {
+    r  := NullReal64()
+    t1 := NullReal64()
+    t2 := NullReal64()
+    for i := 0; i < n; i += 2 {
+      t1.Mul(x.ConstAt(i), x.ConstAt(i))
+      t1.Sub(x.ConstAt(i+1), t1)
+      t1.Mul(t1, t1)
+      t1.Mul(t1, ConstFloat64(100.0))
+      t2.Sub(ConstFloat64(1.0), x.ConstAt(i))
+      t2.Mul(t2, t2)
+      r.Add(r, t1)
+      r.Add(r, t2)
+    }
+    return r, nil
+  }
+  x0 := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i += 2 {
+    x0[i  ] = -1.2
+    x0[i+1] =  1.0
+  }
+  xn, err := Run(f, x0, History{5}, Epsilon{1e-8})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  for i := 0; i < n; i++ {
+    if math.Abs(xn.At(i).GetFloat64() - 1.0) > 1e-6 {
+      test.Error("L-BFGS extended Rosenbrock test failed!"); break
+    }
+  }
+}
+
+func TestLbfgsConstraints(test *testing.T) {
+  // f(x) = x - log(x), defined for x > 0
+  // minimum: f(1) = 1
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    r.Log(x.ConstAt(0))
+    r.Sub(x.ConstAt(0), r)
+    return r, nil
+  }
+  constraints := func(x Vector) bool {
+    return x.At(0).GetFloat64() > 0.0
+  }
+  x0 := NewDenseFloat64Vector([]float64{10})
+  xn, err := Run(f, x0, Constraints{constraints}, Epsilon{1e-10})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if math.Abs(xn.At(0).GetFloat64() - 1.0) > 1e-8 {
+    test.Error("L-BFGS constraints test failed!")
+  }
+  if _, err := Run(f, NewDenseFloat64Vector([]float64{-1}), Constraints{constraints}); err == nil {
+    test.Error("L-BFGS constraints test failed!")
+  }
+}
+
+func TestLbfgsBounds1(test *testing.T) {
+  // Rosenbrock function with bound x1 <= 0.5
+  // minimum: (x1,x2) = (0.5, 0.25)
+  f := func(x ConstVector) (MagicScalar, error) {
+    a  := ConstFloat64(  1.0)
+    b  := ConstFloat64(100.0)
+    c  := ConstFloat64(  2.0)
+    t1 := NullReal64()
+    t2 := NullReal64()
+    t1.Mul(b, t1.Pow(t1.Sub(x.ConstAt(1), t1.Mul(x.ConstAt(0), x.ConstAt(0))), c))
+    t2.Pow(t2.Sub(a, x.ConstAt(0)), c)
+    t1.Add(t1, t2)
+    return t1, nil
+  }
+  t  := NewFloat64(0.0)
+  x0 := NewDenseFloat64Vector([]float64{-0.5, 2})
+  xr := NewDenseFloat64Vector([]float64{ 0.5, 0.25})
+  xn, err := Run(f, x0,
+    Bounds{Upper: []float64{0.5, math.Inf(1)}},
+    Epsilon{1e-10})
+  if err != nil {
+    test.Error(err)
+  }
+  if t.Vnorm(xn.VsubV(xn, xr)).GetFloat64() > 1e-8 {
+    test.Error("L-BFGS-B Rosenbrock test failed!")
+  }
+}
+
+func TestLbfgsBounds2(test *testing.T) {
+  // f(x) = sum_i (x_i - c_i)^2 with 0 <= x_i <= 1
+  n := 20
+  c := make([]float64, n)
+  l := make([]float64, n)
+  u := make([]float64, n)
+  for i := 0; i < n; i++ {
+    c[i] = -1.0 + 3.0*float64(i)/float64(n-1)
+    u[i] =  1.0
+  }
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    t := NullReal64()
+    for i := 0; i < n; i++ {
+      t.Sub(x.ConstAt(i), ConstFloat64(c[i]))
+      t.Mul(t, t)
+      r.Add(r, t)
+    }
+    return r, nil
+  }
+  x0 := NullDenseFloat64Vector(n)
+  xn, err := Run(f, x0, Bounds{Lower: l, Upper: u}, Epsilon{1e-10})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  for i := 0; i < n; i++ {
+    if math.Abs(xn.At(i).GetFloat64() - math.Min(math.Max(c[i], 0.0), 1.0)) > 1e-8 {
+      test.Error("L-BFGS-B test failed!"); break
+    }
+  }
+}
+
+func TestLbfgsMinimize(test *testing.T) {
+  // f(x) = sum_i (x_i - c_i)^2 with x_i <= 0
+  // minimum: x = (-1, 0)
+  f := func(x ConstVector) (MagicScalar, error) {
+    c  := []float64{-1, 2}
+    r  := NullReal64()
+    t  := NullReal64()
+    for i := 0; i < x.Dim(); i++ {
+      t.Sub(x.ConstAt(i), ConstFloat64(c[i]))
+      r.Add(r, t.Mul(t, t))
+    }
+    return r, nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{-2, -2})
+  r, err := Minimize(f, x0, Bounds{Upper: []float64{0, 0}}, Epsilon{1e-10})
+  if err != nil {
+    test.Error(err)
+  }
+  // the projected gradient vanishes at the solution
+  if r.Status != optimize.Converged || r.GradientNorm >= 1e-10 {
+    test.Error("test failed")
+  }
+  if math.Abs(r.Value - 4.0) > 1e-8 || math.Abs(r.X.Float64At(0) + 1.0) > 1e-8 || r.X.Float64At(1) != 0.0 {
+    test.Error("test failed")
+  }  // the initial value is recorded if it already satisfies the stop
+  // criterion
+  x1 := NewDenseFloat64Vector([]float64{-1, 0})
+  if r, _ := Minimize(f, x1, Bounds{Upper: []float64{0, 0}}, optimize.Trace{Value: true}); r.Status != optimize.Converged || r.Iterations != 0 || len(r.Trace) != 1 {
+    test.Error("test failed")
+  }
+}
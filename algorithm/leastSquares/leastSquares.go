@@ -0,0 +1,204 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leastSquares
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/backSubstitution"
+import   "github.com/pbenner/autodiff/algorithm/qr"
+
+/* -------------------------------------------------------------------------- */
+
+// Tikhonov regularization lambda ||x||^2
+type Ridge struct {
+  Value float64
+}
+
+// Weights w_i of the squared residuals
+type Weights struct {
+  Value ConstVector
+}
+
+// Relative tolerance for detecting rank deficient matrices
+type Epsilon struct {
+  Value float64
+}
+
+type InSitu struct {
+  QR qr.InSitu
+  A  Matrix
+  B  Vector
+  C  Vector
+  T  Scalar
+}
+
+/* -------------------------------------------------------------------------- */
+
+func leastSquares(a ConstMatrix, b ConstVector, w ConstVector, lambda, epsilon float64, inSitu *InSitu) (Vector, error) {
+  m, n := a.Dims()
+  A := inSitu.A
+  B := inSitu.B
+  C := inSitu.C
+  t := inSitu.T
+
+  // construct the augmented system [ W^1/2 A; lambda^1/2 I ] x = [ W^1/2 b; 0 ]
+  A.Reset()
+  B.Reset()
+  for i := 0; i < m; i++ {
+    if w != nil {
+      t.Sqrt(w.ConstAt(i))
+    }
+    for j := 0; j < n; j++ {
+      if w != nil {
+        A.At(i, j).Mul(a.ConstAt(i, j), t)
+      } else {
+        A.At(i, j).Set(a.ConstAt(i, j))
+      }
+    }
+    if w != nil {
+      B.At(i).Mul(b.ConstAt(i), t)
+    } else {
+      B.At(i).Set(b.ConstAt(i))
+    }
+  }
+  if lambda != 0.0 {
+    t.SetFloat64(lambda)
+    t.Sqrt(t)
+    for j := 0; j < n; j++ {
+      A.At(m+j, j).Set(t)
+    }
+  }
+  // A P = Q R, where column pivoting is required for a reliable rank
+  // estimate
+  Q, R, p, err := qr.Run(A, qr.Thin{Value: true}, qr.ColumnPivoting{Value: true}, &inSitu.QR)
+  if err != nil {
+    return nil, err
+  }
+  if qr.Rank(R, epsilon) < n {
+    return nil, fmt.Errorf("matrix does not have full column rank")
+  }
+  // solve R z = Q^T b
+  C.VdotM(B, Q)
+
+  z, err := backSubstitution.Run(R, C)
+  if err != nil {
+    return nil, err
+  }
+  // x = P z
+  x := z.CloneVector()
+  for j := 0; j < n; j++ {
+    x.At(p[j]).Set(z.At(j))
+  }
+  return x, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize ||W^1/2 (A x - b)||^2 + lambda ||x||^2, where W is a diagonal
+// matrix of weights (Weights option) and lambda the ridge penalty (Ridge
+// option). The problem is solved with a Householder QR decomposition of
+// the augmented system with column pivoting, which requires that A has full
+// column rank unless lambda is positive. Diagonal elements of R smaller than
+// epsilon times the largest diagonal element (Epsilon option) indicate a
+// rank deficient matrix. Weights must be non-negative.
+func Run(a ConstMatrix, b ConstVector, args ...interface{}) (Vector, error) {
+  m, n := a.Dims()
+  t    := a.ElementType()
+
+  if b.Dim() != m {
+    return nil, fmt.Errorf("matrix vector dimensions do not match")
+  }
+  // use element type of b if derivatives are
+  // computed only with respect to b
+  if t != Real32Type && t != Real64Type {
+    if s := b.ElementType(); s == Real32Type || s == Real64Type {
+      t = s
+    }
+  }
+  inSitu  := &InSitu{}
+  lambda  := 0.0
+  epsilon := 1e-12
+
+  var w ConstVector
+
+  // loop over optional arguments
+  for _, arg := range args {
+    switch tmp := arg.(type) {
+    case Ridge:
+      lambda = tmp.Value
+    case Weights:
+      w = tmp.Value
+    case Epsilon:
+      epsilon = tmp.Value
+    case *InSitu:
+      inSitu = tmp
+    case InSitu:
+      panic("InSitu must be passed by reference")
+    default:
+      panic("LeastSquares(): Invalid optional argument!")
+    }
+  }
+  if lambda < 0.0 {
+    return nil, fmt.Errorf("ridge penalty must be non-negative")
+  }
+  if w != nil && w.Dim() != m {
+    return nil, fmt.Errorf("weight vector has invalid dimension")
+  }
+  if w != nil {
+    for i := 0; i < m; i++ {
+      if w.ConstAt(i).GetFloat64() < 0.0 {
+        return nil, fmt.Errorf("weights must be non-negative")
+      }
+    }
+  }
+  // number of rows of the augmented system
+  k := m
+  if lambda != 0.0 {
+    k += n
+  }
+  if k < n {
+    return nil, fmt.Errorf("system is underdetermined")
+  }
+  if inSitu.A == nil {
+    inSitu.A = NullDenseMatrix(t, k, n)
+  } else {
+    if u, v := inSitu.A.Dims(); u != k || v != n {
+      return nil, fmt.Errorf("A has invalid dimension (%dx%d instead of %dx%d)", u, v, k, n)
+    }
+  }
+  if inSitu.B == nil {
+    inSitu.B = NullDenseVector(t, k)
+  } else {
+    if inSitu.B.Dim() != k {
+      return nil, fmt.Errorf("B has invalid dimension")
+    }
+  }
+  if inSitu.C == nil {
+    inSitu.C = NullDenseVector(t, n)
+  } else {
+    if inSitu.C.Dim() != n {
+      return nil, fmt.Errorf("C has invalid dimension")
+    }
+  }
+  if inSitu.T == nil {
+    inSitu.T = NullScalar(t)
+  }
+  return leastSquares(a, b, w, lambda, epsilon, inSitu)
+}
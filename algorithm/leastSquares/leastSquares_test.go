@@ -0,0 +1,115 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leastSquares
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestLeastSquares1(test *testing.T) {
+  // fit a line to four points
+  a := NewDenseFloat64Matrix([]float64{
+    1, 1,
+    1, 2,
+    1, 3,
+    1, 4 }, 4, 2)
+  b := NewDenseFloat64Vector([]float64{6, 5, 7, 10})
+
+  if x, err := Run(a, b); err != nil {
+    test.Error(err)
+  } else {
+    if !x.Equals(NewDenseFloat64Vector([]float64{3.5, 1.4}), 1e-10) {
+      test.Errorf("test failed: %v", x)
+    }
+  }
+  // weights of zero remove observations
+  w := NewDenseFloat64Vector([]float64{1, 1, 0, 1})
+  if x, err := Run(a, b, Weights{w}); err != nil {
+    test.Error(err)
+  } else {
+    if !x.Equals(NewDenseFloat64Vector([]float64{3.5, 1.5}), 1e-10) {
+      test.Errorf("test failed: %v", x)
+    }
+  }
+}
+
+func TestLeastSquares2(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2,
+    2, 4,
+    3, 6 }, 3, 2)
+  b := NewDenseFloat64Vector([]float64{1, 2, 3})
+
+  // a is rank deficient
+  if _, err := Run(a, b); err == nil {
+    test.Error("test failed")
+  }
+  // ridge regression: x = (A^T A + lambda I)^-1 A^T b
+  if x, err := Run(a, b, Ridge{1.0}); err != nil {
+    test.Error(err)
+  } else {
+    if !x.Equals(NewDenseFloat64Vector([]float64{14.0/71.0, 28.0/71.0}), 1e-10) {
+      test.Errorf("test failed: %v", x)
+    }
+  }
+}
+
+func TestLeastSquares3(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 1,
+    1, 2,
+    1, 3,
+    1, 4 }, 4, 2)
+  b := NewDenseReal64Vector([]float64{6, 5, 7, 10})
+  b.Variables(1)
+
+  x, err := Run(a, b)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  // x = (A^T A)^-1 A^T b, hence dx_1/db_1 is the
+  // first element of the second row of (A^T A)^-1 A^T
+  if math.Abs(x.ConstAt(1).GetDerivative(0) + 0.3) > 1e-10 {
+    test.Errorf("test failed: %v", x.ConstAt(1).GetDerivative(0))
+  }
+}
+
+func TestLeastSquares4(test *testing.T) {
+  // the first column is numerically zero compared to the other columns,
+  // which is only detected with column pivoting
+  a := NewDenseFloat64Matrix([]float64{
+    1e-14, 1, 1,
+        0, 1, 2,
+        0, 1, 3,
+        0, 1, 4 }, 4, 3)
+  b := NewDenseFloat64Vector([]float64{6, 5, 7, 10})
+
+  if _, err := Run(a, b); err == nil {
+    test.Error("test failed")
+  }
+  // negative weights
+  if _, err := Run(a.Slice(0, 4, 1, 3), b, Weights{NewDenseFloat64Vector([]float64{1, -1, 1, 1})}); err == nil {
+    test.Error("test failed")
+  }
+}
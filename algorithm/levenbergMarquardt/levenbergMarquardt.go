@@ -0,0 +1,450 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// References:
+// Moré, Jorge J. The Levenberg-Marquardt algorithm: implementation and
+// theory. Numerical analysis. Springer, 1978. 105-116.
+// Transtrum, Mark K., and James P. Sethna. Improvements to the
+// Levenberg-Marquardt algorithm for nonlinear least-squares minimization.
+// arXiv:1201.5885 (2012).
+
+/* -------------------------------------------------------------------------- */
+
+package levenbergMarquardt
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/cholesky"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+import   "github.com/pbenner/autodiff/algorithm/pseudoInverse"
+
+/* -------------------------------------------------------------------------- */
+
+// Residual function r(x), the objective is the sum of squares ||r(x)||^2
+type Residuals func(ConstVector) (MagicVector, error)
+
+type Epsilon struct {
+  Value float64
+}
+
+type MaxIterations struct {
+  Value int
+}
+
+// Initial damping parameter
+type Lambda struct {
+  Value float64
+}
+
+// Scale the damping term with the diagonal of J^T J (Marquardt), which
+// makes the algorithm invariant to rescaling of parameters. Otherwise the
+// identity matrix is used (Levenberg).
+type Scaling struct {
+  Value bool
+}
+
+// Add a second order correction to the step along the geodesic of the model
+// manifold. Alpha is the maximum ratio between acceleration and velocity.
+type GeodesicAcceleration struct {
+  Value bool
+  Alpha float64
+}
+
+type Hook struct {
+  Value func(x, r ConstVector, y ConstScalar) bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+// evaluate residuals r and Jacobian J at x
+func evalJacobian(f Residuals, x ConstVector, r Vector, J Matrix) error {
+  var err error
+  g := func(x ConstVector) ConstVector {
+    y, e := f(x)
+    if e != nil {
+      err = e
+      return NullDenseReal64Vector(r.Dim())
+    }
+    r.Set(y)
+    return y
+  }
+  J.Jacobian(g, AsDenseReal64Vector(x))
+  return err
+}
+
+// evaluate residuals at x
+func evalResiduals(f Residuals, x ConstVector, r Vector) error {
+  y, err := f(x)
+  if err != nil {
+    return err
+  }
+  if y.Dim() != r.Dim() {
+    return fmt.Errorf("residual vector has invalid dimension")
+  }
+  r.Set(y)
+  for i := 0; i < r.Dim(); i++ {
+    if v := r.At(i).GetFloat64(); math.IsNaN(v) || math.IsInf(v, 0) {
+      return fmt.Errorf("invalid residuals")
+    }
+  }
+  return nil
+}
+
+// second directional derivative of the residuals along v, which is
+// computed with autodiff
+func evalDirectionalDerivative2(f Residuals, x, v ConstVector, r Vector) error {
+  t := NewReal64(0.0)
+  if err := Variables(2, t); err != nil {
+    return err
+  }
+  X := NullDenseReal64Vector(x.Dim())
+  s := NullReal64()
+  // X = x + t v
+  for i := 0; i < x.Dim(); i++ {
+    s.Mul(t, v.ConstAt(i))
+    X.At(i).Add(x.ConstAt(i), s)
+  }
+  y, err := f(X)
+  if err != nil {
+    return err
+  }
+  for i := 0; i < r.Dim(); i++ {
+    if y.ConstAt(i).GetOrder() >= 2 && y.ConstAt(i).GetN() > 0 {
+      r.At(i).SetFloat64(y.ConstAt(i).GetHessian(0, 0))
+    } else {
+      r.At(i).SetFloat64(0.0)
+    }
+  }
+  return nil
+}
+
+// solve (J^T J + lambda D) x = b
+func solve(A Matrix, d []float64, lambda float64, b ConstVector) (Vector, error) {
+  n, _ := A.Dims()
+  B := A.CloneMatrix()
+  for i := 0; i < n; i++ {
+    B.At(i, i).SetFloat64(A.At(i, i).GetFloat64() + lambda*d[i])
+  }
+  L, _, err := cholesky.Run(B)
+  if err != nil {
+    return nil, err
+  }
+  return cholesky.Solve(L, nil, b)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns the solution, J^T J and the residuals at the solution
+func levenbergMarquardt(f Residuals, x0 Vector, epsilon Epsilon, maxIterations MaxIterations, lambda_ Lambda, scaling Scaling, acceleration GeodesicAcceleration, hook Hook) (Vector, Matrix, Vector, error) {
+  n := x0.Dim()
+  x1 := AsDenseFloat64Vector(x0)
+  x2 := AsDenseFloat64Vector(x0)
+  // determine number of residuals
+  m := 0
+  if r, err := f(x1); err != nil {
+    return x1, nil, nil, fmt.Errorf("invalid initial value: %s", err)
+  } else {
+    m = r.Dim()
+  }
+  r1 := NullDenseFloat64Vector(m)
+  r2 := NullDenseFloat64Vector(m)
+  rv := NullDenseFloat64Vector(m)
+  J  := NullDenseFloat64Matrix(m, n)
+  A  := NullDenseFloat64Matrix(n, n)
+  g  := NullDenseFloat64Vector(n)
+  p  := NullDenseFloat64Vector(n)
+  d  := make([]float64, n)
+  y1 := 0.0
+  y2 := 0.0
+
+  lambda := lambda_.Value
+  nu     := 2.0
+
+  // evaluate residuals, J^T J and the gradient J^T r
+  eval := func(x Vector) error {
+    if err := evalJacobian(f, x, r1, J); err != nil {
+      return err
+    }
+    A.MdotM(J.T(), J)
+    g.MdotV(J.T(), r1)
+    y1 = NullFloat64().VdotV(r1, r1).GetFloat64()
+    for i := 0; i < n; i++ {
+      if scaling.Value {
+        // keep the largest diagonal element
+        d[i] = math.Max(d[i], A.At(i, i).GetFloat64())
+        // a zero column of J would not be damped at all, use one
+        // instead as in MINPACK
+        if d[i] == 0.0 {
+          d[i] = 1.0
+        }
+      } else {
+        d[i] = 1.0
+      }
+    }
+    return nil
+  }
+  if err := eval(x1); err != nil {
+    return x1, nil, nil, fmt.Errorf("invalid initial value: %s", err)
+  }
+  // execute hook if available
+  if hook.Value != nil && hook.Value(x1, r1, ConstFloat64(y1)) {
+    return x1, A, r1, nil
+  }
+  for i := 0; i < maxIterations.Value; i++ {
+    // evaluate stop criterion
+    if maxAbs(g) < epsilon.Value {
+      break
+    }
+    // velocity: (J^T J + lambda D) v = -J^T r
+    v, err := solve(A, d, lambda, g)
+    if err != nil {
+      lambda *= nu; nu *= 2.0
+      continue
+    }
+    v.VmulS(v, ConstFloat64(-1.0))
+    p.Set(v)
+    if acceleration.Value {
+      // acceleration: (J^T J + lambda D) a = -J^T r_vv
+      if err := evalDirectionalDerivative2(f, x1, v, rv); err == nil {
+        t := NullDenseFloat64Vector(n)
+        t.MdotV(J.T(), rv)
+        if a, err := solve(A, d, lambda, t); err == nil {
+          a.VmulS(a, ConstFloat64(-1.0))
+          // accept acceleration only if it is small compared to the
+          // velocity, i.e. 2 ||a||/||v|| <= alpha
+          if 2.0*NullFloat64().Vnorm(a).GetFloat64() <= acceleration.Alpha*NullFloat64().Vnorm(v).GetFloat64() {
+            // p = v + a/2
+            a.VmulS(a, ConstFloat64(0.5))
+            p.VaddV(p, a)
+          }
+        }
+      }
+    }
+    x2.VaddV(x1, p)
+    // predicted reduction of the sum of squares using the velocity
+    // -2 v^T g - v^T A v = lambda v^T D v - v^T g
+    pred := -NullFloat64().VdotV(v, g).GetFloat64()
+    for j := 0; j < n; j++ {
+      pred += lambda*d[j]*v.At(j).GetFloat64()*v.At(j).GetFloat64()
+    }
+    rho := -1.0
+    if err := evalResiduals(f, x2, r2); err == nil {
+      y2  = NullFloat64().VdotV(r2, r2).GetFloat64()
+      rho = (y1 - y2)/pred
+    }
+    if rho > 0.0 {
+      // evaluate stop criterion on the step size
+      small := NullFloat64().Vnorm(p).GetFloat64() < epsilon.Value*(NullFloat64().Vnorm(x1).GetFloat64() + epsilon.Value)
+      // accept step
+      x1.Set(x2)
+      if err := eval(x1); err != nil {
+        return x1, nil, nil, err
+      }
+      lambda *= math.Max(1.0/3.0, 1.0 - math.Pow(2.0*rho - 1.0, 3.0))
+      nu      = 2.0
+      // execute hook if available
+      if hook.Value != nil && hook.Value(x1, r1, ConstFloat64(y1)) {
+        break
+      }
+      if small {
+        break
+      }
+    } else {
+      lambda *= nu
+      nu     *= 2.0
+    }
+  }
+  return x1, A, r1, nil
+}
+
+func maxAbs(x DenseFloat64Vector) float64 {
+  r := 0.0
+  for i := 0; i < x.Dim(); i++ {
+    r = math.Max(r, math.Abs(x[i]))
+  }
+  return r
+}
+
+// covariance estimate s^2 (J^T J)^-1 of the parameters with residual
+// variance s^2 = ||r||^2/(m - n), where A = J^T J and r are the residuals
+func covariance(A Matrix, r Vector) (Matrix, error) {
+  n, _ := A.Dims()
+  m    := r.Dim()
+  if m <= n {
+    return nil, nil
+  }
+  c, err := pseudoInverse.Run(A)
+  if err != nil {
+    return nil, fmt.Errorf("computing covariance failed: %v", err)
+  }
+  y := NullFloat64().VdotV(r, r).GetFloat64()
+  c.MmulS(c, ConstFloat64(y/float64(m - n)))
+  return c, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize the sum of squares ||r(x)||^2 with the Levenberg-Marquardt
+// algorithm starting at x0. The Jacobian of the residuals is computed with
+// autodiff. Returns the solution and an estimate of the covariance of the
+// parameters, which is nil if there are not more residuals than parameters.
+// An error is returned if the covariance cannot be computed.
+// Optional arguments:
+//
+//   Epsilon             : stop if the maximum absolute gradient or the
+//                         relative step size is smaller than epsilon
+//   MaxIterations       : maximum number of iterations
+//   Lambda              : initial damping parameter
+//   Scaling             : use the diagonal of J^T J for damping
+//   GeodesicAcceleration: use second order corrections
+//   Hook                : called at the initial value and after every
+//                         accepted step, stop if it returns true
+func Run(f Residuals, x0 Vector, args ...interface{}) (Vector, Matrix, error) {
+  x, A, r, err := run(f, x0, args...)
+  if err != nil {
+    return x, nil, err
+  }
+  c, err := covariance(A, r)
+  return x, c, err
+}
+
+func run(f Residuals, x0 Vector, args ...interface{}) (Vector, Matrix, Vector, error) {
+
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{1000}
+  lambda        := Lambda       {1e-3}
+  scaling       := Scaling      {true}
+  acceleration  := GeodesicAcceleration{false, 0.75}
+  hook          := Hook         { nil}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Lambda:
+      lambda = a
+    case Scaling:
+      scaling = a
+    case GeodesicAcceleration:
+      acceleration = a
+      if acceleration.Alpha == 0.0 {
+        acceleration.Alpha = 0.75
+      }
+    case Hook:
+      hook = a
+    default:
+      panic("LevenbergMarquardt(): Invalid optional argument!")
+    }
+  }
+  if lambda.Value <= 0.0 {
+    return nil, nil, nil, fmt.Errorf("damping parameter must be positive")
+  }
+  return levenbergMarquardt(f, x0, epsilon, maxIterations, lambda, scaling, acceleration, hook)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize the sum of squares ||r(x)||^2 starting at x0 and return the
+// solution together with convergence diagnostics. Accepts the same optional
+// arguments as Run and additionally optimize.Trace. Use Run to obtain the
+// covariance of the parameters.
+func Minimize(f Residuals, x0 Vector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook    := Hook   { nil}
+  epsilon := Epsilon{1e-8}
+  options := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicVector, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // position before the last accepted step
+  x1 := AsDenseFloat64Vector(x0)
+  // stop criterion on the size of the last accepted step
+  small := false
+  // the hook is called at the initial value and after every accepted step,
+  // where the gradient is not available
+  first := true
+  options = append(options, Hook{func(x, residuals ConstVector, y ConstScalar) bool {
+    x2 := AsDenseFloat64Vector(x)
+    if first {
+      r.Init(x, y.GetFloat64(), math.NaN()); first = false
+    } else {
+      p := NullDenseFloat64Vector(x2.Dim())
+      p.VsubV(x2, x1)
+      small = optimize.Norm(p) < epsilon.Value*(optimize.Norm(x1) + epsilon.Value)
+      r.Iteration(x, y.GetFloat64(), math.NaN())
+    }
+    x1 = x2
+    return hook.Value != nil && r.Hook(hook.Value(x, residuals, y))
+  }})
+  x, _, _, err := run(g, x0, options...)
+  if x == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  // evaluate residuals and the gradient J^T r at the solution, where the
+  // gradient of the sum of squares is 2 J^T r
+  y     := math.NaN()
+  gnorm := math.NaN()
+  gmax  := math.NaN()
+  if rx, e := f(x); e != nil {
+    if err == nil {
+      err = e
+    }
+  } else {
+    m  := rx.Dim()
+    rv := NullDenseFloat64Vector(m)
+    J  := NullDenseFloat64Matrix(m, x.Dim())
+    gv := NullDenseFloat64Vector(x.Dim())
+    if e := evalJacobian(f, x, rv, J); e != nil {
+      if err == nil {
+        err = e
+      }
+    } else {
+      gv.MdotV(J.T(), rv)
+      y     = NullFloat64().VdotV(rv, rv).GetFloat64()
+      gnorm = 2.0*optimize.Norm(gv)
+      gmax  = maxAbs(gv)
+    }
+  }
+  switch {
+  case err != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), err
+  case gmax < epsilon.Value || small:
+    return r.Result(x, y, gnorm, optimize.Converged), nil
+  default:
+    return r.Result(x, y, gnorm, optimize.MaxIterationsReached), nil
+  }
+}
@@ -0,0 +1,152 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package levenbergMarquardt
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestLevenbergMarquardt1(test *testing.T) {
+  // fit y = a exp(-b t) to noise-free data
+  t := []float64{0, 0.5, 1, 1.5, 2, 2.5, 3, 3.5, 4}
+  y := make([]float64, len(t))
+  for i := 0; i < len(t); i++ {
+    y[i] = 5.0*math.Exp(-1.3*t[i])
+  }
+  f := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(len(t))
+    for i := 0; i < len(t); i++ {
+      r.At(i).Mul(x.ConstAt(1), ConstFloat64(-t[i]))
+      r.At(i).Exp(r.At(i))
+      r.At(i).Mul(r.At(i), x.ConstAt(0))
+      r.At(i).Sub(r.At(i), ConstFloat64(y[i]))
+    }
+    return r, nil
+  }
+  for _, acceleration := range []bool{false, true} {
+    x, _, err := Run(f, NewDenseFloat64Vector([]float64{1, 0.1}), GeodesicAcceleration{Value: acceleration})
+    if err != nil {
+      test.Error(err); return
+    }
+    if math.Abs(x.At(0).GetFloat64() - 5.0) > 1e-6 || math.Abs(x.At(1).GetFloat64() - 1.3) > 1e-6 {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestLevenbergMarquardt2(test *testing.T) {
+  // linear regression y = a + b t, the covariance must match the ordinary
+  // least squares estimate s^2 (X^T X)^-1
+  t := []float64{1, 2, 3, 4, 5, 6}
+  y := []float64{1.1, 1.9, 3.2, 3.9, 5.2, 5.8}
+  f := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(len(t))
+    for i := 0; i < len(t); i++ {
+      r.At(i).Mul(x.ConstAt(1), ConstFloat64(t[i]))
+      r.At(i).Add(r.At(i), x.ConstAt(0))
+      r.At(i).Sub(r.At(i), ConstFloat64(y[i]))
+    }
+    return r, nil
+  }
+  x, c, err := Run(f, NewDenseFloat64Vector([]float64{0, 0}))
+  if err != nil {
+    test.Error(err); return
+  }
+  // closed form solution
+  n  := float64(len(t))
+  st, sy, stt, sty := 0.0, 0.0, 0.0, 0.0
+  for i := 0; i < len(t); i++ {
+    st  += t[i]
+    sy  += y[i]
+    stt += t[i]*t[i]
+    sty += t[i]*y[i]
+  }
+  det := n*stt - st*st
+  b := (n*sty - st*sy)/det
+  a := (sy - b*st)/n
+  s2 := 0.0
+  for i := 0; i < len(t); i++ {
+    s2 += (y[i] - a - b*t[i])*(y[i] - a - b*t[i])
+  }
+  s2 /= n - 2.0
+  cr := NewDenseFloat64Matrix([]float64{
+     s2*stt/det, -s2*st/det,
+    -s2*st /det,  s2*n /det }, 2, 2)
+
+  if math.Abs(x.At(0).GetFloat64() - a) > 1e-8 || math.Abs(x.At(1).GetFloat64() - b) > 1e-8 {
+    test.Error("test failed")
+  }
+  if !c.Equals(cr, 1e-8) {
+    test.Error("test failed")
+  }
+}
+
+func TestLevenbergMarquardt3(test *testing.T) {
+  // Rosenbrock function as sum of squares with badly scaled parameters
+  // r1 = 10 (x2/1000 - x1^2), r2 = 1 - x1
+  // minimum: (x1,x2) = (1, 1000)
+  f := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(2)
+    t := NullReal64()
+    t      .Mul(x.ConstAt(0), x.ConstAt(0))
+    r.At(0).Div(x.ConstAt(1), ConstFloat64(1000.0))
+    r.At(0).Sub(r.At(0), t)
+    r.At(0).Mul(r.At(0), ConstFloat64(10.0))
+    r.At(1).Sub(ConstFloat64(1.0), x.ConstAt(0))
+    return r, nil
+  }
+  for _, acceleration := range []bool{false, true} {
+    x, c, err := Run(f, NewDenseFloat64Vector([]float64{-1.2, 1000}),
+      Scaling{true},
+      GeodesicAcceleration{Value: acceleration})
+    if err != nil {
+      test.Error(err); return
+    }
+    if math.Abs(x.At(0).GetFloat64() - 1.0) > 1e-6 || math.Abs(x.At(1).GetFloat64() - 1000.0) > 1e-3 {
+      test.Error("test failed")
+    }
+    if c != nil {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestLevenbergMarquardt4(test *testing.T) {
+  // the residuals do not depend on the second parameter, i.e. the second
+  // column of the Jacobian is zero
+  y := []float64{1, 2, 3, 6}
+  f := func(x ConstVector) (MagicVector, error) {
+    r := NullDenseReal64Vector(len(y))
+    for i := 0; i < len(y); i++ {
+      r.At(i).Sub(x.ConstAt(0), ConstFloat64(y[i]))
+    }
+    return r, nil
+  }
+  x, _, err := Run(f, NewDenseFloat64Vector([]float64{0, 1}))
+  if err != nil {
+    test.Error(err); return
+  }
+  if math.Abs(x.At(0).GetFloat64() - 3.0) > 1e-6 || x.At(1).GetFloat64() != 1.0 {
+    test.Error("test failed")
+  }
+}
@@ -0,0 +1,185 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logm
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/lu"
+import   "github.com/pbenner/autodiff/algorithm/matrixNorm"
+import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
+import   "github.com/pbenner/autodiff/algorithm/sqrtm"
+
+/* -------------------------------------------------------------------------- */
+
+// Degree of the Padé approximant of log(I + X), which is accurate to
+// double precision for ||X|| <= theta (Higham, 2001)
+const padeDegree = 7
+const padeTheta  = 0.25
+
+// Maximum number of square roots taken before the Padé approximant is
+// applied
+const maxSquareRoots = 100
+
+/* -------------------------------------------------------------------------- */
+
+// Gauss-Legendre nodes and weights on the interval [0,1]
+func gaussLegendre(m int) ([]float64, []float64) {
+  x := make([]float64, m)
+  w := make([]float64, m)
+  for i := 0; i < m; i++ {
+    // initial guess for the ith root of the Legendre polynomial P_m
+    z := math.Cos(math.Pi*(float64(i)+0.75)/(float64(m)+0.5))
+    d := 0.0
+    for k := 0; k < 100; k++ {
+      // evaluate P_m(z) and its derivative with the three-term recurrence
+      p0, p1 := 1.0, z
+      for j := 2; j <= m; j++ {
+        p0, p1 = p1, ((2*float64(j)-1)*z*p1 - (float64(j)-1)*p0)/float64(j)
+      }
+      d  = float64(m)*(z*p1 - p0)/(z*z - 1)
+      dz := p1/d
+      z -= dz
+      if math.Abs(dz) < 1e-16 {
+        break
+      }
+    }
+    x[i] = (1.0 - z)/2.0
+    w[i] = 1.0/((1.0 - z*z)*d*d)
+  }
+  return x, w
+}
+
+// Evaluate the Padé approximant of log(I + X) in partial fraction form,
+// i.e. sum_j w_j (I + x_j X)^-1 X, where x_j and w_j are the Gauss-Legendre
+// nodes and weights
+func pade(x Matrix, m int) (Matrix, error) {
+  n, _ := x.Dims()
+  e := x.ElementType()
+  t := NullScalar(e)
+  r := NullDenseMatrix(e, n, n)
+  a := NullDenseMatrix(e, n, n)
+  y := NullDenseMatrix(e, n, n)
+  nodes, weights := gaussLegendre(m)
+  for k := 0; k < m; k++ {
+    // A = I + x_k X
+    t.SetFloat64(nodes[k])
+    a.MmulS(x, t)
+    for i := 0; i < n; i++ {
+      a.At(i, i).Add(a.At(i, i), ConstFloat64(1.0))
+    }
+    l, u, p, err := lu.Run(a)
+    if err != nil {
+      return nil, err
+    }
+    for j := 0; j < n; j++ {
+      if z, err := lu.Solve(l, u, p, x.Col(j)); err != nil {
+        return nil, err
+      } else {
+        ColView(y, j).Set(z)
+      }
+    }
+    t.SetFloat64(weights[k])
+    y.MmulS(y, t)
+    r.MaddM(r, y)
+  }
+  return r, nil
+}
+
+func logm(a Matrix, args ...interface{}) (Matrix, error) {
+  n, _ := a.Dims()
+  e := a.ElementType()
+
+  args = append(args, qrAlgorithm.ComputeU{Value: true})
+  h, u, err := qrAlgorithm.Run(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  for i := 0; i < n; i++ {
+    // remove rounding errors below the subdiagonal
+    for j := 0; j+1 < i; j++ {
+      h.At(i, j).Reset()
+    }
+    // check for zero eigenvalues in 1x1 blocks
+    if h.ConstAt(i, i).GetFloat64() == 0.0 {
+      if (i == 0 || h.ConstAt(i, i-1).GetFloat64() == 0.0) && (i == n-1 || h.ConstAt(i+1, i).GetFloat64() == 0.0) {
+        return nil, fmt.Errorf("matrix is singular")
+      }
+    }
+  }
+  // inverse scaling, take square roots until T is close to the identity
+  k := 0
+  x := NullDenseMatrix(e, n, n)
+  for ; ; k++ {
+    x.Set(h)
+    for i := 0; i < n; i++ {
+      x.At(i, i).Sub(x.At(i, i), ConstFloat64(1.0))
+    }
+    if matrixNorm.Norm1(x).GetFloat64() <= padeTheta {
+      break
+    }
+    if k == maxSquareRoots {
+      return nil, fmt.Errorf("inverse scaling did not converge")
+    }
+    if h, err = sqrtm.Run(h, sqrtm.QuasiTriangular{Value: true}); err != nil {
+      return nil, err
+    }
+  }
+  r, err := pade(x, padeDegree)
+  if err != nil {
+    return nil, err
+  }
+  // log(A) = 2^k U log(T) U^T
+  t := NullDenseMatrix(e, n, n)
+  t.MmulS(r, NewScalar(e, math.Pow(2.0, float64(k))))
+  r.MdotM(u, t)
+  t.MdotM(r, u.T())
+  return t, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the principal logarithm of a real matrix A with the inverse
+// scaling and squaring method. A is reduced to real Schur form
+// A = U T U^T with the QR algorithm, square roots of T are taken until T is
+// close to the identity and the logarithm is evaluated with a Padé
+// approximant. A must not have zero or negative real eigenvalues. All
+// options are passed to the QR algorithm.
+func Run(a Matrix, args_ ...interface{}) (Matrix, error) {
+  n, m := a.Dims()
+  if n != m {
+    return nil, fmt.Errorf("`a' must be a square matrix")
+  }
+  if n == 0 {
+    return nil, fmt.Errorf("`a' is an empty matrix")
+  }
+  // arguments passed on to the qrAlgorithm
+  var args []interface{}
+  // loop over optional arguments
+  for _, arg := range args_ {
+    switch arg.(type) {
+    case qrAlgorithm.ComputeU:
+      // drop this option
+    default:
+      args = append(args, arg)
+    }
+  }
+  return logm(a, args...)
+}
@@ -0,0 +1,105 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logm
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/expm"
+
+/* -------------------------------------------------------------------------- */
+
+func TestLogm1(test *testing.T) {
+  // the logarithm of a rotation by phi is [0 -phi; phi 0]
+  phi := 1.2
+  a := NewDenseFloat64Matrix([]float64{
+    math.Cos(phi), -math.Sin(phi),
+    math.Sin(phi),  math.Cos(phi) }, 2, 2)
+  b := NewDenseFloat64Matrix([]float64{
+    0, -phi,
+    phi,  0 }, 2, 2)
+  if x, err := Run(a); err != nil {
+    test.Error(err)
+  } else if !x.Equals(b, 1e-10) {
+    test.Error("test failed")
+  }
+}
+
+func TestLogm2(test *testing.T) {
+  // exp(log(A)) = A for a transition matrix with complex eigenvalues
+  a := NewDenseFloat64Matrix([]float64{
+    0.6, 0.3, 0.1, 0.0,
+    0.1, 0.6, 0.3, 0.0,
+    0.3, 0.1, 0.5, 0.1,
+    0.0, 0.1, 0.1, 0.8 }, 4, 4)
+  x, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if y, err := expm.Run(x); err != nil {
+    test.Error(err)
+  } else if !y.Equals(a, 1e-10) {
+    test.Error("test failed")
+  }
+}
+
+func TestLogm3(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2,
+    0, 0 }, 2, 2)
+  if _, err := Run(a); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestLogm4(test *testing.T) {
+  // d/dt log(exp(Q t)) = Q
+  t := NewReal64(0.8)
+  Variables(1, t)
+  q := NewDenseFloat64Matrix([]float64{
+    -1.0,  0.5,  0.5,
+     0.2, -0.4,  0.2,
+     0.1,  0.6, -0.7 }, 3, 3)
+  a := NullDenseReal64Matrix(3, 3)
+  a.MmulS(q, t)
+
+  p, err := expm.Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  x, err := Run(p)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  for i := 0; i < 3; i++ {
+    for j := 0; j < 3; j++ {
+      if math.Abs(x.At(i, j).GetFloat64() - 0.8*q.At(i, j).GetFloat64()) > 1e-10 {
+        test.Errorf("test failed for element (%d,%d)", i, j)
+      }
+      if math.Abs(x.At(i, j).GetDerivative(0) - q.At(i, j).GetFloat64()) > 1e-8 {
+        test.Errorf("test failed for derivative of element (%d,%d)", i, j)
+      }
+    }
+  }
+}
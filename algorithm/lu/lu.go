@@ -0,0 +1,273 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lu
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/condition"
+import   "github.com/pbenner/autodiff/algorithm/matrixNorm"
+
+/* -------------------------------------------------------------------------- */
+
+type InSitu struct {
+  L Matrix
+  U Matrix
+  P []int
+  T Scalar
+}
+
+/* -------------------------------------------------------------------------- */
+
+func lu(a ConstMatrix, L, U Matrix, p []int, t Scalar) (Matrix, Matrix, []int, error) {
+  n, _ := a.Dims()
+
+  U.Set(a)
+  L.SetIdentity()
+  for i := 0; i < n; i++ {
+    p[i] = i
+  }
+  for k := 0; k < n; k++ {
+    // find row with maximum value at column k
+    m := k
+    for i := k+1; i < n; i++ {
+      if math.Abs(U.At(i, k).GetFloat64()) > math.Abs(U.At(m, k).GetFloat64()) {
+        m = i
+      }
+    }
+    if m != k {
+      for j := k; j < n; j++ {
+        U.Swap(k, j, m, j)
+      }
+      for j := 0; j < k; j++ {
+        L.Swap(k, j, m, j)
+      }
+      p[k], p[m] = p[m], p[k]
+    }
+    if U.At(k, k).GetFloat64() == 0.0 {
+      // matrix is singular, nothing to eliminate
+      continue
+    }
+    // eliminate column k
+    for i := k+1; i < n; i++ {
+      l := L.At(i, k)
+      l.Div(U.At(i, k), U.At(k, k))
+      for j := k+1; j < n; j++ {
+        t.Mul(l, U.At(k, j))
+        U.At(i, j).Sub(U.At(i, j), t)
+      }
+      U.At(i, k).Reset()
+    }
+  }
+  return L, U, p, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the LU decomposition P A = L U with partial pivoting, where L is
+// a lower triangular matrix with unit diagonal and U an upper triangular
+// matrix. The permutation P is returned as a slice p, such that the ith row
+// of P A is the p[i]th row of A. Singular matrices are decomposed without
+// error, U has a zero on the diagonal in this case. The condition number
+// of A is estimated and checked if a condition.Threshold is passed.
+func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, []int, error) {
+  n, m := a.Dims()
+  if n != m {
+    panic("LU(): Not a square matrix!")
+  }
+  t      := a.ElementType()
+  inSitu := &InSitu{}
+
+  var threshold condition.Threshold
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case condition.Threshold:
+      threshold = a
+    case *InSitu:
+      inSitu = a
+    case InSitu:
+      panic("InSitu must be passed by reference")
+    default:
+      panic("LU(): Invalid optional argument!")
+    }
+  }
+  if inSitu.L == nil {
+    inSitu.L = NullDenseMatrix(t, n, n)
+  } else {
+    if u, v := inSitu.L.Dims(); u != n || v != n {
+      return nil, nil, nil, fmt.Errorf("L has invalid dimension (%dx%d instead of %dx%d)", u, v, n, n)
+    }
+  }
+  if inSitu.U == nil {
+    inSitu.U = NullDenseMatrix(t, n, n)
+  } else {
+    if u, v := inSitu.U.Dims(); u != n || v != n {
+      return nil, nil, nil, fmt.Errorf("U has invalid dimension (%dx%d instead of %dx%d)", u, v, n, n)
+    }
+  }
+  if inSitu.P == nil {
+    inSitu.P = make([]int, n)
+  } else {
+    if len(inSitu.P) != n {
+      return nil, nil, nil, fmt.Errorf("P has invalid length (%d instead of %d)", len(inSitu.P), n)
+    }
+  }
+  if inSitu.T == nil {
+    inSitu.T = NullScalar(t)
+  }
+  l, u, p, err := lu(a, inSitu.L, inSitu.U, inSitu.P, inSitu.T)
+  if err != nil {
+    return nil, nil, nil, err
+  }
+  if threshold.Value > 0.0 {
+    c, _ := Condition(a, l, u, p)
+    if err := threshold.Check(c); err != nil {
+      return nil, nil, nil, err
+    }
+  }
+  return l, u, p, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Solve A x = b given the LU decomposition of A.
+func Solve(l, u ConstMatrix, p []int, b ConstVector) (Vector, error) {
+  n, _ := u.Dims()
+  if b.Dim() != n || len(p) != n {
+    panic("vector dimensions do not match!")
+  }
+  x := NullDenseVector(u.ElementType(), n)
+  t := NullScalar(u.ElementType())
+  // forward substitution L y = P b
+  for i := 0; i < n; i++ {
+    s := x.At(i)
+    s.Set(b.ConstAt(p[i]))
+    for j := 0; j < i; j++ {
+      t.Mul(l.ConstAt(i, j), x.ConstAt(j))
+      s.Sub(s, t)
+    }
+  }
+  // back substitution U x = y
+  for i := n-1; i >= 0; i-- {
+    if u.ConstAt(i, i).GetFloat64() == 0.0 {
+      return nil, fmt.Errorf("matrix is singular")
+    }
+    s := x.At(i)
+    for j := i+1; j < n; j++ {
+      t.Mul(u.ConstAt(i, j), x.ConstAt(j))
+      s.Sub(s, t)
+    }
+    s.Div(s, u.ConstAt(i, i))
+  }
+  return x, nil
+}
+
+// Solve A^T x = b given the LU decomposition of A.
+func SolveT(l, u ConstMatrix, p []int, b ConstVector) (Vector, error) {
+  n, _ := u.Dims()
+  if b.Dim() != n || len(p) != n {
+    panic("vector dimensions do not match!")
+  }
+  y := NullDenseVector(u.ElementType(), n)
+  x := NullDenseVector(u.ElementType(), n)
+  t := NullScalar(u.ElementType())
+  // A^T = U^T L^T P, forward substitution U^T z = b
+  for i := 0; i < n; i++ {
+    if u.ConstAt(i, i).GetFloat64() == 0.0 {
+      return nil, fmt.Errorf("matrix is singular")
+    }
+    s := y.At(i)
+    s.Set(b.ConstAt(i))
+    for j := 0; j < i; j++ {
+      t.Mul(u.ConstAt(j, i), y.ConstAt(j))
+      s.Sub(s, t)
+    }
+    s.Div(s, u.ConstAt(i, i))
+  }
+  // back substitution L^T y = z
+  for i := n-1; i >= 0; i-- {
+    s := y.At(i)
+    for j := i+1; j < n; j++ {
+      t.Mul(l.ConstAt(j, i), y.ConstAt(j))
+      s.Sub(s, t)
+    }
+  }
+  // x = P^T y
+  for i := 0; i < n; i++ {
+    x.At(p[i]).Set(y.ConstAt(i))
+  }
+  return x, nil
+}
+
+// Estimate the 1-norm condition number of A given the LU decomposition of
+// A. Returns infinity if A is singular.
+func Condition(a, l, u ConstMatrix, p []int) (float64, error) {
+  n, _ := u.Dims()
+  solve := func(b ConstVector) (Vector, error) {
+    return Solve(l, u, p, b)
+  }
+  solveT := func(b ConstVector) (Vector, error) {
+    return SolveT(l, u, p, b)
+  }
+  return condition.Estimate1(matrixNorm.Norm1(a).GetFloat64(), n, solve, solveT)
+}
+
+// Compute the determinant of A given the LU decomposition of A.
+func Determinant(u ConstMatrix, p []int) Scalar {
+  n, _ := u.Dims()
+  r := NewScalar(u.ElementType(), 1.0)
+  for i := 0; i < n; i++ {
+    r.Mul(r, u.ConstAt(i, i))
+  }
+  // determine the sign of the permutation from its cycles
+  visited := make([]bool, len(p))
+  for i := 0; i < len(p); i++ {
+    if visited[i] {
+      continue
+    }
+    k := 0
+    for j := i; !visited[j]; j = p[j] {
+      visited[j] = true
+      k++
+    }
+    if k % 2 == 0 {
+      r.Neg(r)
+    }
+  }
+  return r
+}
+
+// Compute the inverse of A given the LU decomposition of A.
+func Inverse(l, u ConstMatrix, p []int) (Matrix, error) {
+  n, _ := u.Dims()
+  r := NullDenseMatrix(u.ElementType(), n, n)
+  e := NullDenseVector(u.ElementType(), n)
+  for j := 0; j < n; j++ {
+    e.At(j).SetFloat64(1.0)
+    x, err := Solve(l, u, p, e)
+    if err != nil {
+      return nil, err
+    }
+    ColView(r, j).Set(x)
+    e.At(j).SetFloat64(0.0)
+  }
+  return r, nil
+}
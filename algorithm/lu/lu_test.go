@@ -0,0 +1,170 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lu
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/condition"
+
+/* -------------------------------------------------------------------------- */
+
+func TestLU1(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2, 0,
+    3, 1, 4,
+    2, 5, 1 }, 3, 3)
+
+  l, u, p, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  // check P A = L U
+  b := a.CloneMatrix()
+  b.PermuteRows(p)
+  r := NullDenseFloat64Matrix(3, 3)
+  r.MdotM(l, u)
+
+  if !r.Equals(b, 1e-12) {
+    test.Error("test failed")
+  }
+  for i := 0; i < 3; i++ {
+    if l.At(i, i).GetFloat64() != 1.0 {
+      test.Error("test failed")
+    }
+    for j := 0; j < i; j++ {
+      if u.At(i, j).GetFloat64() != 0.0 || l.At(j, i).GetFloat64() != 0.0 {
+        test.Error("test failed")
+      }
+    }
+  }
+  if d := Determinant(u, p); math.Abs(d.GetFloat64() + 9) > 1e-12 {
+    test.Errorf("test failed: %v", d)
+  }
+  // solve for multiple right-hand sides
+  for _, x := range []ConstVector{
+    NewDenseFloat64Vector([]float64{1, 2, 3}),
+    NewDenseFloat64Vector([]float64{-1, 0, 1}) } {
+    y := NullDenseFloat64Vector(3)
+    y.MdotV(a, x)
+    if z, err := Solve(l, u, p, y); err != nil {
+      test.Error(err)
+    } else if !z.Equals(x, 1e-12) {
+      test.Error("test failed")
+    }
+  }
+  if ainv, err := Inverse(l, u, p); err != nil {
+    test.Error(err)
+  } else {
+    r.MdotM(a, ainv)
+    if !r.Equals(DenseIdentityMatrix(Float64Type, 3), 1e-12) {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestLU2(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    2, 4, 6,
+    1, 0, 1 }, 3, 3)
+
+  l, u, p, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if d := Determinant(u, p); math.Abs(d.GetFloat64()) > 1e-12 {
+    test.Error("test failed")
+  }
+  if _, err := Solve(l, u, p, NewDenseFloat64Vector([]float64{1, 2, 3})); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestLU3(test *testing.T) {
+  a := NewDenseReal64Matrix([]float64{
+    1, 2, 0,
+    3, 1, 4,
+    2, 5, 1 }, 3, 3)
+  a.Variables(1)
+
+  _, u, p, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  d := Determinant(u, p)
+  // the derivative of the determinant is the transposed
+  // adjugate of A
+  adj := NewDenseFloat64Matrix([]float64{
+    -19,  -2,   8,
+      5,   1,  -4,
+     13,  -1,  -5 }, 3, 3)
+
+  for i := 0; i < 3; i++ {
+    for j := 0; j < 3; j++ {
+      if math.Abs(d.GetDerivative(3*i+j) - adj.At(j, i).GetFloat64()) > 1e-10 {
+        test.Errorf("test failed for derivative (%d,%d)", i, j)
+      }
+    }
+  }
+}
+
+func TestLU4(test *testing.T) {
+  // condition number of the 3x3 Hilbert matrix is 748
+  a := NewDenseFloat64Matrix([]float64{
+    1.0/1, 1.0/2, 1.0/3,
+    1.0/2, 1.0/3, 1.0/4,
+    1.0/3, 1.0/4, 1.0/5 }, 3, 3)
+
+  l, u, p, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  if c, err := Condition(a, l, u, p); err != nil {
+    test.Error(err)
+  } else if math.Abs(c - 748) > 1e-8 {
+    test.Error("test failed")
+  }
+  // solve with the transposed matrix
+  b := NewDenseFloat64Matrix([]float64{
+    1, 2, 0,
+    3, 1, 4,
+    2, 5, 1 }, 3, 3)
+  l, u, p, _ = Run(b)
+  x := NewDenseFloat64Vector([]float64{1, -2, 3})
+  y := NullDenseFloat64Vector(3)
+  y.VdotM(x, b)
+  if z, err := SolveT(l, u, p, y); err != nil {
+    test.Error(err)
+  } else if !z.Equals(x, 1e-12) {
+    test.Error("test failed")
+  }
+  if _, _, _, err := Run(a, condition.Threshold{Value: 100}); err == nil {
+    test.Error("test failed")
+  }
+  if _, _, _, err := Run(a, condition.Threshold{Value: 1000}); err != nil {
+    test.Error(err)
+  }
+}
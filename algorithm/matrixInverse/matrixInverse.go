@@ -18,11 +18,13 @@ package matrixInverse
 
 /* -------------------------------------------------------------------------- */
 
-//import   "fmt"
+import   "fmt"
 
 import . "github.com/pbenner/autodiff"
 import   "github.com/pbenner/autodiff/algorithm/cholesky"
+import   "github.com/pbenner/autodiff/algorithm/condition"
 import   "github.com/pbenner/autodiff/algorithm/gaussJordan"
+import   "github.com/pbenner/autodiff/algorithm/matrixNorm"
 import   "github.com/pbenner/autodiff/algorithm/rprop"
 
 /* -------------------------------------------------------------------------- */
@@ -126,8 +128,112 @@ func mInverseUpperTriangular(matrix ConstMatrix, inSitu *InSitu, args ...interfa
   }
 }
 
+// Invert a lower triangular matrix by forward substitution. The result is
+// stored in r, which may be a LowerTriangularMatrix.
+func mInverseLowerTriangularPacked(a ConstMatrix, r Matrix, t Scalar) (Matrix, error) {
+  n, _ := a.Dims()
+  for j := 0; j < n; j++ {
+    if a.Float64At(j, j) == 0.0 {
+      return nil, fmt.Errorf("matrix is singular")
+    }
+    // solve a x = e_j, where x_i = 0 for all i < j
+    for i := j; i < n; i++ {
+      s := r.At(i, j)
+      if i == j {
+        s.SetFloat64(1.0)
+      } else {
+        s.Reset()
+      }
+      for k := j; k < i; k++ {
+        t.Mul(a.ConstAt(i, k), r.ConstAt(k, j))
+        s.Sub(s, t)
+      }
+      s.Div(s, a.ConstAt(i, i))
+    }
+  }
+  return r, nil
+}
+
+// Invert matrices with compact storage without converting them to dense
+// matrices. The inverse has the same structure as the original matrix.
+func mInversePacked(matrix ConstMatrix, inSitu *InSitu, positiveDefinite bool) (Matrix, error) {
+  n, _ := matrix.Dims()
+  t    := NullScalar(matrix.ElementType())
+  switch a := matrix.(type) {
+  case *DiagonalMatrix:
+    r, ok := inSitu.Id.(*DiagonalMatrix)
+    if !ok {
+      r = NullDiagonalMatrix(a.ElementType(), n)
+    }
+    for i := 0; i < n; i++ {
+      if a.Float64At(i, i) == 0.0 {
+        return nil, fmt.Errorf("matrix is singular")
+      }
+      r.At(i, i).SetFloat64(1.0)
+      r.At(i, i).Div(r.At(i, i), a.ConstAt(i, i))
+    }
+    return r, nil
+  case *LowerTriangularMatrix:
+    r, ok := inSitu.Id.(*LowerTriangularMatrix)
+    if !ok {
+      r = NullLowerTriangularMatrix(a.ElementType(), n)
+    }
+    return mInverseLowerTriangularPacked(a, r, t)
+  case *UpperTriangularMatrix:
+    r, ok := inSitu.Id.(*UpperTriangularMatrix)
+    if !ok {
+      r = NullUpperTriangularMatrix(a.ElementType(), n)
+    }
+    // the transpose of the inverse is the inverse of the transpose
+    if _, err := mInverseLowerTriangularPacked(a.T(), r.T(), t); err != nil {
+      return nil, err
+    }
+    return r, nil
+  case *SymmetricPackedMatrix:
+    if !positiveDefinite {
+      break
+    }
+    l, _, err := cholesky.Run(a, &inSitu.Cholesky)
+    if err != nil {
+      return nil, err
+    }
+    // A^-1 = L^-T L^-1
+    x := NullLowerTriangularMatrix(a.ElementType(), n)
+    if _, err := mInverseLowerTriangularPacked(l, x, t); err != nil {
+      return nil, err
+    }
+    r, ok := inSitu.Id.(*SymmetricPackedMatrix)
+    if !ok {
+      r = NullSymmetricPackedMatrix(a.ElementType(), n)
+    }
+    return r.MdotM(x.T(), x), nil
+  }
+  return nil, nil
+}
+
 /* -------------------------------------------------------------------------- */
 
+// Check the condition number ||A||_1 ||A^-1||_1 of the matrix given its
+// inverse r
+func checkCondition(matrix ConstMatrix, threshold condition.Threshold, r Matrix, err error) (Matrix, error) {
+  if err != nil || threshold.Value <= 0.0 {
+    return r, err
+  }
+  c := matrixNorm.Norm1(matrix).GetFloat64()*matrixNorm.Norm1(r).GetFloat64()
+  if err := threshold.Check(c); err != nil {
+    return nil, err
+  }
+  return r, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the inverse of a matrix. Diagonal and triangular matrices with
+// compact storage are inverted without conversion to dense matrices, the
+// result has the same type. A SymmetricPackedMatrix is inverted using
+// its Cholesky decomposition if PositiveDefinite is set. If a
+// condition.Threshold is passed, the 1-norm condition number of the matrix
+// is computed from the inverse and compared with the threshold.
 func Run(matrix ConstMatrix, args ...interface{}) (Matrix, error) {
   rows, cols := matrix.Dims()
   if rows != cols {
@@ -140,6 +246,8 @@ func Run(matrix ConstMatrix, args ...interface{}) (Matrix, error) {
   upperTriangular  := false
   inSitu           := &InSitu{}
 
+  var threshold condition.Threshold
+
   gArgs := []interface{}{}
 
   // loop over optional arguments
@@ -149,6 +257,8 @@ func Run(matrix ConstMatrix, args ...interface{}) (Matrix, error) {
       positiveDefinite = a.Value
     case UpperTriangular:
       upperTriangular = a.Value
+    case condition.Threshold:
+      threshold = a
     case *InSitu:
       inSitu = a
     case InSitu:
@@ -159,6 +269,9 @@ func Run(matrix ConstMatrix, args ...interface{}) (Matrix, error) {
       gArgs = append(gArgs, arg)
     }
   }
+  if r, err := mInversePacked(matrix, inSitu, positiveDefinite); r != nil || err != nil {
+    return checkCondition(matrix, threshold, r, err)
+  }
   if inSitu.Id == nil {
     inSitu.Id = NullDenseMatrix(matrix.ElementType(), rows, rows)
     inSitu.Id.SetIdentity()
@@ -180,12 +293,15 @@ func Run(matrix ConstMatrix, args ...interface{}) (Matrix, error) {
     inSitu.B = NullDenseVector(matrix.ElementType(), rows)
   }
   if positiveDefinite {
-    return mInversePositiveDefinite(matrix, inSitu, gArgs...)
+    r, err := mInversePositiveDefinite(matrix, inSitu, gArgs...)
+    return checkCondition(matrix, threshold, r, err)
   } else {
     if upperTriangular {
-      return mInverseUpperTriangular(matrix, inSitu, gArgs...)
+      r, err := mInverseUpperTriangular(matrix, inSitu, gArgs...)
+      return checkCondition(matrix, threshold, r, err)
     } else {
-      return mInverse(matrix, inSitu, gArgs...)
+      r, err := mInverse(matrix, inSitu, gArgs...)
+      return checkCondition(matrix, threshold, r, err)
     }
   }
 }
@@ -25,6 +25,7 @@ import   "math"
 
 import . "github.com/pbenner/autodiff"
 import   "github.com/pbenner/autodiff/algorithm/cholesky"
+import   "github.com/pbenner/autodiff/algorithm/condition"
 import   "github.com/pbenner/autodiff/algorithm/gaussJordan"
 
 /* -------------------------------------------------------------------------- */
@@ -76,6 +77,32 @@ func TestMatrixInversePD(test *testing.T) {
   }
 }
 
+func TestMatrixInversePacked(test *testing.T) {
+  m := NewDenseFloat64Matrix([]float64{
+    2, 1, 3,
+    1, 4, 2,
+    3, 2, 6 }, 3, 3)
+  for _, a := range []Matrix{
+    AsDiagonalMatrix       (Float64Type, m),
+    AsUpperTriangularMatrix(Float64Type, m),
+    AsLowerTriangularMatrix(Float64Type, m),
+    AsSymmetricPackedMatrix(Float64Type, m) } {
+    r, err := Run(a, PositiveDefinite{true})
+    if err != nil {
+      test.Error(err); continue
+    }
+    // result must have the same type as the input
+    if fmt.Sprintf("%T", r) != fmt.Sprintf("%T", a) {
+      test.Error("test failed")
+    }
+    x := NullDenseFloat64Matrix(3, 3)
+    x.MdotM(a, r)
+    if !x.Equals(DenseIdentityMatrix(Float64Type, 3), 1e-8) {
+      test.Error("test failed")
+    }
+  }
+}
+
 func TestMatrixPerformance(test *testing.T) {
 
   kernelSquaredExponential := func(sigma Matrix, l, v float64) Matrix {
@@ -127,3 +154,21 @@ func TestMatrixPerformance(test *testing.T) {
   fmt.Printf("Inverting a 100x100 positive definite matrix (type DenseFloat64Matrix) took %s.\n", elapsed)
 
 }
+
+func TestMatrixInverseCondition(test *testing.T) {
+  // condition number of the 3x3 Hilbert matrix is 748
+  a := NewDenseFloat64Matrix([]float64{
+    1.0/1, 1.0/2, 1.0/3,
+    1.0/2, 1.0/3, 1.0/4,
+    1.0/3, 1.0/4, 1.0/5 }, 3, 3)
+
+  if _, err := Run(a, condition.Threshold{Value: 100}); err == nil {
+    test.Error("test failed")
+  }
+  if _, err := Run(a, PositiveDefinite{true}, condition.Threshold{Value: 100}); err == nil {
+    test.Error("test failed")
+  }
+  if _, err := Run(a, condition.Threshold{Value: 1000}); err != nil {
+    test.Error(err)
+  }
+}
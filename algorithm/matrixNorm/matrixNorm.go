@@ -0,0 +1,112 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package matrixNorm
+
+/* -------------------------------------------------------------------------- */
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/svd"
+
+/* -------------------------------------------------------------------------- */
+
+// Returns the largest sum of absolute values over all rows (transpose
+// false) or columns (transpose true)
+func maxAbsSum(a ConstMatrix, transpose bool) Scalar {
+  n, m := a.Dims()
+  if transpose {
+    n, m = m, n
+  }
+  r := NullScalar(a.ElementType())
+  s := NullScalar(a.ElementType())
+  t := NullScalar(a.ElementType())
+  for i := 0; i < n; i++ {
+    s.Reset()
+    for j := 0; j < m; j++ {
+      if transpose {
+        t.Abs(a.ConstAt(j, i))
+      } else {
+        t.Abs(a.ConstAt(i, j))
+      }
+      s.Add(s, t)
+    }
+    if i == 0 || s.GetFloat64() > r.GetFloat64() {
+      r.Set(s)
+    }
+  }
+  return r
+}
+
+// Returns the absolute singular values of a
+func singularValues(a ConstMatrix) ([]Scalar, error) {
+  m, n := a.Dims()
+  b := NullDenseMatrix(a.ElementType(), m, n)
+  b.Set(a)
+  if m < n {
+    b = b.T()
+    m, n = n, m
+  }
+  h, _, _, err := svd.Run(b)
+  if err != nil {
+    return nil, err
+  }
+  r := make([]Scalar, n)
+  for i := 0; i < n; i++ {
+    r[i] = NullScalar(a.ElementType())
+    r[i].Abs(h.ConstAt(i, i))
+  }
+  return r, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Induced 1-norm, i.e. the maximum absolute column sum of a.
+func Norm1(a ConstMatrix) Scalar {
+  return maxAbsSum(a, true)
+}
+
+// Induced infinity-norm, i.e. the maximum absolute row sum of a.
+func NormInf(a ConstMatrix) Scalar {
+  return maxAbsSum(a, false)
+}
+
+// Induced 2-norm (spectral norm), i.e. the largest singular value of a.
+func Norm2(a ConstMatrix) (Scalar, error) {
+  s, err := singularValues(a)
+  if err != nil {
+    return nil, err
+  }
+  r := NullScalar(a.ElementType())
+  for i := 0; i < len(s); i++ {
+    if i == 0 || s[i].GetFloat64() > r.GetFloat64() {
+      r.Set(s[i])
+    }
+  }
+  return r, nil
+}
+
+// Nuclear norm (trace norm), i.e. the sum of all singular values of a.
+func Nuclear(a ConstMatrix) (Scalar, error) {
+  s, err := singularValues(a)
+  if err != nil {
+    return nil, err
+  }
+  r := NullScalar(a.ElementType())
+  for i := 0; i < len(s); i++ {
+    r.Add(r, s[i])
+  }
+  return r, nil
+}
@@ -0,0 +1,86 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package matrixNorm
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestMatrixNorm1(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, -2,  3,
+   -4,  5, -6 }, 2, 3)
+
+  if r := Norm1(a); r.GetFloat64() != 9 {
+    test.Error("test failed")
+  }
+  if r := NormInf(a); r.GetFloat64() != 15 {
+    test.Error("test failed")
+  }
+  // singular values of a are the square roots of the eigenvalues of
+  // A A^T = [14 -32; -32 77]
+  l1 := (91 + math.Sqrt(91*91 - 4*(14*77 - 32*32)))/2
+  l2 := (91 - math.Sqrt(91*91 - 4*(14*77 - 32*32)))/2
+  if r, err := Norm2(a); err != nil {
+    test.Error(err)
+  } else if math.Abs(r.GetFloat64() - math.Sqrt(l1)) > 1e-10 {
+    test.Error("test failed")
+  }
+  if r, err := Nuclear(a); err != nil {
+    test.Error(err)
+  } else if math.Abs(r.GetFloat64() - math.Sqrt(l1) - math.Sqrt(l2)) > 1e-10 {
+    test.Error("test failed")
+  }
+}
+
+func TestMatrixNorm2(test *testing.T) {
+  a := NewDenseReal64Matrix([]float64{
+    1, -2,
+    3,  4 }, 2, 2)
+  a.Variables(1)
+
+  // derivative of the largest absolute column sum |a_12| + |a_22|
+  r := Norm1(a)
+  if r.GetFloat64() != 6 || r.GetDerivative(1) != -1 || r.GetDerivative(3) != 1 || r.GetDerivative(0) != 0 {
+    test.Error("test failed")
+  }
+  // compare derivatives of the nuclear norm with finite differences
+  s, err := Nuclear(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  v := []float64{1, -2, 3, 4}
+  h := 1e-6
+  for k := 0; k < 4; k++ {
+    v1 := append([]float64{}, v...)
+    v2 := append([]float64{}, v...)
+    v1[k] += h
+    v2[k] -= h
+    s1, _ := Nuclear(NewDenseFloat64Matrix(v1, 2, 2))
+    s2, _ := Nuclear(NewDenseFloat64Matrix(v2, 2, 2))
+    if d := (s1.GetFloat64() - s2.GetFloat64())/(2*h); math.Abs(d - s.GetDerivative(k)) > 1e-6 {
+      test.Errorf("test failed for derivative %d", k)
+    }
+  }
+}
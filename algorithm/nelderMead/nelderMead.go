@@ -0,0 +1,452 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// References:
+// Nelder, John A., and Roger Mead. A simplex method for function
+// minimization. The computer journal 7.4 (1965): 308-313.
+// Gao, Fuchang, and Lixing Han. Implementing the Nelder-Mead simplex
+// algorithm with adaptive parameters. Computational Optimization and
+// Applications 51.1 (2012): 259-277.
+
+/* -------------------------------------------------------------------------- */
+
+package nelderMead
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "math/rand"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+// Objective function, which does not have to be differentiable
+type Objective func(ConstVector) (ConstScalar, error)
+
+type Epsilon struct {
+  Value float64
+}
+
+// Maximum number of iterations of each run
+type MaxIterations struct {
+  Value int
+}
+
+// Size of the initial simplex. If zero, each coordinate is perturbed by 5%
+// of its value or by 0.00025 if it is zero.
+type Step struct {
+  Value float64
+}
+
+// Lower and upper bounds for each coordinate, see optimize.Bounds
+type Bounds = optimize.Bounds
+
+// Number of restarts with a new simplex at the best position
+type Restarts struct {
+  Value int
+}
+
+// Seed of the random number generator, which determines the orientation of
+// the simplex after a restart
+type Seed struct {
+  Value int64
+}
+
+type Hook struct {
+  Value func(x ConstVector, y ConstScalar) bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+type vertex struct {
+  x DenseFloat64Vector
+  y float64
+}
+
+type simplex []vertex
+
+func (s simplex) Len() int {
+  return len(s)
+}
+
+func (s simplex) Less(i, j int) bool {
+  return s[i].y < s[j].y
+}
+
+func (s simplex) Swap(i, j int) {
+  s[i], s[j] = s[j], s[i]
+}
+
+// maximum distance of a vertex to the best vertex
+func (s simplex) size() float64 {
+  r := 0.0
+  for i := 1; i < len(s); i++ {
+    for j := 0; j < s[0].x.Dim(); j++ {
+      r = math.Max(r, math.Abs(s[i].x[j] - s[0].x[j]))
+    }
+  }
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+type nelderMead struct {
+  f          Objective
+  bounds     Bounds
+  // total number of iterations, convergence of the last run and the
+  // value at the best vertex
+  iterations int
+  converged  bool
+  value      float64
+}
+
+// evaluate objective function at the projection of x, NaN values are
+// replaced by +Inf
+func (obj nelderMead) eval(x DenseFloat64Vector) (vertex, error) {
+  obj.bounds.Project(x)
+  y, err := obj.f(x)
+  if err != nil {
+    return vertex{}, err
+  }
+  if v := y.GetFloat64(); math.IsNaN(v) {
+    return vertex{x, math.Inf(1)}, nil
+  } else {
+    return vertex{x, v}, nil
+  }
+}
+
+// returns c + t (x - c)
+func (obj nelderMead) point(c, x DenseFloat64Vector, t float64) (vertex, error) {
+  r := NullDenseFloat64Vector(c.Dim())
+  for i := 0; i < c.Dim(); i++ {
+    r[i] = c[i] + t*(x[i] - c[i])
+  }
+  return obj.eval(r)
+}
+
+// construct the initial simplex at x0, where the edges are given by the
+// columns of the orthonormal matrix q
+func (obj nelderMead) init(x0 DenseFloat64Vector, q [][]float64, step float64) (simplex, error) {
+  n := x0.Dim()
+  s := make(simplex, n+1)
+  if v, err := obj.eval(x0.Clone()); err != nil {
+    return nil, err
+  } else {
+    s[0] = v
+  }
+  for i := 0; i < n; i++ {
+    x := x0.Clone()
+    for j := 0; j < n; j++ {
+      h := step
+      if h == 0.0 {
+        if x0[j] != 0.0 {
+          h = 0.05*math.Abs(x0[j])
+        } else {
+          h = 0.00025
+        }
+      }
+      h *= q[j][i]
+      // move away from bounds
+      if x[j] + h > obj.bounds.UpperBound(j) || x[j] + h < obj.bounds.LowerBound(j) {
+        h = -h
+      }
+      x[j] += h
+    }
+    if v, err := obj.eval(x); err != nil {
+      return nil, err
+    } else {
+      s[i+1] = v
+    }
+  }
+  return s, nil
+}
+
+func (obj *nelderMead) run(s simplex, epsilon Epsilon, maxIterations MaxIterations, hook Hook) (simplex, error) {
+  n := len(s)-1
+  // adaptive parameters for reflection, expansion, contraction and
+  // shrinkage
+  alpha := 1.0
+  beta  := 1.0 + 2.0/float64(n)
+  gamma := 0.75 - 1.0/(2.0*float64(n))
+  delta := 1.0 - 1.0/float64(n)
+  if n == 1 {
+    beta  = 2.0
+    gamma = 0.5
+    delta = 0.5
+  }
+  c := NullDenseFloat64Vector(n)
+
+  for i := 0; i < maxIterations.Value; i++ {
+    sort.Stable(s)
+    // execute hook if available
+    if hook.Value != nil && hook.Value(s[0].x, ConstFloat64(s[0].y)) {
+      break
+    }
+    // evaluate stop criterion
+    if s.size() < epsilon.Value {
+      obj.converged = true
+      break
+    }
+    obj.iterations++
+    // centroid of all vertices except the worst
+    c.Reset()
+    for j := 0; j < n; j++ {
+      for k := 0; k < n; k++ {
+        c[k] += s[j].x[k]/float64(n)
+      }
+    }
+    // reflection
+    vr, err := obj.point(c, s[n].x, -alpha)
+    if err != nil {
+      return s, err
+    }
+    switch {
+    case vr.y < s[0].y:
+      // expansion
+      ve, err := obj.point(c, s[n].x, -alpha*beta)
+      if err != nil {
+        return s, err
+      }
+      if ve.y < vr.y {
+        s[n] = ve
+      } else {
+        s[n] = vr
+      }
+      continue
+    case vr.y < s[n-1].y:
+      s[n] = vr
+      continue
+    case vr.y < s[n].y:
+      // outside contraction
+      vc, err := obj.point(c, vr.x, gamma)
+      if err != nil {
+        return s, err
+      }
+      if vc.y <= vr.y {
+        s[n] = vc
+        continue
+      }
+    default:
+      // inside contraction
+      vc, err := obj.point(c, s[n].x, gamma)
+      if err != nil {
+        return s, err
+      }
+      if vc.y < s[n].y {
+        s[n] = vc
+        continue
+      }
+    }
+    // shrink simplex towards the best vertex
+    for j := 1; j <= n; j++ {
+      if s[j], err = obj.point(s[0].x, s[j].x, delta); err != nil {
+        return s, err
+      }
+    }
+  }
+  sort.Stable(s)
+  return s, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// random orthonormal matrix computed with the Gram-Schmidt process
+func randomRotation(r *rand.Rand, n int) [][]float64 {
+  q := make([][]float64, n)
+  for i := 0; i < n; i++ {
+    q[i] = make([]float64, n)
+  }
+  for j := 0; j < n; j++ {
+    for {
+      for i := 0; i < n; i++ {
+        q[i][j] = r.NormFloat64()
+      }
+      for k := 0; k < j; k++ {
+        t := 0.0
+        for i := 0; i < n; i++ {
+          t += q[i][j]*q[i][k]
+        }
+        for i := 0; i < n; i++ {
+          q[i][j] -= t*q[i][k]
+        }
+      }
+      t := 0.0
+      for i := 0; i < n; i++ {
+        t += q[i][j]*q[i][j]
+      }
+      if t = math.Sqrt(t); t > 1e-8 {
+        for i := 0; i < n; i++ {
+          q[i][j] /= t
+        }
+        break
+      }
+    }
+  }
+  return q
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f with the Nelder-Mead simplex method starting at x0. The
+// method uses only function values, hence f may be non-differentiable or
+// noisy. The expansion, contraction and shrinkage parameters are adapted
+// to the dimension of the problem. Optional arguments:
+//
+//   Epsilon      : stop if the maximum distance between the best vertex and
+//                  all other vertices is smaller than epsilon (default 1e-8)
+//   MaxIterations: maximum number of iterations of each run (default 200n)
+//   Step         : size of the initial simplex
+//   Bounds       : lower and upper bounds, vertices are projected onto the
+//                  feasible box
+//   Restarts     : number of restarts with a new randomly rotated simplex
+//                  at the best position, which helps if the simplex has
+//                  collapsed prematurely (default 0)
+//   Seed         : seed of the random number generator used for restarts
+//   Hook         : called at every iteration with the best vertex, stop if
+//                  it returns true
+func Run(f Objective, x0 ConstVector, args ...interface{}) (Vector, error) {
+  x, _, err := minimize(f, x0, args...)
+  return x, err
+}
+
+func minimize(f Objective, x0 ConstVector, args ...interface{}) (Vector, *nelderMead, error) {
+
+  n := x0.Dim()
+
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{200*n}
+  step          := Step         {0.0}
+  bounds        := Bounds       {}
+  restarts      := Restarts     {0}
+  seed          := Seed         {0}
+  hook          := Hook         {nil}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Step:
+      step = a
+    case Bounds:
+      bounds = a
+    case Restarts:
+      restarts = a
+    case Seed:
+      seed = a
+    case Hook:
+      hook = a
+    default:
+      panic("NelderMead(): Invalid optional argument!")
+    }
+  }
+  if n == 0 {
+    return nil, nil, fmt.Errorf("invalid dimension")
+  }
+  if err := bounds.Check(n); err != nil {
+    return nil, nil, err
+  }
+  obj := &nelderMead{f: f, bounds: bounds}
+  rng := rand.New(rand.NewSource(seed.Value))
+  // the initial simplex is aligned with the coordinate axes
+  q := make([][]float64, n)
+  for i := 0; i < n; i++ {
+    q[i] = make([]float64, n)
+    q[i][i] = 1.0
+  }
+  x := AsDenseFloat64Vector(x0)
+  bounds.Project(x)
+
+  s, err := obj.init(x, q, step.Value)
+  if err != nil {
+    return x, obj, fmt.Errorf("invalid initial value: %s", err)
+  }
+  if math.IsInf(s[0].y, 1) {
+    return x, obj, fmt.Errorf("invalid initial value: %v", x)
+  }
+  stop := false
+  for k := 0; k <= restarts.Value && !stop; k++ {
+    if k > 0 {
+      if s, err = obj.init(s[0].x, randomRotation(rng, n), step.Value); err != nil {
+        return x, obj, err
+      }
+    }
+    // stop all runs if the hook returns true
+    h := hook
+    if hook.Value != nil {
+      h.Value = func(x ConstVector, y ConstScalar) bool {
+        stop = hook.Value(x, y)
+        return stop
+      }
+    }
+    obj.converged = false
+    if s, err = obj.run(s, epsilon, maxIterations, h); err != nil {
+      return s[0].x, obj, err
+    }
+  }
+  obj.value = s[0].y
+  return s[0].x, obj, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. The status refers to the last run if restarts
+// are used. Accepts the same optional arguments as Run and additionally
+// optimize.Trace.
+func Minimize(f Objective, x0 ConstVector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook    := Hook{nil}
+  options := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (ConstScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // the hook is called at every iteration with the best vertex
+  options = append(options, Hook{func(x ConstVector, y ConstScalar) bool {
+    r.Iteration(x, y.GetFloat64(), math.NaN())
+    return hook.Value != nil && r.Hook(hook.Value(x, y))
+  }})
+  x, obj, err := minimize(g, x0, options...)
+  if obj == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  r.SetIterations(obj.iterations)
+  switch {
+  case err != nil:
+    return r.Result(x, math.NaN(), math.NaN(), optimize.Failed), err
+  case obj.converged:
+    return r.Result(x, obj.value, math.NaN(), optimize.Converged), nil
+  default:
+    return r.Result(x, obj.value, math.NaN(), optimize.MaxIterationsReached), nil
+  }
+}
@@ -0,0 +1,138 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nelderMead
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+func TestNelderMeadRosenbrock(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    // f(x1, x2) = (a - x1)^2 + b(x2 - x1^2)^2
+    // a = 1
+    // b = 100
+    // minimum: (x1,x2) = (a, a^2)
+    x1 := x.Float64At(0)
+    x2 := x.Float64At(1)
+    return ConstFloat64((1.0 - x1)*(1.0 - x1) + 100.0*(x2 - x1*x1)*(x2 - x1*x1)), nil
+  }
+  t  := NewFloat64(0.0)
+  x0 := NewDenseFloat64Vector([]float64{-0.5, 2})
+  xr := NewDenseFloat64Vector([]float64{   1, 1})
+  xn, err := Run(f, x0, Epsilon{1e-10}, MaxIterations{1000})
+  if err != nil {
+    test.Error(err)
+  }
+  if t.Vnorm(xn.VsubV(xn, xr)).GetFloat64() > 1e-8 {
+    test.Error("Nelder-Mead Rosenbrock test failed!")
+  }
+}
+
+func TestNelderMeadNonDifferentiable(test *testing.T) {
+
+  // f(x) = max_i |x_i - i|
+  f := func(x ConstVector) (ConstScalar, error) {
+    r := 0.0
+    for i := 0; i < x.Dim(); i++ {
+      r = math.Max(r, math.Abs(x.Float64At(i) - float64(i)))
+    }
+    return ConstFloat64(r), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{1, 1, 1, 1})
+  xn, err := Run(f, x0, Step{1.0}, Restarts{5}, Seed{1})
+  if err != nil {
+    test.Error(err)
+  }
+  for i := 0; i < xn.Dim(); i++ {
+    if math.Abs(xn.Float64At(i) - float64(i)) > 1e-6 {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestNelderMeadBounds(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    x1 := x.Float64At(0)
+    x2 := x.Float64At(1)
+    return ConstFloat64((x1 - 2.0)*(x1 - 2.0) + (x2 + 2.0)*(x2 + 2.0)), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{0, 0})
+  xn, err := Run(f, x0, Bounds{Lower: []float64{-1, -1}, Upper: []float64{1, 1}}, Restarts{1})
+  if err != nil {
+    test.Error(err)
+  }
+  if math.Abs(xn.Float64At(0) - 1.0) > 1e-8 || math.Abs(xn.Float64At(1) + 1.0) > 1e-8 {
+    test.Error("test failed")
+  }
+  if _, err := Run(f, x0, Bounds{Lower: []float64{-1}}); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestNelderMeadSeed(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    r := 0.0
+    for i := 0; i < x.Dim(); i++ {
+      r += math.Abs(x.Float64At(i))
+    }
+    return ConstFloat64(r), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{1, 2, 3})
+  x1, err1 := Run(f, x0, Restarts{3}, Seed{42}, MaxIterations{50})
+  x2, err2 := Run(f, x0, Restarts{3}, Seed{42}, MaxIterations{50})
+  if err1 != nil || err2 != nil {
+    test.Error("test failed")
+  }
+  for i := 0; i < x1.Dim(); i++ {
+    if x1.Float64At(i) != x2.Float64At(i) {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestNelderMeadMinimize(test *testing.T) {
+
+  f := func(x ConstVector) (ConstScalar, error) {
+    x1 := x.Float64At(0)
+    x2 := x.Float64At(1)
+    return ConstFloat64((x1 - 1.0)*(x1 - 1.0) + (x2 - 2.0)*(x2 - 2.0)), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{0, 0})
+  r, err := Minimize(f, x0, Epsilon{1e-10}, optimize.Trace{Value: true})
+  if err != nil {
+    test.Error(err)
+  }
+  if r.Status != optimize.Converged || r.Value > 1e-16 || !math.IsNaN(r.GradientNorm) {
+    test.Error("test failed")
+  }
+  if r.Iterations == 0 || r.Evaluations <= r.Iterations || len(r.Trace) == 0 {
+    test.Error("test failed")
+  }
+  if r, _ := Minimize(f, x0, MaxIterations{5}); r.Status != optimize.MaxIterationsReached {
+    test.Error("test failed")
+  }
+}
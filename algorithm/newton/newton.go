@@ -25,6 +25,7 @@ import . "github.com/pbenner/autodiff"
 import   "github.com/pbenner/autodiff/algorithm/matrixInverse"
 import   "github.com/pbenner/autodiff/algorithm/cholesky"
 import   "github.com/pbenner/autodiff/algorithm/lineSearch"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
 
 /* -------------------------------------------------------------------------- */
@@ -60,6 +61,10 @@ type MaxIterations struct {
   Value int
 }
 
+// Returned if the line search fails to find a new position. The position
+// of the last iteration is returned together with this error.
+var ErrLineSearch = fmt.Errorf("line search failed")
+
 type InSitu struct {
   T1 Vector
   T2 Scalar
@@ -194,7 +199,7 @@ func newton_root(f objective_root, x ConstVector,
     for {
       x2.VsubV(x1, t1)
       if Vequals(x1, x2) {
-        return x1, fmt.Errorf("line search failed")
+        return x1, ErrLineSearch
       }
       // check constraints
       if constraints.Value == nil || constraints.Value(x2) {
@@ -307,7 +312,7 @@ func newton_min(
       for {
         x2.VsubV(x1, t1)
         if Vequals(x1, x2) {
-          return x1, fmt.Errorf("line search failed")
+          return x1, ErrLineSearch
         }
         // check constraints
         if constraints.Value == nil || constraints.Value(x2) {
@@ -509,3 +514,68 @@ func RunMin(f_ func(ConstVector) (MagicScalar, error), x ConstVector, args ...in
   }
   return run_min(f, x, getPhi, args...)
 }
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x and return the solution together with
+// convergence diagnostics. Accepts the same optional arguments as RunMin and
+// additionally optimize.Trace.
+func Minimize(f_ func(ConstVector) (MagicScalar, error), x ConstVector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook          := HookMin      { nil}
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  options       := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case HookMin:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    case MaxIterations:
+      maxIterations = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f_(x)
+  }
+  // the hook is called at the beginning of every iteration
+  first := true
+  options = append(options, HookMin{func(x, g ConstVector, H ConstMatrix, y ConstScalar) bool {
+    if first {
+      r.Init(x, y.GetFloat64(), optimize.Norm(g)); first = false
+    } else {
+      r.Iteration(x, y.GetFloat64(), optimize.Norm(g))
+    }
+    return hook.Value != nil && r.Hook(hook.Value(x, g, H, y))
+  }})
+  xn, err := RunMin(g, x, options...)
+  if xn == nil {
+    return r.Result(x, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  y, gnorm, e := optimize.Evaluate(f_, xn)
+  switch {
+  case err == ErrLineSearch:
+    return r.Result(xn, y, gnorm, optimize.Stalled), nil
+  case err != nil:
+    return r.Result(xn, y, gnorm, optimize.Failed), err
+  case e != nil:
+    return r.Result(xn, y, gnorm, optimize.Failed), e
+  case gnorm < epsilon.Value:
+    return r.Result(xn, y, gnorm, optimize.Converged), nil
+  default:
+    // the hook is not called after the last iteration
+    if !r.Stopped() {
+      r.SetIterations(maxIterations.Value)
+    }
+    return r.Result(xn, y, gnorm, optimize.MaxIterationsReached), nil
+  }
+}
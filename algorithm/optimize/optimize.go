@@ -0,0 +1,203 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package optimize
+
+/* -------------------------------------------------------------------------- */
+
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// Reason for the termination of an optimization algorithm
+type Status int
+
+const (
+  // stop criterion of the algorithm is satisfied
+  Converged Status = iota
+  // maximum number of iterations reached before convergence
+  MaxIterationsReached
+  // no further progress possible, e.g. the line search failed
+  Stalled
+  // stopped by the hook
+  HookStopped
+  // stopped because of an error, e.g. invalid function values
+  Failed
+)
+
+func (s Status) String() string {
+  switch s {
+  case Converged:
+    return "converged"
+  case MaxIterationsReached:
+    return "maximum number of iterations reached"
+  case Stalled:
+    return "stalled"
+  case HookStopped:
+    return "stopped by hook"
+  case Failed:
+    return "failed"
+  default:
+    return "unknown"
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// State of an optimization algorithm after an iteration
+type Iteration struct {
+  X            Vector
+  Value        float64
+  // NaN if not available
+  GradientNorm float64
+}
+
+type Result struct {
+  // final position and the value of the objective function at X
+  X            Vector
+  Value        float64
+  // norm of the gradient at X, NaN if the algorithm does not use
+  // gradients
+  GradientNorm float64
+  // number of iterations
+  Iterations   int
+  // number of evaluations of the objective function (with automatic
+  // differentiation an evaluation also computes derivatives)
+  Evaluations  int
+  Status       Status
+  // state after every iteration (only if the Trace option is set)
+  Trace        []Iteration
+}
+
+// Optional argument for recording the state of an algorithm after every
+// iteration
+type Trace struct {
+  Value bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Recorder collects the information for a Result while an algorithm is
+// running. It is used by the Minimize functions of all algorithms.
+type Recorder struct {
+  trace       bool
+  stopped     bool
+  iterations  int
+  evaluations int
+  states      []Iteration
+}
+
+// Create a new recorder. The Trace option is removed from args.
+func NewRecorder(args []interface{}) (*Recorder, []interface{}) {
+  r := Recorder{}
+  a := []interface{}{}
+  for _, arg := range args {
+    switch t := arg.(type) {
+    case Trace:
+      r.trace = t.Value
+    default:
+      a = append(a, arg)
+    }
+  }
+  return &r, a
+}
+
+// Count an evaluation of the objective function
+func (r *Recorder) Evaluation() {
+  r.evaluations++
+}
+
+// Record the initial state of the algorithm without counting an iteration
+func (r *Recorder) Init(x ConstVector, y, gnorm float64) {
+  if r.trace {
+    r.states = append(r.states, Iteration{AsDenseFloat64Vector(x), y, gnorm})
+  }
+}
+
+// Record the state of the algorithm after an iteration
+func (r *Recorder) Iteration(x ConstVector, y, gnorm float64) {
+  r.iterations++
+  if r.trace {
+    r.states = append(r.states, Iteration{AsDenseFloat64Vector(x), y, gnorm})
+  }
+}
+
+// Register the return value of a hook
+func (r *Recorder) Hook(stop bool) bool {
+  r.stopped = r.stopped || stop
+  return stop
+}
+
+// Returns true if a hook requested to stop the algorithm
+func (r *Recorder) Stopped() bool {
+  return r.stopped
+}
+
+func (r *Recorder) Iterations() int {
+  return r.iterations
+}
+
+// Overwrite the number of iterations, i.e. if the algorithm does not call
+// its hook after every iteration
+func (r *Recorder) SetIterations(n int) {
+  r.iterations = n
+}
+
+// Create the final result. The status is HookStopped if a hook requested to
+// stop the algorithm, otherwise the given status is used.
+func (r *Recorder) Result(x ConstVector, y, gnorm float64, status Status) Result {
+  if r.stopped {
+    status = HookStopped
+  }
+  return Result{
+    X           : AsDenseFloat64Vector(x),
+    Value       : y,
+    GradientNorm: gnorm,
+    Iterations  : r.iterations,
+    Evaluations : r.evaluations,
+    Status      : status,
+    Trace       : r.states }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Evaluate f at x, returns the function value and the norm of the gradient
+func Evaluate(f func(ConstVector) (MagicScalar, error), x ConstVector) (float64, float64, error) {
+  X := AsDenseReal64Vector(x)
+  if err := X.Variables(1); err != nil {
+    return math.NaN(), math.NaN(), err
+  }
+  y, err := f(X)
+  if err != nil {
+    return math.NaN(), math.NaN(), err
+  }
+  g := NullDenseFloat64Vector(x.Dim())
+  for i := 0; i < y.GetN(); i++ {
+    g[i] = y.GetDerivative(i)
+  }
+  return y.GetFloat64(), Norm(g), nil
+}
+
+// Euclidean norm of a vector
+func Norm(x ConstVector) float64 {
+  r := 0.0
+  for i := 0; i < x.Dim(); i++ {
+    r += x.Float64At(i)*x.Float64At(i)
+  }
+  return math.Sqrt(r)
+}
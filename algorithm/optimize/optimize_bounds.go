@@ -0,0 +1,84 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+package optimize
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// Lower and upper bounds for each coordinate. A nil slice or infinite
+// values disable the respective bounds.
+type Bounds struct {
+  Lower []float64
+  Upper []float64
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns true if at least one of the bounds is given
+func (b Bounds) IsSet() bool {
+  return b.Lower != nil || b.Upper != nil
+}
+
+// Lower bound of coordinate i
+func (b Bounds) LowerBound(i int) float64 {
+  if b.Lower == nil {
+    return math.Inf(-1)
+  }
+  return b.Lower[i]
+}
+
+// Upper bound of coordinate i
+func (b Bounds) UpperBound(i int) float64 {
+  if b.Upper == nil {
+    return math.Inf(1)
+  }
+  return b.Upper[i]
+}
+
+// Check that the bounds are valid for a vector of dimension n
+func (b Bounds) Check(n int) error {
+  if b.Lower != nil && len(b.Lower) != n {
+    return fmt.Errorf("lower bounds have invalid length (%d instead of %d)", len(b.Lower), n)
+  }
+  if b.Upper != nil && len(b.Upper) != n {
+    return fmt.Errorf("upper bounds have invalid length (%d instead of %d)", len(b.Upper), n)
+  }
+  for i := 0; i < n; i++ {
+    if b.LowerBound(i) > b.UpperBound(i) {
+      return fmt.Errorf("lower bound exceeds upper bound at coordinate %d", i)
+    }
+  }
+  return nil
+}
+
+// Project x onto the feasible box
+func (b Bounds) Project(x Vector) {
+  for i := 0; i < x.Dim(); i++ {
+    if v := x.At(i).GetFloat64(); v < b.LowerBound(i) {
+      x.At(i).SetFloat64(b.LowerBound(i))
+    } else if v > b.UpperBound(i) {
+      x.At(i).SetFloat64(b.UpperBound(i))
+    }
+  }
+}
@@ -0,0 +1,93 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package optimize
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestRecorder(test *testing.T) {
+  r, args := NewRecorder([]interface{}{1, Trace{true}, "a"})
+  if len(args) != 2 {
+    test.Error("test failed")
+  }
+  x := NewDenseFloat64Vector([]float64{1, 2})
+  r.Init(x, 3.0, 1.0)
+  r.Evaluation()
+  r.Iteration(x, 2.0, 0.5)
+  r.Evaluation()
+  // modifying x must not change the trace
+  x[0] = 0.0
+
+  s := r.Result(x, 2.0, 0.5, Converged)
+  if s.Status != Converged || s.Iterations != 1 || s.Evaluations != 2 {
+    test.Error("test failed")
+  }
+  if len(s.Trace) != 2 || s.Trace[1].X.Float64At(0) != 1.0 || s.Trace[1].Value != 2.0 {
+    test.Error("test failed")
+  }
+  if r.Hook(true); r.Result(x, 2.0, 0.5, Converged).Status != HookStopped {
+    test.Error("test failed")
+  }
+}
+
+func TestEvaluate(test *testing.T) {
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    r.Mul(x.ConstAt(0), x.ConstAt(1))
+    return r, nil
+  }
+  y, g, err := Evaluate(f, NewDenseFloat64Vector([]float64{3, 4}))
+  if err != nil {
+    test.Error(err)
+  }
+  if y != 12.0 || math.Abs(g - 5.0) > 1e-12 {
+    test.Error("test failed")
+  }
+  if Stalled.String() != "stalled" {
+    test.Error("test failed")
+  }
+}
+
+func TestBounds(test *testing.T) {
+  b := Bounds{Lower: []float64{0, math.Inf(-1)}, Upper: []float64{1, 2}}
+  if err := b.Check(2); err != nil {
+    test.Error(err)
+  }
+  if err := b.Check(3); err == nil {
+    test.Error("test failed")
+  }
+  if err := (Bounds{Lower: []float64{1, 0}, Upper: []float64{0, 1}}).Check(2); err == nil {
+    test.Error("test failed")
+  }
+  x := NewDenseFloat64Vector([]float64{-1, 3})
+  b.Project(x)
+  if x[0] != 0 || x[1] != 2 {
+    test.Error("test failed")
+  }
+  x = NewDenseFloat64Vector([]float64{-1, 3})
+  Bounds{}.Project(x)
+  if x[0] != -1 || x[1] != 3 || (Bounds{}).IsSet() {
+    test.Error("test failed")
+  }
+}
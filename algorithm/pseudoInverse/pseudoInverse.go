@@ -0,0 +1,236 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pseudoInverse
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/svd"
+
+/* -------------------------------------------------------------------------- */
+
+// Singular values smaller than epsilon times the largest singular value
+// are treated as zero (default max(m,n) times the machine epsilon)
+type Epsilon struct {
+  Value float64
+}
+
+/* -------------------------------------------------------------------------- */
+
+type decomposition struct {
+  // A = U diag(s) V^T, where U is m x k and V is n x n with k = min(m,n)
+  s Vector
+  u Matrix
+  v Matrix
+  // indices of singular values sorted in descending order
+  p []int
+  // numerical rank
+  r int
+}
+
+func diag(h ConstMatrix) Vector {
+  m, n := h.Dims()
+  if m < n {
+    n = m
+  }
+  r := NullDenseVector(h.ElementType(), n)
+  for i := 0; i < n; i++ {
+    r.At(i).Set(h.ConstAt(i, i))
+  }
+  return r
+}
+
+func decompose(a Matrix, args ...interface{}) (decomposition, error) {
+  m, n := a.Dims()
+  epsilon := -1.0
+  for _, arg := range args {
+    switch tmp := arg.(type) {
+    case Epsilon:
+      epsilon = tmp.Value
+    default:
+      panic("PseudoInverse(): Invalid optional argument!")
+    }
+  }
+  if epsilon < 0.0 {
+    epsilon = float64(m)*2.220446049250313e-16
+    if n > m {
+      epsilon = float64(n)*2.220446049250313e-16
+    }
+  }
+  r := decomposition{}
+  if m >= n {
+    h, u, v, err := svd.Run(a, svd.ComputeU{Value: true}, svd.ComputeV{Value: true})
+    if err != nil {
+      return r, err
+    }
+    r.s = diag(h)
+    r.u = u.Slice(0, m, 0, n)
+    r.v = v
+  } else {
+    // A^T = U H V^T, hence A = V H U^T
+    h, u, v, err := svd.Run(a.T(), svd.ComputeU{Value: true}, svd.ComputeV{Value: true})
+    if err != nil {
+      return r, err
+    }
+    r.s = diag(h)
+    r.u = v
+    r.v = u
+  }
+  k := r.s.Dim()
+  r.p = make([]int, k)
+  for i := 0; i < k; i++ {
+    r.p[i] = i
+  }
+  sort.SliceStable(r.p, func(i, j int) bool {
+    return math.Abs(r.s.ConstAt(r.p[i]).GetFloat64()) > math.Abs(r.s.ConstAt(r.p[j]).GetFloat64())
+  })
+  if k > 0 {
+    tol := epsilon*math.Abs(r.s.ConstAt(r.p[0]).GetFloat64())
+    for _, i := range r.p {
+      if s := math.Abs(r.s.ConstAt(i).GetFloat64()); s > tol && s > 0.0 {
+        r.r++
+      }
+    }
+  }
+  return r, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the Moore-Penrose pseudo-inverse A^+ = V diag(1/s) U^T from the
+// singular value decomposition A = U diag(s) V^T, where singular values
+// smaller than epsilon times the largest singular value are treated as
+// zero. A may be singular or rectangular.
+func Run(a Matrix, args ...interface{}) (Matrix, error) {
+  m, n := a.Dims()
+  d, err := decompose(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  t := a.ElementType()
+  r := NullDenseMatrix(t, n, m)
+  s := NullScalar(t)
+  w := NullScalar(t)
+  for _, k := range d.p[0:d.r] {
+    for i := 0; i < n; i++ {
+      s.Div(d.v.ConstAt(i, k), d.s.ConstAt(k))
+      for j := 0; j < m; j++ {
+        w.Mul(s, d.u.ConstAt(j, k))
+        r.At(i, j).Add(r.At(i, j), w)
+      }
+    }
+  }
+  return r, nil
+}
+
+// Numerical rank of A, i.e. the number of singular values larger than
+// epsilon times the largest singular value.
+func Rank(a Matrix, args ...interface{}) (int, error) {
+  d, err := decompose(a, args...)
+  if err != nil {
+    return 0, err
+  }
+  return d.r, nil
+}
+
+// Orthonormal basis of the null space of A. The columns of the returned
+// n x (n-r) matrix span the null space, where r is the numerical rank of
+// A. Returns nil if A has full column rank.
+func NullSpace(a Matrix, args ...interface{}) (Matrix, error) {
+  _, n := a.Dims()
+  d, err := decompose(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  if d.r == n {
+    return nil, nil
+  }
+  // right singular vectors of zero singular values and, if m < n, all
+  // remaining columns of V
+  idx := append([]int{}, d.p[d.r:]...)
+  for j := len(d.p); j < n; j++ {
+    idx = append(idx, j)
+  }
+  r := NullDenseMatrix(a.ElementType(), n, len(idx))
+  for j, k := range idx {
+    for i := 0; i < n; i++ {
+      r.At(i, j).Set(d.v.ConstAt(i, k))
+    }
+  }
+  return r, nil
+}
+
+// Orthonormal basis of the column space of A. The columns of the returned
+// m x r matrix are the left singular vectors of the r nonzero singular
+// values sorted in descending order, where r is the numerical rank of A.
+// Returns nil if A has rank zero.
+func Orth(a Matrix, args ...interface{}) (Matrix, error) {
+  m, _ := a.Dims()
+  d, err := decompose(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  if d.r == 0 {
+    return nil, nil
+  }
+  r := NullDenseMatrix(a.ElementType(), m, d.r)
+  for j, k := range d.p[0:d.r] {
+    for i := 0; i < m; i++ {
+      r.At(i, j).Set(d.u.ConstAt(i, k))
+    }
+  }
+  return r, nil
+}
+
+// Compute the minimum norm solution x = A^+ b of the linear least squares
+// problem min ||A x - b||, which is well defined also if A does not have
+// full column rank.
+func Solve(a Matrix, b ConstVector, args ...interface{}) (Vector, error) {
+  m, n := a.Dims()
+  if b.Dim() != m {
+    return nil, fmt.Errorf("matrix vector dimensions do not match")
+  }
+  d, err := decompose(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  t := a.ElementType()
+  if s := b.ElementType(); s == Real32Type || s == Real64Type {
+    t = s
+  }
+  x := NullDenseVector(t, n)
+  s := NullScalar(t)
+  w := NullScalar(t)
+  for _, k := range d.p[0:d.r] {
+    // s = u_k^T b / s_k
+    s.Reset()
+    for i := 0; i < m; i++ {
+      w.Mul(d.u.ConstAt(i, k), b.ConstAt(i))
+      s.Add(s, w)
+    }
+    s.Div(s, d.s.ConstAt(k))
+    for i := 0; i < n; i++ {
+      w.Mul(s, d.v.ConstAt(i, k))
+      x.At(i).Add(x.At(i), w)
+    }
+  }
+  return x, nil
+}
@@ -0,0 +1,157 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pseudoInverse
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// check the four Penrose conditions
+func checkPenrose(test *testing.T, a, x Matrix) {
+  m, n := a.Dims()
+  ax  := NullDenseFloat64Matrix(m, m)
+  xa  := NullDenseFloat64Matrix(n, n)
+  axa := NullDenseFloat64Matrix(m, n)
+  xax := NullDenseFloat64Matrix(n, m)
+  ax .MdotM(a, x)
+  xa .MdotM(x, a)
+  axa.MdotM(ax, a)
+  xax.MdotM(xa, x)
+  if !axa.Equals(a, 1e-10) {
+    test.Error("test failed")
+  }
+  if !xax.Equals(x, 1e-10) {
+    test.Error("test failed")
+  }
+  if !ax.Equals(ax.T(), 1e-10) || !xa.Equals(xa.T(), 1e-10) {
+    test.Error("test failed")
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func TestPseudoInverse1(test *testing.T) {
+  // design matrix with collinear columns (x3 = x1 + x2)
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    1, 0, 1,
+    2, 1, 3,
+    0, 1, 1,
+    3, 1, 4 }, 5, 3)
+
+  x, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkPenrose(test, a, x)
+
+  if r, err := Rank(a); err != nil {
+    test.Error(err)
+  } else if r != 2 {
+    test.Error("test failed")
+  }
+  if z, err := NullSpace(a); err != nil {
+    test.Error(err)
+  } else {
+    if _, k := z.Dims(); k != 1 {
+      test.Error("test failed")
+    }
+    // null space is spanned by (1, 1, -1)
+    r := NullDenseFloat64Vector(5)
+    r.MdotV(a, z.Col(0))
+    if !r.Equals(NullDenseFloat64Vector(5), 1e-10) {
+      test.Error("test failed")
+    }
+    if v := z.Col(0); math.Abs(math.Abs(v.ConstAt(0).GetFloat64()) - 1/math.Sqrt(3)) > 1e-10 {
+      test.Error("test failed")
+    }
+  }
+  if q, err := Orth(a); err != nil {
+    test.Error(err)
+  } else {
+    if _, k := q.Dims(); k != 2 {
+      test.Error("test failed")
+    }
+    // Q Q^T a = a
+    p := NullDenseFloat64Matrix(5, 5)
+    p.MdotM(q, q.T())
+    r := NullDenseFloat64Matrix(5, 3)
+    r.MdotM(p, a)
+    if !r.Equals(a, 1e-10) {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestPseudoInverse2(test *testing.T) {
+  // wide matrix with full row rank
+  a := NewDenseFloat64Matrix([]float64{
+    1, 0, 2, 1,
+    0, 1, 1, 3 }, 2, 4)
+
+  x, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkPenrose(test, a, x)
+
+  if z, err := NullSpace(a); err != nil {
+    test.Error(err)
+  } else {
+    if _, k := z.Dims(); k != 2 {
+      test.Error("test failed")
+    }
+    r := NullDenseFloat64Matrix(2, 2)
+    r.MdotM(a, z)
+    if !r.Equals(NullDenseFloat64Matrix(2, 2), 1e-10) {
+      test.Error("test failed")
+    }
+    r.MdotM(z.T(), z)
+    if !r.Equals(DenseIdentityMatrix(Float64Type, 2), 1e-10) {
+      test.Error("test failed")
+    }
+  }
+}
+
+func TestPseudoInverse3(test *testing.T) {
+  // minimum norm solution of an underdetermined system: the solution of
+  // x1 + x2 = 2 with minimum norm is (1, 1)
+  a := NewDenseFloat64Matrix([]float64{
+    1, 1,
+    1, 1 }, 2, 2)
+  b := NewDenseFloat64Vector([]float64{2, 2})
+
+  if x, err := Solve(a, b); err != nil {
+    test.Error(err)
+  } else if !x.Equals(NewDenseFloat64Vector([]float64{1, 1}), 1e-10) {
+    test.Error("test failed")
+  }
+  // zero matrix
+  if r, err := Rank(NullDenseFloat64Matrix(3, 2)); err != nil {
+    test.Error(err)
+  } else if r != 0 {
+    test.Error("test failed")
+  }
+}
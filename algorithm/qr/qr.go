@@ -0,0 +1,234 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package qr
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/householder"
+
+/* -------------------------------------------------------------------------- */
+
+type ComputeQ struct {
+  Value bool
+}
+
+type Thin struct {
+  Value bool
+}
+
+type ColumnPivoting struct {
+  Value bool
+}
+
+type InSitu struct {
+  R    Matrix
+  Q    Matrix
+  P    []int
+  X    Vector
+  Nu   Vector
+  Beta Scalar
+  T1   Scalar
+  T2   Scalar
+  T3   Scalar
+  T4   Vector
+}
+
+/* -------------------------------------------------------------------------- */
+
+// squared norm of column j of R starting at row i
+func columnNorm(R Matrix, i, j int) float64 {
+  m, _ := R.Dims()
+  r    := 0.0
+  for k := i; k < m; k++ {
+    x := R.ConstAt(k, j).GetFloat64()
+    r += x*x
+  }
+  return r
+}
+
+func qr(inSitu *InSitu, pivoting bool) (Matrix, Matrix, []int, error) {
+  R := inSitu.R
+  Q := inSitu.Q
+  p := inSitu.P
+  x := inSitu.X
+  t := inSitu.T4
+
+  m, n := R.Dims()
+
+  for j := 0; j < n && j < m; j++ {
+    if pivoting {
+      // move column with largest norm to position j
+      k := j
+      c := columnNorm(R, j, j)
+      for l := j+1; l < n; l++ {
+        if d := columnNorm(R, j, l); d > c {
+          k, c = l, d
+        }
+      }
+      if k != j {
+        for i := 0; i < m; i++ {
+          R.Swap(i, j, i, k)
+        }
+        p[j], p[k] = p[k], p[j]
+      }
+    }
+    for k := j; k < m; k++ {
+      x.At(k).Set(R.ConstAt(k, j))
+    }
+    nu   := inSitu.Nu.Slice(j, m)
+    beta := inSitu.Beta
+    householder.Run(x.Slice(j, m), beta, nu, inSitu.T1, inSitu.T2, inSitu.T3)
+    // compute (I - beta nu nu^T) R(j:m, j:n)
+    householder.ApplyLeft(R.Slice(j, m, j, n), beta, nu, t.Slice(j, n), inSitu.T1)
+    // elements below the diagonal are zero by construction
+    for k := j+1; k < m; k++ {
+      R.At(k, j).Reset()
+    }
+    // accumulate Q
+    if Q != nil {
+      householder.ApplyRight(Q.Slice(0, m, j, m), beta, nu, t.Slice(0, m), inSitu.T1)
+    }
+  }
+  return Q, R, p, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the QR decomposition A P = Q R of an m x n matrix A using
+// Householder reflections. Q is an orthogonal m x m matrix, R an upper
+// triangular m x n matrix and P a permutation of the columns of A, such that
+// the jth column of A P is the p[j]th column of A. Columns are only
+// permuted if ColumnPivoting is enabled. With the Thin option, only the
+// first min(m, n) columns of Q and rows of R are returned. Q is nil if
+// ComputeQ is false.
+func Run(a ConstMatrix, args ...interface{}) (Matrix, Matrix, []int, error) {
+  m, n := a.Dims()
+  t    := a.ElementType()
+
+  inSitu   := &InSitu{}
+  computeQ := true
+  thin     := false
+  pivoting := false
+
+  // loop over optional arguments
+  for _, arg := range args {
+    switch tmp := arg.(type) {
+    case ComputeQ:
+      computeQ = tmp.Value
+    case Thin:
+      thin = tmp.Value
+    case ColumnPivoting:
+      pivoting = tmp.Value
+    case *InSitu:
+      inSitu = tmp
+    case InSitu:
+      panic("InSitu must be passed by reference")
+    default:
+      panic("QR(): Invalid optional argument!")
+    }
+  }
+  if inSitu.R == nil {
+    inSitu.R = NullDenseMatrix(t, m, n)
+  } else {
+    if u, v := inSitu.R.Dims(); u != m || v != n {
+      return nil, nil, nil, fmt.Errorf("R has invalid dimension (%dx%d instead of %dx%d)", u, v, m, n)
+    }
+  }
+  inSitu.R.Set(a)
+  if computeQ {
+    if inSitu.Q == nil {
+      inSitu.Q = NullDenseMatrix(t, m, m)
+    } else {
+      if u, v := inSitu.Q.Dims(); u != m || v != m {
+        return nil, nil, nil, fmt.Errorf("Q has invalid dimension (%dx%d instead of %dx%d)", u, v, m, m)
+      }
+    }
+    inSitu.Q.SetIdentity()
+  } else {
+    inSitu.Q = nil
+  }
+  if inSitu.P == nil {
+    inSitu.P = make([]int, n)
+  } else {
+    if len(inSitu.P) != n {
+      return nil, nil, nil, fmt.Errorf("P has invalid length (%d instead of %d)", len(inSitu.P), n)
+    }
+  }
+  for j := 0; j < n; j++ {
+    inSitu.P[j] = j
+  }
+  if inSitu.X == nil {
+    inSitu.X = NullDenseVector(t, m)
+  }
+  if inSitu.Nu == nil {
+    inSitu.Nu = NullDenseVector(t, m)
+  }
+  if inSitu.Beta == nil {
+    inSitu.Beta = NullScalar(t)
+  }
+  if inSitu.T1 == nil {
+    inSitu.T1 = NullScalar(t)
+  }
+  if inSitu.T2 == nil {
+    inSitu.T2 = NullScalar(t)
+  }
+  if inSitu.T3 == nil {
+    inSitu.T3 = NullScalar(t)
+  }
+  if inSitu.T4 == nil {
+    if m > n {
+      inSitu.T4 = NullDenseVector(t, m)
+    } else {
+      inSitu.T4 = NullDenseVector(t, n)
+    }
+  }
+  Q, R, p, err := qr(inSitu, pivoting)
+  if err != nil {
+    return nil, nil, nil, err
+  }
+  if thin && m > n {
+    if Q != nil {
+      Q = Q.Slice(0, m, 0, n)
+    }
+    R = R.Slice(0, n, 0, n)
+  }
+  return Q, R, p, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Estimate the rank of a matrix from the diagonal of R computed with
+// column pivoting. Diagonal elements smaller than epsilon times the
+// largest diagonal element are considered zero.
+func Rank(r ConstMatrix, epsilon float64) int {
+  m, n := r.Dims()
+  if m == 0 || n == 0 {
+    return 0
+  }
+  c := epsilon*math.Abs(r.ConstAt(0, 0).GetFloat64())
+  k := 0
+  for i := 0; i < m && i < n; i++ {
+    if math.Abs(r.ConstAt(i, i).GetFloat64()) > c {
+      k++
+    }
+  }
+  return k
+}
@@ -0,0 +1,110 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package qr
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func checkQR(test *testing.T, a, q, r Matrix, p []int) {
+  m, _ := q.Dims()
+  _, n := r.Dims()
+  // check A P = Q R
+  b := NullDenseFloat64Matrix(m, n)
+  for j := 0; j < n; j++ {
+    ColView(b, j).Set(ColView(a, p[j]))
+  }
+  c := NullDenseFloat64Matrix(m, n)
+  c.MdotM(q, r)
+  if !c.Equals(b, 1e-10) {
+    test.Error("test failed")
+  }
+  // check Q^T Q = I
+  _, k := q.Dims()
+  d := NullDenseFloat64Matrix(k, k)
+  d.MdotM(q.T(), q)
+  if !d.Equals(DenseIdentityMatrix(Float64Type, k), 1e-10) {
+    test.Error("test failed")
+  }
+  // check that R is upper triangular
+  u, v := r.Dims()
+  for i := 0; i < u; i++ {
+    for j := 0; j < i && j < v; j++ {
+      if r.At(i, j).GetFloat64() != 0.0 {
+        test.Error("test failed")
+      }
+    }
+  }
+}
+
+func TestQR1(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    12, -51,   4,
+     6, 167, -68,
+    -4,  24, -41,
+     1,   2,   3 }, 4, 3)
+
+  if q, r, p, err := Run(a); err != nil {
+    test.Error(err)
+  } else {
+    if u, v := q.Dims(); u != 4 || v != 4 {
+      test.Error("test failed")
+    }
+    checkQR(test, a, q, r, p)
+  }
+  if q, r, p, err := Run(a, Thin{true}); err != nil {
+    test.Error(err)
+  } else {
+    if u, v := q.Dims(); u != 4 || v != 3 {
+      test.Error("test failed")
+    }
+    checkQR(test, a, q, r, p)
+  }
+  if q, r, p, err := Run(a.T()); err != nil {
+    test.Error(err)
+  } else {
+    checkQR(test, a.T(), q, r, p)
+  }
+}
+
+func TestQR2(test *testing.T) {
+  // matrix of rank 2
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    2, 4, 6,
+    1, 0, 1,
+    0, 1, 1 }, 4, 3)
+
+  q, r, p, err := Run(a, ColumnPivoting{true})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkQR(test, a, q, r, p)
+
+  if p[0] != 2 {
+    test.Error("test failed")
+  }
+  if k := Rank(r, 1e-10); k != 2 {
+    test.Errorf("test failed: rank is %d", k)
+  }
+}
@@ -97,9 +97,9 @@ func QRstep(H, U Matrix, p, q int, inSitu *InSitu) {
     givensRotation.Run(H22.At(i, i), H22.At(i+1, i), c, s)
     // multiply with Givens matrix (G H)
     givensRotation.ApplyHessenbergLeft(H22, c, s, i, i+1, t1, t2)
-    givensRotation.ApplyHessenbergLeft(H23, c, s, i, i+1, t1, t2)
+    givensRotation.ApplyLeft(H23, c, s, i, i+1, t1, t2)
     // multiply with Givens matrix (H G)
-    givensRotation.ApplyHessenbergRight(H12, c, s, i, i+1, t1, t2)
+    givensRotation.ApplyRight(H12, c, s, i, i+1, t1, t2)
     givensRotation.ApplyHessenbergRight(H22, c, s, i, i+1, t1, t2)
     if u != nil {
       givensRotation.ApplyRight(u, c, s, i, i+1, t1, t2)
@@ -323,6 +323,10 @@ func qrAlgorithm(inSitu *InSitu, epsilon float64) (Matrix, Matrix, error) {
 
 /* -------------------------------------------------------------------------- */
 
+// Compute the real Schur decomposition A = U H U^T, where H is upper
+// quasi-triangular with 1x1 and 2x2 blocks on the diagonal. The 2x2 blocks
+// correspond to pairs of complex conjugate eigenvalues. U is orthogonal and
+// only computed if requested with the ComputeU option.
 func Run(a Matrix, args ...interface{}) (Matrix, Matrix, error) {
 
   n, m := a.Dims()
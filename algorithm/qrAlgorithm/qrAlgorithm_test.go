@@ -20,6 +20,7 @@ package qrAlgorithm
 
 //import   "fmt"
 import   "math"
+import   "math/rand"
 import   "sort"
 import   "testing"
 
@@ -201,3 +202,25 @@ func Test8(test *testing.T) {
     test.Errorf("test failed")
   }
 }
+
+func TestQRstepOffDiagonalBlocks(test *testing.T) {
+  // QR steps on 2x2 blocks with real eigenvalues must be applied to the
+  // full off-diagonal blocks
+  t := NewFloat64(0.0)
+  n := 12
+  r := rand.New(rand.NewSource(3))
+  a := NullDenseFloat64Matrix(n, n)
+  for i := 0; i < n; i++ {
+    for j := 0; j < n; j++ {
+      a.At(i, j).SetFloat64(r.NormFloat64())
+    }
+  }
+  h, u, _ := Run(a, ComputeU{true}, Epsilon{1e-15})
+
+  b := NullDenseFloat64Matrix(n, n)
+  b.MdotM(b.MdotM(u, h), u.T())
+
+  if math.Abs(t.Mnorm(b.MsubM(a, b)).GetFloat64()) > 1e-10 {
+    test.Errorf("test failed")
+  }
+}
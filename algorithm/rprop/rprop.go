@@ -23,6 +23,7 @@ import   "math"
 
 import . "github.com/pbenner/autodiff"
 import . "github.com/pbenner/autodiff/algorithm"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -225,3 +226,70 @@ func RunGradient(f interface{}, x0 ConstVector, step_init float64, eta []float64
     panic("invalid objective function")
   }
 }
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. Accepts the same arguments as Run and
+// additionally optimize.Trace.
+func Minimize(f interface{}, x0 Vector, step_init float64, eta []float64, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook          := Hook         { nil}
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  options       := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    case MaxIterations:
+      maxIterations = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  h, ok := f.(func(ConstVector) (MagicScalar, error))
+  if !ok {
+    panic("invalid objective function")
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return h(x)
+  }
+  // the hook is called at the beginning of every iteration
+  first := true
+  options = append(options, Hook{func(gradient, step []float64, x ConstVector, y ConstScalar) bool {
+    if first {
+      r.Init(x, y.GetFloat64(), Norm(gradient)); first = false
+    } else {
+      r.Iteration(x, y.GetFloat64(), Norm(gradient))
+    }
+    return hook.Value != nil && r.Hook(hook.Value(gradient, step, x, y))
+  }})
+  x, err := Run(g, x0, step_init, eta, options...)
+  if x == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  y, gnorm, e := optimize.Evaluate(h, x)
+  switch {
+  case err != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), err
+  case e != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), e
+  case gnorm < epsilon.Value:
+    return r.Result(x, y, gnorm, optimize.Converged), nil
+  default:
+    // the hook is not called after the last iteration
+    if !r.Stopped() {
+      r.SetIterations(maxIterations.Value)
+    }
+    return r.Result(x, y, gnorm, optimize.MaxIterationsReached), nil
+  }
+}
@@ -22,6 +22,7 @@ import   "fmt"
 import   "math"
 
 import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -360,3 +361,77 @@ func Run(f interface{}, n int, x Vector, args ...interface{}) (Vector, int64, er
     }
   }
 }
+
+/* -------------------------------------------------------------------------- */
+
+// Run SAGA and return the solution together with convergence diagnostics.
+// Iterations are epochs and evaluations are calls of f on single data
+// points. Since objective functions provide only gradient information of
+// single data points, the final value and gradient norm are not available
+// (NaN). Accepts the same arguments as Run and additionally optimize.Trace.
+func Minimize(f interface{}, n int, x Vector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook          := Hook         {nil}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  options       := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case MaxIterations:
+      maxIterations = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  var g interface{}
+  switch a := f.(type) {
+  case Objective1Dense:
+    g = Objective1Dense(func(i int, x DenseFloat64Vector) (float64, float64, DenseFloat64Vector, error) {
+      r.Evaluation()
+      return a(i, x)
+    })
+  case Objective2Dense:
+    g = Objective2Dense(func(i int, x DenseFloat64Vector) (float64, DenseFloat64Vector, error) {
+      r.Evaluation()
+      return a(i, x)
+    })
+  case Objective1Sparse:
+    g = Objective1Sparse(func(i int, x DenseFloat64Vector) (float64, float64, SparseConstFloat64Vector, error) {
+      r.Evaluation()
+      return a(i, x)
+    })
+  case Objective2Sparse:
+    g = Objective2Sparse(func(i int, x DenseFloat64Vector) (float64, SparseConstFloat64Vector, error) {
+      r.Evaluation()
+      return a(i, x)
+    })
+  default:
+    panic("invalid objective")
+  }
+  // the hook is called after every epoch unless the algorithm converged
+  options = append(options, Hook{func(x ConstVector, delta, lambda ConstScalar, epoch int) bool {
+    r.Iteration(x, math.NaN(), math.NaN())
+    return hook.Value != nil && r.Hook(hook.Value(x, delta, lambda, epoch))
+  }})
+  xn, _, err := Run(g, n, x, options...)
+  if xn == nil {
+    return r.Result(x, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  switch {
+  case err != nil:
+    return r.Result(xn, math.NaN(), math.NaN(), optimize.Failed), err
+  case r.Stopped():
+    return r.Result(xn, math.NaN(), math.NaN(), optimize.HookStopped), nil
+  case r.Iterations() == maxIterations.Value:
+    return r.Result(xn, math.NaN(), math.NaN(), optimize.MaxIterationsReached), nil
+  default:
+    // the hook is not called in the last epoch
+    r.SetIterations(r.Iterations()+1)
+    return r.Result(xn, math.NaN(), math.NaN(), optimize.Converged), nil
+  }
+}
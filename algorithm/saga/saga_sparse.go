@@ -155,8 +155,14 @@ func saga1Sparse(
       return x1, g.Int63(), err
     } else {
       // execute hook if available
-      if hook.Value != nil && hook.Value(x1, ConstFloat64(delta), ConstFloat64(float64(n)*proxop.GetLambda()/gamma.Value), epoch) {
-        break
+      if hook.Value != nil {
+        lambda := 0.0
+        if proxop != nil {
+          lambda = float64(n)*proxop.GetLambda()/gamma.Value
+        }
+        if hook.Value(x1, ConstFloat64(delta), ConstFloat64(lambda), epoch) {
+          break
+        }
       }
     }
     xs.Set(x1)
@@ -242,8 +248,14 @@ func saga2Sparse(
       return x1, g.Int63(), err
     } else {
       // execute hook if available
-      if hook.Value != nil && hook.Value(x1, ConstFloat64(delta), ConstFloat64(float64(n)*proxop.GetLambda()/gamma.Value), epoch) {
-        break
+      if hook.Value != nil {
+        lambda := 0.0
+        if proxop != nil {
+          lambda = float64(n)*proxop.GetLambda()/gamma.Value
+        }
+        if hook.Value(x1, ConstFloat64(delta), ConstFloat64(lambda), epoch) {
+          break
+        }
       }
     }
     xs.Set(x1)
@@ -24,6 +24,7 @@ import   "testing"
 
 import . "github.com/pbenner/autodiff"
 import . "github.com/pbenner/autodiff/statistics/vectorDistribution"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
 
 /* -------------------------------------------------------------------------- */
 
@@ -401,3 +402,37 @@ func Test7(test *testing.T) {
     }
   }
 }
+
+func TestMinimize(test *testing.T) {
+
+  // data
+  cellSize  := []float64{
+    1, 4, 1, 8, 1, 10, 1, 1, 1, 2, 1, 1, 3, 1, 7, 4, 1, 1, 7, 1}
+  cellShape := []float64{
+    1, 4, 1, 8, 1, 10, 1, 2, 1, 1, 1, 1, 3, 1, 5, 6, 1, 1, 7, 1}
+  class := []float64{
+    0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0, 1, 1, 0, 0, 1, 0}
+  // x
+  x := make([]DenseFloat64Vector, len(cellSize))
+  for i := 0; i < len(cellSize); i++ {
+    x[i] = DenseFloat64Vector([]float64{1.0, cellSize[i], cellShape[i]})
+  }
+
+  theta_0 := NewDenseFloat64Vector([]float64{-1, 0.0, 0.0})
+  z := DenseFloat64Vector([]float64{-3.549076e+00, 1.840901e-01, 5.067003e-01})
+  t := NullFloat64()
+
+  if r, err := Minimize(Objective1Dense(f_dense(class, x)), len(cellSize), theta_0, Gamma{1.0/20}, Epsilon{1e-8}); err != nil {
+    test.Error(err)
+  } else {
+    if t.Vnorm(r.X.VsubV(r.X, z)); t.GetFloat64() > 1e-4 {
+      test.Error("test failed")
+    }
+    if r.Status != optimize.Converged || r.Evaluations < r.Iterations*len(cellSize) {
+      test.Error("test failed")
+    }
+  }
+  if r, _ := Minimize(Objective1Dense(f_dense(class, x)), len(cellSize), theta_0, Gamma{1.0/20}, MaxIterations{10}); r.Status != optimize.MaxIterationsReached || r.Iterations != 10 {
+    test.Error("test failed")
+  }
+}
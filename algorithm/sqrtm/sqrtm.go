@@ -0,0 +1,226 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqrtm
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/lu"
+import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
+
+/* -------------------------------------------------------------------------- */
+
+// The input matrix is already in real Schur form, i.e. upper
+// quasi-triangular with 1x1 and 2x2 blocks on the diagonal
+type QuasiTriangular struct {
+  Value bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns the first index and size of all diagonal blocks
+func getBlocks(t ConstMatrix) ([]int, []int) {
+  n, _ := t.Dims()
+  start := []int{}
+  size  := []int{}
+  for i := 0; i < n; i++ {
+    start = append(start, i)
+    if i < n-1 && t.ConstAt(i+1, i).GetFloat64() != 0.0 {
+      size = append(size, 2)
+      i++
+    } else {
+      size = append(size, 1)
+    }
+  }
+  return start, size
+}
+
+// Square root of a 1x1 or 2x2 diagonal block. For 2x2 blocks the principal
+// square root is given by (T + s I)/t, where s = sqrt(det T) and
+// t = sqrt(tr T + 2s).
+func sqrtBlock(r, t Matrix, t1, t2 Scalar) error {
+  n, _ := t.Dims()
+  if n == 1 {
+    if t.ConstAt(0, 0).GetFloat64() < 0.0 {
+      return fmt.Errorf("matrix has negative real eigenvalues")
+    }
+    r.At(0, 0).Sqrt(t.ConstAt(0, 0))
+    return nil
+  }
+  // t1 = sqrt(det T)
+  t1.Mul(t.ConstAt(0, 0), t.ConstAt(1, 1))
+  t2.Mul(t.ConstAt(0, 1), t.ConstAt(1, 0))
+  t1.Sub(t1, t2)
+  if t1.GetFloat64() < 0.0 {
+    return fmt.Errorf("matrix has negative real eigenvalues")
+  }
+  t1.Sqrt(t1)
+  // t2 = sqrt(tr T + 2 sqrt(det T))
+  t2.Add(t.ConstAt(0, 0), t.ConstAt(1, 1))
+  t2.Add(t2, t1)
+  t2.Add(t2, t1)
+  if t2.GetFloat64() <= 0.0 {
+    return fmt.Errorf("matrix has negative real eigenvalues")
+  }
+  t2.Sqrt(t2)
+  r.At(0, 0).Add(t.ConstAt(0, 0), t1)
+  r.At(0, 1).Set(t.ConstAt(0, 1))
+  r.At(1, 0).Set(t.ConstAt(1, 0))
+  r.At(1, 1).Add(t.ConstAt(1, 1), t1)
+  for i := 0; i < 2; i++ {
+    for j := 0; j < 2; j++ {
+      r.At(i, j).Div(r.At(i, j), t2)
+    }
+  }
+  return nil
+}
+
+// Solve the Sylvester equation A X + X B = C for small matrices A (p x p)
+// and B (q x q) by vectorization
+func sylvesterBlock(x, a, b, c Matrix) error {
+  p, _ := a.Dims()
+  q, _ := b.Dims()
+  m := NullDenseMatrix(x.ElementType(), p*q, p*q)
+  y := NullDenseVector(x.ElementType(), p*q)
+  for i := 0; i < p; i++ {
+    for j := 0; j < q; j++ {
+      // row of equation (i,j), unknown X_kl has index k + p*l
+      for k := 0; k < p; k++ {
+        m.At(i + p*j, k + p*j).Add(m.At(i + p*j, k + p*j), a.ConstAt(i, k))
+      }
+      for l := 0; l < q; l++ {
+        m.At(i + p*j, i + p*l).Add(m.At(i + p*j, i + p*l), b.ConstAt(l, j))
+      }
+      y.At(i + p*j).Set(c.ConstAt(i, j))
+    }
+  }
+  l, u, pi, err := lu.Run(m)
+  if err != nil {
+    return err
+  }
+  z, err := lu.Solve(l, u, pi, y)
+  if err != nil {
+    return err
+  }
+  for i := 0; i < p; i++ {
+    for j := 0; j < q; j++ {
+      x.At(i, j).Set(z.ConstAt(i + p*j))
+    }
+  }
+  return nil
+}
+
+// Compute the principal square root of an upper quasi-triangular matrix
+// with the block recurrence of Higham (1987)
+func sqrtQuasiTriangular(t Matrix) (Matrix, error) {
+  n, _ := t.Dims()
+  e := t.ElementType()
+  r := NullDenseMatrix(e, n, n)
+  c := NullDenseMatrix(e, 2, 2)
+  t1 := NullScalar(e)
+  t2 := NullScalar(e)
+
+  start, size := getBlocks(t)
+
+  for j := 0; j < len(start); j++ {
+    j0, j1 := start[j], start[j]+size[j]
+    if err := sqrtBlock(r.Slice(j0, j1, j0, j1), t.Slice(j0, j1, j0, j1), t1, t2); err != nil {
+      return nil, err
+    }
+    for i := j-1; i >= 0; i-- {
+      i0, i1 := start[i], start[i]+size[i]
+      // C = T_ij - sum_k R_ik R_kj
+      ci := c.Slice(0, size[i], 0, size[j])
+      for k := i0; k < i1; k++ {
+        for l := j0; l < j1; l++ {
+          s := ci.At(k-i0, l-j0)
+          s.Set(t.ConstAt(k, l))
+          for h := i1; h < j0; h++ {
+            t1.Mul(r.ConstAt(k, h), r.ConstAt(h, l))
+            s.Sub(s, t1)
+          }
+        }
+      }
+      if err := sylvesterBlock(r.Slice(i0, i1, j0, j1), r.Slice(i0, i1, i0, i1), r.Slice(j0, j1, j0, j1), ci); err != nil {
+        return nil, fmt.Errorf("matrix is singular")
+      }
+    }
+  }
+  return r, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+func sqrtm(a Matrix, args ...interface{}) (Matrix, error) {
+  n, _ := a.Dims()
+  e := a.ElementType()
+
+  args = append(args, qrAlgorithm.ComputeU{Value: true})
+  h, u, err := qrAlgorithm.Run(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  r, err := sqrtQuasiTriangular(h)
+  if err != nil {
+    return nil, err
+  }
+  // A^(1/2) = U R U^T
+  t := NullDenseMatrix(e, n, n)
+  t.MdotM(u, r)
+  r.MdotM(t, u.T())
+  return r, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the principal square root X of a real matrix A, i.e. X X = A and
+// all eigenvalues of X have positive real part. The matrix is first
+// reduced to real Schur form A = U T U^T with the QR algorithm and the
+// square root of T is computed with the block recurrence of Higham (1987).
+// A must not have negative real eigenvalues. If A is already upper
+// quasi-triangular, the Schur decomposition can be skipped with the
+// QuasiTriangular option. All remaining options are passed to the QR
+// algorithm.
+func Run(a Matrix, args_ ...interface{}) (Matrix, error) {
+  n, m := a.Dims()
+  if n != m {
+    return nil, fmt.Errorf("`a' must be a square matrix")
+  }
+  if n == 0 {
+    return nil, fmt.Errorf("`a' is an empty matrix")
+  }
+  quasiTriangular := false
+  // arguments passed on to the qrAlgorithm
+  var args []interface{}
+  // loop over optional arguments
+  for _, arg := range args_ {
+    switch tmp := arg.(type) {
+    case QuasiTriangular:
+      quasiTriangular = tmp.Value
+    case qrAlgorithm.ComputeU:
+      // drop this option
+    default:
+      args = append(args, arg)
+    }
+  }
+  if quasiTriangular {
+    return sqrtQuasiTriangular(a)
+  }
+  return sqrtm(a, args...)
+}
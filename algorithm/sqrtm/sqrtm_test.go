@@ -0,0 +1,106 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqrtm
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestSqrtm1(test *testing.T) {
+  // matrix with real and complex eigenvalues
+  a := NewDenseFloat64Matrix([]float64{
+    4, 1, 0, 2,
+    1, 3, 1, 0,
+   -2, 0, 5, 1,
+    0, 1,-1, 2 }, 4, 4)
+
+  x, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  r := NullDenseFloat64Matrix(4, 4)
+  r.MdotM(x, x)
+  if !r.Equals(a, 1e-10) {
+    test.Error("test failed")
+  }
+}
+
+func TestSqrtm2(test *testing.T) {
+  // rotation by 2 phi, the principal square root is the rotation by phi
+  phi := 0.4
+  a := NewDenseFloat64Matrix([]float64{
+    math.Cos(2*phi), -math.Sin(2*phi),
+    math.Sin(2*phi),  math.Cos(2*phi) }, 2, 2)
+  b := NewDenseFloat64Matrix([]float64{
+    math.Cos(phi), -math.Sin(phi),
+    math.Sin(phi),  math.Cos(phi) }, 2, 2)
+
+  if x, err := Run(a); err != nil {
+    test.Error(err)
+  } else if !x.Equals(b, 1e-10) {
+    test.Error("test failed")
+  }
+}
+
+func TestSqrtm3(test *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    -1, 0,
+     0, 1 }, 2, 2)
+  if _, err := Run(a); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestSqrtm4(test *testing.T) {
+  // compare derivatives with finite differences
+  v := []float64{
+    4, 1, 0,
+    1, 3, 2,
+   -2, 0, 5 }
+  a := NewDenseReal64Matrix(v, 3, 3)
+  a.Variables(1)
+
+  x, err := Run(a)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  h := 1e-6
+  for k := 0; k < 9; k++ {
+    v1 := append([]float64{}, v...)
+    v2 := append([]float64{}, v...)
+    v1[k] += h
+    v2[k] -= h
+    x1, _ := Run(NewDenseFloat64Matrix(v1, 3, 3))
+    x2, _ := Run(NewDenseFloat64Matrix(v2, 3, 3))
+    for i := 0; i < 3; i++ {
+      for j := 0; j < 3; j++ {
+        d := (x1.At(i, j).GetFloat64() - x2.At(i, j).GetFloat64())/(2*h)
+        if math.Abs(x.At(i, j).GetDerivative(k) - d) > 1e-6 {
+          test.Errorf("test failed for derivative %d of element (%d,%d)", k, i, j)
+        }
+      }
+    }
+  }
+}
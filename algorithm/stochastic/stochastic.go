@@ -0,0 +1,295 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stochastic
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "math/rand"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+// Objective function evaluated on a minibatch, i.e. a subset of indices
+// of the data set
+type Objective func(batch []int, x ConstVector) (MagicScalar, error)
+
+type Method struct {
+  Value MethodType
+}
+
+type BatchSize struct {
+  Value int
+}
+
+type LearningRate struct {
+  Value float64
+}
+
+type Schedule struct {
+  Value ScheduleType
+}
+
+type Epsilon struct {
+  Value float64
+}
+
+// Maximum number of epochs
+type MaxIterations struct {
+  Value int
+}
+
+type Seed struct {
+  Value int64
+}
+
+// Called after every epoch with the current position, the average
+// objective value over all minibatches of the epoch, and the epoch
+type Hook struct {
+  Value func(x ConstVector, y ConstScalar, epoch int) bool
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns true if the maximum relative change of x is smaller than epsilon
+func evalStopping(x1, x2 DenseFloat64Vector, epsilon float64) (bool, error) {
+  max_x     := 0.0
+  max_delta := 0.0
+  for i := 0; i < x1.Dim(); i++ {
+    if math.IsNaN(x2[i]) {
+      return true, fmt.Errorf("NaN value detected")
+    }
+    max_x     = math.Max(max_x    , math.Abs(x2[i]))
+    max_delta = math.Max(max_delta, math.Abs(x2[i] - x1[i]))
+  }
+  if max_x != 0.0 {
+    return max_delta/max_x <= epsilon, nil
+  } else {
+    return max_delta <= epsilon, nil
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func stochastic(f Objective, n int, x0 Vector, method MethodType, batchSize BatchSize, learningRate LearningRate, schedule Schedule, epsilon Epsilon, maxIterations MaxIterations, seed Seed, hook Hook) (Vector, error) {
+  d  := x0.Dim()
+  x1 := AsDenseFloat64Vector(x0)
+  x2 := AsDenseFloat64Vector(x0)
+  g  := NullDenseFloat64Vector(d)
+  // here comes the magic!
+  X  := NullDenseReal64Vector(d)
+  // average objective value
+  y  := NullFloat64()
+  // random number generator for shuffling the data
+  r  := rand.New(rand.NewSource(seed.Value))
+  // indices of all data points
+  p  := make([]int, n)
+  for i := 0; i < n; i++ {
+    p[i] = i
+  }
+  method.Init(d)
+  // iteration counter
+  t := 0
+  for epoch := 0; epoch < maxIterations.Value; epoch++ {
+    lr := learningRate.Value
+    if schedule.Value != nil {
+      lr = schedule.Value.Rate(lr, epoch)
+    }
+    r.Shuffle(n, func(i, j int) { p[i], p[j] = p[j], p[i] })
+    y.Reset()
+    for i := 0; i < n; i += batchSize.Value {
+      batch := p[i:min(i+batchSize.Value, n)]
+      // evaluate objective function on minibatch
+      X.Set(x2)
+      X.Variables(1)
+      z, err := f(batch, X)
+      if err != nil {
+        return x2, err
+      }
+      g.Reset()
+      for j := 0; j < z.GetN(); j++ {
+        g[j] = z.GetDerivative(j)
+      }
+      y.Add(y, ConstFloat64(z.GetFloat64()*float64(len(batch))/float64(n)))
+      t++
+      method.Update(x2, g, lr, t)
+    }
+    // execute hook if available
+    if hook.Value != nil && hook.Value(x2, y, epoch) {
+      break
+    }
+    // evaluate stop criterion
+    if stop, err := evalStopping(x1, x2, epsilon.Value); err != nil {
+      return x2, err
+    } else if stop {
+      break
+    }
+    x1.Set(x2)
+  }
+  return x2, nil
+}
+
+func min(a, b int) int {
+  if a < b {
+    return a
+  }
+  return b
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize an objective function f that is defined on a data set of size n
+// with a stochastic minibatch method. In every epoch the data set is
+// shuffled and split into minibatches, which are passed to f. Optional
+// arguments:
+//
+//   Method       : optimization method, i.e. SGD (default), Adam, AdamW,
+//                  AdaGrad, RMSProp or a custom MethodType; the state of
+//                  the method is reset at the beginning of Run
+//   BatchSize    : size of minibatches (default 1)
+//   LearningRate : initial learning rate (default 0.01)
+//   Schedule     : adapts the learning rate after every epoch
+//   Epsilon      : stop if the maximum relative change of x within an epoch
+//                  is smaller than epsilon (default 0)
+//   MaxIterations: maximum number of epochs (default 100)
+//   Seed         : seed of the random number generator used for shuffling
+//   Hook         : called after every epoch, stop if it returns true
+func Run(f Objective, n int, x0 Vector, args ...interface{}) (Vector, error) {
+
+  method        := Method       {&SGD{}}
+  batchSize     := BatchSize    {   1}
+  learningRate  := LearningRate {1e-2}
+  schedule      := Schedule     { nil}
+  epsilon       := Epsilon      { 0.0}
+  maxIterations := MaxIterations{ 100}
+  seed          := Seed         {   0}
+  hook          := Hook         { nil}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Method:
+      method = a
+    case BatchSize:
+      batchSize = a
+    case LearningRate:
+      learningRate = a
+    case Schedule:
+      schedule = a
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Seed:
+      seed = a
+    case Hook:
+      hook = a
+    default:
+      panic("Stochastic(): Invalid optional argument!")
+    }
+  }
+  if n < 1 {
+    return nil, fmt.Errorf("invalid data set size: %d", n)
+  }
+  if batchSize.Value < 1 {
+    return nil, fmt.Errorf("invalid batch size: %d", batchSize.Value)
+  }
+  if method.Value == nil {
+    return nil, fmt.Errorf("no optimization method given")
+  }
+  switch s := schedule.Value.(type) {
+  case StepDecay:
+    if s.Step < 1 {
+      return nil, fmt.Errorf("invalid step size of learning rate schedule: %d", s.Step)
+    }
+  case *StepDecay:
+    if s == nil {
+      return nil, fmt.Errorf("invalid learning rate schedule")
+    }
+    if s.Step < 1 {
+      return nil, fmt.Errorf("invalid step size of learning rate schedule: %d", s.Step)
+    }
+  }
+  return stochastic(f, n, x0, method.Value, batchSize, learningRate, schedule, epsilon, maxIterations, seed, hook)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. Iterations are epochs and evaluations are calls
+// of f on minibatches. The final value and gradient norm are computed on the
+// full data set. Accepts the same arguments as Run and additionally
+// optimize.Trace.
+func Minimize(f Objective, n int, x0 Vector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook    := Hook   { nil}
+  epsilon := Epsilon{ 0.0}
+  options := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(batch []int, x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f(batch, x)
+  }
+  // position after the previous epoch
+  x1 := AsDenseFloat64Vector(x0)
+  // stop criterion of the last epoch
+  converged := false
+  // the hook is called after every epoch, where the gradient on the full
+  // data set is not available
+  options = append(options, Hook{func(x ConstVector, y ConstScalar, epoch int) bool {
+    x2 := AsDenseFloat64Vector(x)
+    converged, _ = evalStopping(x1, x2, epsilon.Value)
+    x1 = x2
+    r.Iteration(x, y.GetFloat64(), math.NaN())
+    return hook.Value != nil && r.Hook(hook.Value(x, y, epoch))
+  }})
+  x, err := Run(g, n, x0, options...)
+  if x == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  // evaluate objective function on the full data set
+  batch := make([]int, n)
+  for i := 0; i < n; i++ {
+    batch[i] = i
+  }
+  y, gnorm, e := optimize.Evaluate(func(x ConstVector) (MagicScalar, error) { return f(batch, x) }, x)
+  switch {
+  case err != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), err
+  case e != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), e
+  case converged:
+    return r.Result(x, y, gnorm, optimize.Converged), nil
+  default:
+    return r.Result(x, y, gnorm, optimize.MaxIterationsReached), nil
+  }
+}
@@ -0,0 +1,171 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stochastic
+
+/* -------------------------------------------------------------------------- */
+
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// Update rule of a stochastic optimization method. Methods keep their state
+// (e.g. moment estimates) between updates. Init is called by Run for a
+// problem of dimension n and resets the state, so that a method can be
+// reused, but must not be shared by concurrent runs. Update performs a
+// step at x with minibatch gradient g and learning rate lr, where t counts
+// the updates starting at one.
+type MethodType interface {
+  Init  (n int)
+  Update(x, g DenseFloat64Vector, lr float64, t int)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Stochastic gradient descent with (Nesterov) momentum
+type SGD struct {
+  Momentum float64
+  Nesterov bool
+  v        DenseFloat64Vector
+}
+
+func (obj *SGD) Init(n int) {
+  obj.v = NullDenseFloat64Vector(n)
+}
+
+func (obj *SGD) Update(x, g DenseFloat64Vector, lr float64, t int) {
+  for i := 0; i < x.Dim(); i++ {
+    // v = mu v + g
+    obj.v[i] = obj.Momentum*obj.v[i] + g[i]
+    if obj.Nesterov {
+      x[i] -= lr*(g[i] + obj.Momentum*obj.v[i])
+    } else {
+      x[i] -= lr*obj.v[i]
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Adam (Kingma and Ba, 2014). Zero values of Beta1, Beta2 and Epsilon are
+// replaced by the defaults 0.9, 0.999 and 1e-8.
+type Adam struct {
+  Beta1   float64
+  Beta2   float64
+  Epsilon float64
+  m       DenseFloat64Vector
+  v       DenseFloat64Vector
+}
+
+func (obj *Adam) Init(n int) {
+  if obj.Beta1 == 0.0 {
+    obj.Beta1 = 0.9
+  }
+  if obj.Beta2 == 0.0 {
+    obj.Beta2 = 0.999
+  }
+  if obj.Epsilon == 0.0 {
+    obj.Epsilon = 1e-8
+  }
+  obj.m = NullDenseFloat64Vector(n)
+  obj.v = NullDenseFloat64Vector(n)
+}
+
+func (obj *Adam) Update(x, g DenseFloat64Vector, lr float64, t int) {
+  // bias corrections
+  c1 := 1.0 - math.Pow(obj.Beta1, float64(t))
+  c2 := 1.0 - math.Pow(obj.Beta2, float64(t))
+  for i := 0; i < x.Dim(); i++ {
+    obj.m[i] = obj.Beta1*obj.m[i] + (1.0 - obj.Beta1)*g[i]
+    obj.v[i] = obj.Beta2*obj.v[i] + (1.0 - obj.Beta2)*g[i]*g[i]
+    x[i] -= lr*(obj.m[i]/c1)/(math.Sqrt(obj.v[i]/c2) + obj.Epsilon)
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Adam with decoupled weight decay (Loshchilov and Hutter, 2017). Zero
+// values of WeightDecay are replaced by the default 0.01.
+type AdamW struct {
+  Adam
+  WeightDecay float64
+}
+
+func (obj *AdamW) Init(n int) {
+  if obj.WeightDecay == 0.0 {
+    obj.WeightDecay = 1e-2
+  }
+  obj.Adam.Init(n)
+}
+
+func (obj *AdamW) Update(x, g DenseFloat64Vector, lr float64, t int) {
+  for i := 0; i < x.Dim(); i++ {
+    x[i] -= lr*obj.WeightDecay*x[i]
+  }
+  obj.Adam.Update(x, g, lr, t)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// AdaGrad (Duchi et al., 2011). A zero value of Epsilon is replaced by the
+// default 1e-8.
+type AdaGrad struct {
+  Epsilon float64
+  s       DenseFloat64Vector
+}
+
+func (obj *AdaGrad) Init(n int) {
+  if obj.Epsilon == 0.0 {
+    obj.Epsilon = 1e-8
+  }
+  obj.s = NullDenseFloat64Vector(n)
+}
+
+func (obj *AdaGrad) Update(x, g DenseFloat64Vector, lr float64, t int) {
+  for i := 0; i < x.Dim(); i++ {
+    obj.s[i] += g[i]*g[i]
+    x[i] -= lr*g[i]/(math.Sqrt(obj.s[i]) + obj.Epsilon)
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// RMSProp (Tieleman and Hinton, 2012). Zero values of Rho and Epsilon are
+// replaced by the defaults 0.9 and 1e-8.
+type RMSProp struct {
+  Rho     float64
+  Epsilon float64
+  s       DenseFloat64Vector
+}
+
+func (obj *RMSProp) Init(n int) {
+  if obj.Rho == 0.0 {
+    obj.Rho = 0.9
+  }
+  if obj.Epsilon == 0.0 {
+    obj.Epsilon = 1e-8
+  }
+  obj.s = NullDenseFloat64Vector(n)
+}
+
+func (obj *RMSProp) Update(x, g DenseFloat64Vector, lr float64, t int) {
+  for i := 0; i < x.Dim(); i++ {
+    obj.s[i] = obj.Rho*obj.s[i] + (1.0 - obj.Rho)*g[i]*g[i]
+    x[i] -= lr*g[i]/(math.Sqrt(obj.s[i]) + obj.Epsilon)
+  }
+}
@@ -0,0 +1,85 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stochastic
+
+/* -------------------------------------------------------------------------- */
+
+import   "math"
+
+/* -------------------------------------------------------------------------- */
+
+// Learning rate schedule, which computes the learning rate of an epoch
+// given the initial learning rate lr
+type ScheduleType interface {
+  Rate(lr float64, epoch int) float64
+}
+
+// Adapter for using a function as learning rate schedule
+type ScheduleFunc func(lr float64, epoch int) float64
+
+func (f ScheduleFunc) Rate(lr float64, epoch int) float64 {
+  return f(lr, epoch)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Multiply the learning rate by Gamma every Step epochs (Step >= 1)
+type StepDecay struct {
+  Gamma float64
+  Step  int
+}
+
+func (obj StepDecay) Rate(lr float64, epoch int) float64 {
+  return lr*math.Pow(obj.Gamma, float64(epoch/obj.Step))
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Multiply the learning rate by Gamma after every epoch
+type ExponentialDecay struct {
+  Gamma float64
+}
+
+func (obj ExponentialDecay) Rate(lr float64, epoch int) float64 {
+  return lr*math.Pow(obj.Gamma, float64(epoch))
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Learning rate lr/(1 + Decay*epoch)
+type InverseTimeDecay struct {
+  Decay float64
+}
+
+func (obj InverseTimeDecay) Rate(lr float64, epoch int) float64 {
+  return lr/(1.0 + obj.Decay*float64(epoch))
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Cosine annealing from lr to MinRate within Epochs epochs
+type CosineAnnealing struct {
+  Epochs  int
+  MinRate float64
+}
+
+func (obj CosineAnnealing) Rate(lr float64, epoch int) float64 {
+  if epoch >= obj.Epochs {
+    return obj.MinRate
+  }
+  return obj.MinRate + 0.5*(lr - obj.MinRate)*(1.0 + math.Cos(math.Pi*float64(epoch)/float64(obj.Epochs)))
+}
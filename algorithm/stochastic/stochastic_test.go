@@ -0,0 +1,188 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stochastic
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "math/rand"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// least squares objective of the linear model y = a + b u
+func newObjective(n int) Objective {
+  r := rand.New(rand.NewSource(1))
+  u := make([]float64, n)
+  y := make([]float64, n)
+  for i := 0; i < n; i++ {
+    u[i] = 2.0*r.Float64() - 1.0
+    y[i] = 2.0 + 3.0*u[i]
+  }
+  f := func(batch []int, x ConstVector) (MagicScalar, error) {
+    s := NullReal64()
+    t := NullReal64()
+    for _, i := range batch {
+      t.Mul(x.ConstAt(1), ConstFloat64(u[i]))
+      t.Add(t, x.ConstAt(0))
+      t.Sub(t, ConstFloat64(y[i]))
+      t.Mul(t, t)
+      s.Add(s, t)
+    }
+    s.Div(s, ConstFloat64(float64(len(batch))))
+    return s, nil
+  }
+  return f
+}
+
+func checkSolution(test *testing.T, x ConstVector, err error, name string) {
+  if err != nil {
+    test.Error(err); return
+  }
+  if math.Abs(x.ConstAt(0).GetFloat64() - 2.0) > 1e-3 || math.Abs(x.ConstAt(1).GetFloat64() - 3.0) > 1e-3 {
+    test.Errorf("%s test failed", name)
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+func TestSGD(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  x, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.1})
+  checkSolution(test, x, err, "SGD")
+  x, err  = Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.05},
+    Method{&SGD{Momentum: 0.9, Nesterov: true}})
+  checkSolution(test, x, err, "Nesterov")
+}
+
+func TestAdam(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  x, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.1},
+    Method{&Adam{}},
+    Schedule{ExponentialDecay{0.9}})
+  checkSolution(test, x, err, "Adam")
+}
+
+func TestAdamW(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  // weight decay shrinks the solution towards zero
+  x, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.1},
+    Method{&AdamW{WeightDecay: 1e-2}},
+    Schedule{ExponentialDecay{0.9}})
+  if err != nil {
+    test.Error(err); return
+  }
+  if a := x.ConstAt(0).GetFloat64(); a > 2.0 || a < 1.9 {
+    test.Error("AdamW test failed")
+  }
+}
+
+func TestAdaGrad(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  x, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{1.0},
+    Method{&AdaGrad{}})
+  checkSolution(test, x, err, "AdaGrad")
+}
+
+func TestRMSProp(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  x, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.01},
+    Method{&RMSProp{}},
+    Schedule{StepDecay{0.5, 10}})
+  checkSolution(test, x, err, "RMSProp")
+}
+
+func TestSeed(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  run := func(seed int64) ConstVector {
+    x, _ := Run(f, n, NullDenseFloat64Vector(2), BatchSize{7}, MaxIterations{2}, Seed{seed})
+    return x
+  }
+  equals := func(x1, x2 ConstVector) bool {
+    return x1.ConstAt(0).GetFloat64() == x2.ConstAt(0).GetFloat64() &&
+           x1.ConstAt(1).GetFloat64() == x2.ConstAt(1).GetFloat64()
+  }
+  if x1, x2 := run(1), run(1); !equals(x1, x2) {
+    test.Error("test failed")
+  }
+  if x1, x2 := run(1), run(2); equals(x1, x2) {
+    test.Error("test failed")
+  }
+}
+
+func TestHook(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  epochs := 0
+  hook := func(x ConstVector, y ConstScalar, epoch int) bool {
+    epochs++
+    return y.GetFloat64() < 1e-4
+  }
+  if _, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.1}, Hook{hook}); err != nil {
+    test.Error(err)
+  }
+  if epochs == 0 || epochs >= 100 {
+    test.Error("test failed")
+  }
+}
+
+// plain gradient descent as custom method
+type customMethod struct {
+  updates int
+}
+
+func (obj *customMethod) Init(n int) {
+  obj.updates = 0
+}
+
+func (obj *customMethod) Update(x, g DenseFloat64Vector, lr float64, t int) {
+  obj.updates++
+  for i := 0; i < x.Dim(); i++ {
+    x[i] -= lr*g[i]
+  }
+}
+
+func TestCustom(test *testing.T) {
+  n := 200
+  f := newObjective(n)
+  m := &customMethod{}
+  s := ScheduleFunc(func(lr float64, epoch int) float64 {
+    return lr/float64(epoch/20 + 1)
+  })
+  x, err := Run(f, n, NullDenseFloat64Vector(2), BatchSize{10}, LearningRate{0.1},
+    Method{m}, Schedule{s})
+  checkSolution(test, x, err, "custom")
+  if m.updates == 0 || m.updates%20 != 0 {
+    test.Error("test failed")
+  }
+  // invalid schedule
+  if _, err := Run(f, n, NullDenseFloat64Vector(2), Schedule{StepDecay{0.5, 0}}); err == nil {
+    test.Error("test failed")
+  }
+  if _, err := Run(f, n, NullDenseFloat64Vector(2), Schedule{&StepDecay{0.5, 0}}); err == nil {
+    test.Error("test failed")
+  }
+}
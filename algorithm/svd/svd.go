@@ -207,6 +207,10 @@ func golubKahanSVD(inSitu *InSitu, epsilon float64) (Matrix, Matrix, Matrix, err
 
   H, U, V, _ := householderBidiagonalization.Run(A, computeU, computeV, &inSitu.HouseholderBidiagonalization)
   B := H.Slice(0,n,0,n)
+  // Givens rotations are applied to U^T
+  if U != nil {
+    U = U.T()
+  }
 
   for p, q := 0, 0; q < n; {
 
@@ -341,5 +345,8 @@ func Run(a Matrix, args ...interface{}) (Matrix, Matrix, Matrix, error) {
   if inSitu.HouseholderBidiagonalization.T3 == nil {
     inSitu.HouseholderBidiagonalization.T3 = inSitu.T2
   }
+  if a_, ok := a.(*DenseFloat64Matrix); ok && GonumBackend() {
+    return gonumSVD(a_, inSitu)
+  }
   return golubKahanSVD(inSitu, epsilon)
 }
@@ -0,0 +1,58 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package svd
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+import   "gonum.org/v1/gonum/mat"
+
+/* -------------------------------------------------------------------------- */
+
+func gonumSVD(a *DenseFloat64Matrix, inSitu *InSitu) (Matrix, Matrix, Matrix, error) {
+  kind := mat.SVDNone
+  if inSitu.U != nil {
+    kind |= mat.SVDFullU
+  }
+  if inSitu.V != nil {
+    kind |= mat.SVDFullV
+  }
+  r := mat.SVD{}
+  if !r.Factorize(AsGonumMatrix(a), kind) {
+    return nil, nil, nil, fmt.Errorf("singular value decomposition failed")
+  }
+  // inSitu.A might be identical to a, so that it may
+  // only be overwritten after the factorization
+  H := inSitu.A
+  H.Reset()
+  for i, s := range r.Values(nil) {
+    H.At(i, i).SetFloat64(s)
+  }
+  if inSitu.U != nil {
+    u := mat.Dense{}
+    r.UTo(&u)
+    inSitu.U.Set(FromGonumDense(&u))
+  }
+  if inSitu.V != nil {
+    v := mat.Dense{}
+    r.VTo(&v)
+    inSitu.V.Set(FromGonumDense(&v))
+  }
+  return H, inSitu.U, inSitu.V, nil
+}
@@ -0,0 +1,303 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package svd
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+import   "math/rand"
+import   "sort"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+// A singular triplet is accepted if the residual ||A^T u - sigma v|| is
+// smaller than tolerance times the largest singular value
+type Tolerance struct {
+  Value float64
+}
+
+// Maximum number of restarts
+type MaxIterations struct {
+  Value int
+}
+
+// Number of Lanczos vectors, which must be larger than the number of
+// requested singular values (default 2k+10)
+type SubspaceDimension struct {
+  Value int
+}
+
+// Seed for the random number generator
+type Seed struct {
+  Value int64
+}
+
+/* -------------------------------------------------------------------------- */
+
+func dot(a, b DenseFloat64Vector) float64 {
+  r := 0.0
+  for i := range a {
+    r += a[i]*b[i]
+  }
+  return r
+}
+
+func norm(a DenseFloat64Vector) float64 {
+  return math.Sqrt(dot(a, a))
+}
+
+// a = a + c b
+func axpy(a DenseFloat64Vector, c float64, b DenseFloat64Vector) {
+  for i := range a {
+    a[i] += c*b[i]
+  }
+}
+
+func scale(a DenseFloat64Vector, c float64) {
+  for i := range a {
+    a[i] *= c
+  }
+}
+
+// Orthogonalize x against all vectors in V (twice) and normalize x. Returns
+// the norm of x after orthogonalization. If x is numerically zero, it is
+// replaced by a random unit vector orthogonal to V.
+func orthonormalize(x DenseFloat64Vector, V []DenseFloat64Vector, rng *rand.Rand) float64 {
+  r := norm(x)
+  for pass := 0; pass < 2; pass++ {
+    for _, v := range V {
+      axpy(x, -dot(v, x), v)
+    }
+  }
+  s := norm(x)
+  if s > 1e-12*r && s > 0.0 {
+    scale(x, 1.0/s)
+    return s
+  }
+  // try a random vector
+  for i := range x {
+    x[i] = rng.NormFloat64()
+  }
+  for pass := 0; pass < 2; pass++ {
+    for _, v := range V {
+      axpy(x, -dot(v, x), v)
+    }
+  }
+  if t := norm(x); t > 1e-8 {
+    scale(x, 1.0/t)
+  } else {
+    x.Reset()
+  }
+  return 0.0
+}
+
+// Compute x = sum_j V_j y_j, where y is the jth column of Y
+func combine(x DenseFloat64Vector, V []DenseFloat64Vector, Y ConstMatrix, j int) {
+  x.Reset()
+  for i, v := range V {
+    axpy(x, Y.ConstAt(i, j).GetFloat64(), v)
+  }
+}
+
+// Compute the SVD B = U diag(s) V^T of a small square matrix with singular
+// values sorted in descending order. Returns the singular values and a
+// permutation p, such that the ith singular value belongs to column p[i]
+// of U and V. The signs of negative singular values computed by Run are
+// returned separately and must be applied to the columns of U.
+func smallSVD(B Matrix) ([]float64, []int, []float64, Matrix, Matrix, error) {
+  n, _ := B.Dims()
+  h, u, v, err := Run(B, ComputeU{true}, ComputeV{true})
+  if err != nil {
+    return nil, nil, nil, nil, nil, err
+  }
+  s    := make([]float64, n)
+  p    := make([]int, n)
+  sign := make([]float64, n)
+  for i := 0; i < n; i++ {
+    s   [i] = math.Abs(h.ConstAt(i, i).GetFloat64())
+    sign[i] = 1.0
+    if h.ConstAt(i, i).GetFloat64() < 0.0 {
+      sign[i] = -1.0
+    }
+    p[i] = i
+  }
+  sort.SliceStable(p, func(i, j int) bool { return s[p[i]] > s[p[j]] })
+  r1 := make([]float64, n)
+  r2 := make([]float64, n)
+  for i := 0; i < n; i++ {
+    r1[i] = s   [p[i]]
+    r2[i] = sign[p[i]]
+  }
+  return r1, p, r2, u, v, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+func lanczosSVD(a ConstMatrix, k, s, maxIterations int, tolerance float64, computeU, computeV bool, rng *rand.Rand) (Vector, Matrix, Matrix, error) {
+  m, n := a.Dims()
+
+  P := make([]DenseFloat64Vector, s+1)
+  Q := make([]DenseFloat64Vector, s)
+  for j := range P {
+    P[j] = NullDenseFloat64Vector(n)
+  }
+  for j := range Q {
+    Q[j] = NullDenseFloat64Vector(m)
+  }
+  // B = Q^T A P
+  B := NullDenseFloat64Matrix(s, s)
+  // initial vector
+  orthonormalize(P[0], nil, rng)
+
+  // number of Ritz vectors kept at a restart
+  l := 0
+  for iteration := 0; ; iteration++ {
+    beta := 0.0
+    // Golub-Kahan-Lanczos bidiagonalization with full reorthogonalization
+    for j := l; j < s; j++ {
+      Q[j].MdotV(a, P[j])
+      // projections of A p_j on q_0, ..., q_{j-1}, which are nonzero
+      // only for j-1 and after a restart
+      for pass := 0; pass < 2; pass++ {
+        for i := 0; i < j; i++ {
+          h := dot(Q[i], Q[j])
+          B.At(i, j).SetFloat64(B.At(i, j).GetFloat64() + h)
+          axpy(Q[j], -h, Q[i])
+        }
+      }
+      B.At(j, j).SetFloat64(orthonormalize(Q[j], Q[0:j], rng))
+      P[j+1].VdotM(Q[j], a)
+      beta = orthonormalize(P[j+1], P[0:j+1], rng)
+    }
+    sigma, p, sign, U, V, err := smallSVD(B)
+    if err != nil {
+      return nil, nil, nil, err
+    }
+    // the residual of the ith Ritz triplet is ||A^T u - sigma v|| = |beta u_s|
+    converged := 0
+    for i := 0; i < k; i++ {
+      if math.Abs(beta*U.ConstAt(s-1, p[i]).GetFloat64()) <= tolerance*sigma[0] {
+        converged++
+      }
+    }
+    if converged == k || iteration >= maxIterations {
+      r := NullDenseFloat64Vector(k)
+      copy(r, sigma[0:k])
+      var u, v Matrix
+      if computeU {
+        u  = NullDenseFloat64Matrix(m, k)
+        x := NullDenseFloat64Vector(m)
+        for i := 0; i < k; i++ {
+          combine(x, Q, U, p[i])
+          scale(x, sign[i])
+          ColView(u, i).Set(x)
+        }
+      }
+      if computeV {
+        v  = NullDenseFloat64Matrix(n, k)
+        x := NullDenseFloat64Vector(n)
+        for i := 0; i < k; i++ {
+          combine(x, P[0:s], V, p[i])
+          ColView(v, i).Set(x)
+        }
+      }
+      if converged < k {
+        return r, u, v, fmt.Errorf("maximum number of iterations reached")
+      }
+      return r, u, v, nil
+    }
+    // thick restart with the l largest Ritz vectors, the residual vector
+    // p_s becomes the next right Lanczos vector
+    l = k + (s-k)/2
+    if l > s-1 {
+      l = s-1
+    }
+    newP := make([]DenseFloat64Vector, l)
+    newQ := make([]DenseFloat64Vector, l)
+    for i := 0; i < l; i++ {
+      newP[i] = NullDenseFloat64Vector(n)
+      newQ[i] = NullDenseFloat64Vector(m)
+      combine(newP[i], P[0:s], V, p[i])
+      combine(newQ[i], Q, U, p[i])
+      scale(newQ[i], sign[i])
+    }
+    P[l].Set(P[s])
+    for i := 0; i < l; i++ {
+      P[i].Set(newP[i])
+      Q[i].Set(newQ[i])
+    }
+    B.Reset()
+    for i := 0; i < l; i++ {
+      B.At(i, i).SetFloat64(sigma[i])
+    }
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute the k largest singular values of A with a thick-restart Lanczos
+// bidiagonalization. Only matrix vector products with A and A^T are
+// required, so that A may be a sparse matrix. The singular values are
+// returned in descending order, U (m x k) and V (n x k) contain the
+// corresponding left and right singular vectors if requested with the
+// ComputeU and ComputeV options. Computations are performed in float64.
+func RunLanczos(a ConstMatrix, k int, args ...interface{}) (Vector, Matrix, Matrix, error) {
+  m, n := a.Dims()
+
+  computeU      := false
+  computeV      := false
+  tolerance     := 1e-10
+  maxIterations := 300
+  seed          := int64(1)
+  s             := 2*k + 10
+
+  // loop over optional arguments
+  for _, arg := range args {
+    switch tmp := arg.(type) {
+    case ComputeU:
+      computeU = tmp.Value
+    case ComputeV:
+      computeV = tmp.Value
+    case Tolerance:
+      tolerance = tmp.Value
+    case MaxIterations:
+      maxIterations = tmp.Value
+    case SubspaceDimension:
+      s = tmp.Value
+    case Seed:
+      seed = tmp.Value
+    default:
+      panic("RunLanczos(): Invalid optional argument!")
+    }
+  }
+  if s > m {
+    s = m
+  }
+  if s > n {
+    s = n
+  }
+  if k < 1 || k > s {
+    return nil, nil, nil, fmt.Errorf("invalid number of singular values")
+  }
+  if s == k && s < m && s < n {
+    return nil, nil, nil, fmt.Errorf("subspace dimension must be larger than the number of singular values")
+  }
+  return lanczosSVD(a, k, s, maxIterations, tolerance, computeU, computeV, rand.New(rand.NewSource(seed)))
+}
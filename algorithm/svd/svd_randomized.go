@@ -0,0 +1,169 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package svd
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math/rand"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/qr"
+
+/* -------------------------------------------------------------------------- */
+
+// Number of additional random vectors used for sampling the range of A
+// (default 10)
+type Oversampling struct {
+  Value int
+}
+
+// Number of power iterations, which improve the accuracy if the singular
+// values decay slowly (default 2)
+type PowerIterations struct {
+  Value int
+}
+
+/* -------------------------------------------------------------------------- */
+
+func orthonormalizeAll(Y []DenseFloat64Vector, rng *rand.Rand) {
+  for j := range Y {
+    orthonormalize(Y[j], Y[0:j], rng)
+  }
+}
+
+func randomizedSVD(a ConstMatrix, k, l, q int, computeU, computeV bool, rng *rand.Rand) (Vector, Matrix, Matrix, error) {
+  m, n := a.Dims()
+
+  Y := make([]DenseFloat64Vector, l)
+  Z := make([]DenseFloat64Vector, l)
+  for j := 0; j < l; j++ {
+    Y[j] = NullDenseFloat64Vector(m)
+    Z[j] = NullDenseFloat64Vector(n)
+    for i := 0; i < n; i++ {
+      Z[j][i] = rng.NormFloat64()
+    }
+  }
+  // sample the range of A: Y = A Z
+  for j := 0; j < l; j++ {
+    Y[j].MdotV(a, Z[j])
+  }
+  orthonormalizeAll(Y, rng)
+  // power iterations Y = (A A^T)^q A Z with orthonormalization at every
+  // step
+  for i := 0; i < q; i++ {
+    for j := 0; j < l; j++ {
+      Z[j].VdotM(Y[j], a)
+    }
+    orthonormalizeAll(Z, rng)
+    for j := 0; j < l; j++ {
+      Y[j].MdotV(a, Z[j])
+    }
+    orthonormalizeAll(Y, rng)
+  }
+  // B^T = A^T Y, where the columns of Y are an orthonormal basis
+  // of the range of A
+  Bt := NullDenseFloat64Matrix(n, l)
+  for j := 0; j < l; j++ {
+    Z[j].VdotM(Y[j], a)
+    ColView(Bt, j).Set(Z[j])
+  }
+  // B^T = Q R and R = U_R diag(s) V_R^T, such that A = Y B = (Y V_R) diag(s)
+  // (Q U_R)^T
+  Q, R, _, err := qr.Run(Bt, qr.Thin{Value: true})
+  if err != nil {
+    return nil, nil, nil, err
+  }
+  sigma, p, sign, Ur, Vr, err := smallSVD(R)
+  if err != nil {
+    return nil, nil, nil, err
+  }
+  r := NullDenseFloat64Vector(k)
+  copy(r, sigma[0:k])
+
+  var u, v Matrix
+  if computeU {
+    u  = NullDenseFloat64Matrix(m, k)
+    x := NullDenseFloat64Vector(m)
+    for i := 0; i < k; i++ {
+      combine(x, Y, Vr, p[i])
+      ColView(u, i).Set(x)
+    }
+  }
+  if computeV {
+    v  = NullDenseFloat64Matrix(n, k)
+    x := NullDenseFloat64Vector(n)
+    for i := 0; i < k; i++ {
+      x.MdotV(Q, ColView(Ur, p[i]))
+      scale(x, sign[i])
+      ColView(v, i).Set(x)
+    }
+  }
+  return r, u, v, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Compute an approximation of the k largest singular values of A with a
+// randomized range finder (Halko, Martinsson and Tropp, 2011). The range
+// of A is sampled with k plus Oversampling random vectors, the accuracy
+// is improved with PowerIterations. Only matrix vector products with A and
+// A^T are required, so that A may be a sparse matrix. The singular values
+// are returned in descending order, U (m x k) and V (n x k) contain the
+// corresponding left and right singular vectors if requested with the
+// ComputeU and ComputeV options. Computations are performed in float64.
+func RunRandomized(a ConstMatrix, k int, args ...interface{}) (Vector, Matrix, Matrix, error) {
+  m, n := a.Dims()
+
+  computeU     := false
+  computeV     := false
+  oversampling := 10
+  powerIter    := 2
+  seed         := int64(1)
+
+  // loop over optional arguments
+  for _, arg := range args {
+    switch tmp := arg.(type) {
+    case ComputeU:
+      computeU = tmp.Value
+    case ComputeV:
+      computeV = tmp.Value
+    case Oversampling:
+      oversampling = tmp.Value
+    case PowerIterations:
+      powerIter = tmp.Value
+    case Seed:
+      seed = tmp.Value
+    default:
+      panic("RunRandomized(): Invalid optional argument!")
+    }
+  }
+  if k < 1 || k > m || k > n {
+    return nil, nil, nil, fmt.Errorf("invalid number of singular values")
+  }
+  if oversampling < 0 || powerIter < 0 {
+    return nil, nil, nil, fmt.Errorf("invalid optional argument")
+  }
+  l := k + oversampling
+  if l > m {
+    l = m
+  }
+  if l > n {
+    l = n
+  }
+  return randomizedSVD(a, k, l, powerIter, computeU, computeV, rand.New(rand.NewSource(seed)))
+}
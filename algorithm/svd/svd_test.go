@@ -20,6 +20,7 @@ package svd
 
 //import   "fmt"
 import   "math"
+import   "math/rand"
 import   "sort"
 import   "testing"
 
@@ -175,3 +176,135 @@ func Test6(test *testing.T) {
     test.Error("test failed")
   }
 }
+
+func TestRunReconstruction(test *testing.T) {
+  // U^T A V must be equal to the bidiagonal matrix of singular values for
+  // general dense matrices
+  t := NewFloat64(0.0)
+  g := rand.New(rand.NewSource(1))
+  for _, d := range [][2]int{{5, 3}, {4, 4}, {6, 6}, {8, 6}} {
+    m, n := d[0], d[1]
+    a := NullDenseFloat64Matrix(m, n)
+    for i := 0; i < m; i++ {
+      for j := 0; j < n; j++ {
+        a.At(i, j).SetFloat64(g.NormFloat64())
+      }
+    }
+    h, u, v, err := Run(a, ComputeU{true}, ComputeV{true})
+    if err != nil {
+      test.Error(err); continue
+    }
+    r := NullDenseFloat64Matrix(m, n)
+    r.MdotM(r.MdotM(u.T(), a), v)
+
+    if t.Mnorm(r.MsubM(r, h)).GetFloat64() > 1e-8 {
+      test.Errorf("test failed for %dx%d matrix", m, n)
+    }
+  }
+}
+
+func TestGonum(test *testing.T) {
+  t := NewFloat64(0.0)
+  a := NewDenseFloat64Matrix([]float64{
+    1, 1, 0,
+    0, 2, 1,
+    0, 0, 3,
+    1, 0, 1 }, 4, 3)
+
+  SetGonumBackend(true)
+  defer SetGonumBackend(false)
+
+  h, u, v, err := Run(a, ComputeU{true}, ComputeV{true})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  d := NullDenseFloat64Matrix(4, 3)
+  d.MdotM(d.MdotM(u.T(), a), v)
+
+  if t.Mnorm(d.MsubM(d, h)).GetFloat64() > 1e-8 {
+    test.Error("test failed")
+  }
+}
+
+/* -------------------------------------------------------------------------- */
+
+// random sparse matrix with about 10% non-zero elements
+func randomSparse(m, n int) Matrix {
+  r := NullSparseFloat64Matrix(m, n)
+  g := rand.New(rand.NewSource(2))
+  for i := 0; i < m; i++ {
+    for j := 0; j < n; j++ {
+      if g.Float64() < 0.1 {
+        r.At(i, j).SetFloat64(g.NormFloat64())
+      }
+    }
+  }
+  return r
+}
+
+// sparse matrix of rank 3 with singular values 5, 3 and 1
+func lowRankSparse(m, n int) Matrix {
+  r := NullSparseFloat64Matrix(m, n)
+  s := []float64{5, 3, 1}
+  for k := 0; k < 3; k++ {
+    // singular vectors with disjoint supports
+    for i := k; i < m; i += 3 {
+      for j := k; j < n; j += 3 {
+        r.At(i, j).SetFloat64(s[k]/math.Sqrt(float64(((m-k+2)/3)*((n-k+2)/3))))
+      }
+    }
+  }
+  return r
+}
+
+func checkTruncatedSVD(test *testing.T, a ConstMatrix, s Vector, u, v Matrix, expected []float64) {
+  m, _ := a.Dims()
+  x := NullDenseFloat64Vector(m)
+  for i, e := range expected {
+    if math.Abs(s.ConstAt(i).GetFloat64() - e) > 1e-8 {
+      test.Errorf("test failed for singular value `%d'", i)
+    }
+    // A v = sigma u
+    x.MdotV(a, v.Col(i))
+    x.VsubV(x, u.Col(i).VmulS(u.Col(i), s.ConstAt(i)))
+    if norm(x) > 1e-6 {
+      test.Errorf("test failed for singular vectors `%d'", i)
+    }
+  }
+}
+
+func TestLanczos(test *testing.T) {
+  a := randomSparse(300, 60)
+  h, _, _, _ := Run(a.CloneMatrix())
+  r := []float64{}
+  for i := 0; i < 60; i++ {
+    r = append(r, math.Abs(h.At(i, i).GetFloat64()))
+  }
+  sort.Sort(sort.Reverse(sort.Float64Slice(r)))
+
+  s, u, v, err := RunLanczos(a, 5, ComputeU{true}, ComputeV{true})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkTruncatedSVD(test, a, s, u, v, r[0:5])
+}
+
+func TestRandomized(test *testing.T) {
+  a := lowRankSparse(200, 40)
+
+  s, u, v, err := RunRandomized(a, 3, ComputeU{true}, ComputeV{true})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkTruncatedSVD(test, a, s, u, v, []float64{5, 3, 1})
+
+  s, u, v, err = RunLanczos(a, 3, ComputeU{true}, ComputeV{true})
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  checkTruncatedSVD(test, a, s, u, v, []float64{5, 3, 1})
+}
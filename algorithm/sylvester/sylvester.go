@@ -0,0 +1,204 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sylvester
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/lu"
+import   "github.com/pbenner/autodiff/algorithm/qrAlgorithm"
+
+/* -------------------------------------------------------------------------- */
+
+// Returns the first index and size of all diagonal blocks of a
+// quasi-triangular matrix
+func getBlocks(t ConstMatrix) ([]int, []int) {
+  n, _ := t.Dims()
+  start := []int{}
+  size  := []int{}
+  for i := 0; i < n; i++ {
+    start = append(start, i)
+    if i < n-1 && t.ConstAt(i+1, i).GetFloat64() != 0.0 {
+      size = append(size, 2)
+      i++
+    } else {
+      size = append(size, 1)
+    }
+  }
+  return start, size
+}
+
+// Solve the Sylvester equation A X + X B = C for small matrices A (p x p)
+// and B (q x q) by vectorization
+func sylvesterBlock(x, a, b, c Matrix) error {
+  p, _ := a.Dims()
+  q, _ := b.Dims()
+  m := NullDenseMatrix(x.ElementType(), p*q, p*q)
+  y := NullDenseVector(x.ElementType(), p*q)
+  for i := 0; i < p; i++ {
+    for j := 0; j < q; j++ {
+      // row of equation (i,j), unknown X_kl has index k + p*l
+      for k := 0; k < p; k++ {
+        m.At(i + p*j, k + p*j).Add(m.At(i + p*j, k + p*j), a.ConstAt(i, k))
+      }
+      for l := 0; l < q; l++ {
+        m.At(i + p*j, i + p*l).Add(m.At(i + p*j, i + p*l), b.ConstAt(l, j))
+      }
+      y.At(i + p*j).Set(c.ConstAt(i, j))
+    }
+  }
+  l, u, pi, err := lu.Run(m)
+  if err != nil {
+    return err
+  }
+  z, err := lu.Solve(l, u, pi, y)
+  if err != nil {
+    return err
+  }
+  for i := 0; i < p; i++ {
+    for j := 0; j < q; j++ {
+      x.At(i, j).Set(z.ConstAt(i + p*j))
+    }
+  }
+  return nil
+}
+
+// Solve S Y + Y T = F, where S and T are upper quasi-triangular. The
+// solution is computed block-wise starting at the bottom left corner.
+func sylvesterQuasiTriangular(s, t, f Matrix) (Matrix, error) {
+  m, _ := s.Dims()
+  n, _ := t.Dims()
+  e := f.ElementType()
+  y  := NullDenseMatrix(e, m, n)
+  c  := NullDenseMatrix(e, 2, 2)
+  t1 := NullScalar(e)
+
+  sStart, sSize := getBlocks(s)
+  tStart, tSize := getBlocks(t)
+
+  for j := 0; j < len(tStart); j++ {
+    j0, j1 := tStart[j], tStart[j]+tSize[j]
+    for i := len(sStart)-1; i >= 0; i-- {
+      i0, i1 := sStart[i], sStart[i]+sSize[i]
+      // C = F_ij - sum_k S_ik Y_kj - sum_l Y_il T_lj
+      ci := c.Slice(0, sSize[i], 0, tSize[j])
+      for r := i0; r < i1; r++ {
+        for q := j0; q < j1; q++ {
+          x := ci.At(r-i0, q-j0)
+          x.Set(f.ConstAt(r, q))
+          for k := i1; k < m; k++ {
+            t1.Mul(s.ConstAt(r, k), y.ConstAt(k, q))
+            x.Sub(x, t1)
+          }
+          for l := 0; l < j0; l++ {
+            t1.Mul(y.ConstAt(r, l), t.ConstAt(l, q))
+            x.Sub(x, t1)
+          }
+        }
+      }
+      if err := sylvesterBlock(y.Slice(i0, i1, j0, j1), s.Slice(i0, i1, i0, i1), t.Slice(j0, j1, j0, j1), ci); err != nil {
+        return nil, fmt.Errorf("equation does not have a unique solution")
+      }
+    }
+  }
+  return y, nil
+}
+
+func sylvester(a, b, c Matrix, args ...interface{}) (Matrix, error) {
+  m, _ := a.Dims()
+  n, _ := b.Dims()
+  e := c.ElementType()
+  if s := a.ElementType(); s == Real32Type || s == Real64Type {
+    e = s
+  }
+  if s := b.ElementType(); s == Real32Type || s == Real64Type {
+    e = s
+  }
+  // real Schur decompositions A = U S U^T and B = V T V^T
+  args = append(args, qrAlgorithm.ComputeU{Value: true})
+  s, u, err := qrAlgorithm.Run(a, args...)
+  if err != nil {
+    return nil, err
+  }
+  t, v, err := qrAlgorithm.Run(b, args...)
+  if err != nil {
+    return nil, err
+  }
+  // F = U^T C V
+  w := NullDenseMatrix(e, m, n)
+  f := NullDenseMatrix(e, m, n)
+  w.MdotM(u.T(), c)
+  f.MdotM(w, v)
+  y, err := sylvesterQuasiTriangular(s, t, f)
+  if err != nil {
+    return nil, err
+  }
+  // X = U Y V^T
+  w.MdotM(u, y)
+  f.MdotM(w, v.T())
+  return f, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Solve the Sylvester equation A X + X B = C with the Bartels-Stewart
+// algorithm, where A is m x m, B is n x n and C is m x n. A and B are
+// reduced to real Schur form with the QR algorithm and the resulting
+// quasi-triangular system is solved by block substitution. The solution is
+// unique if A and -B have no common eigenvalues. All options are passed to
+// the QR algorithm.
+func Run(a, b, c Matrix, args_ ...interface{}) (Matrix, error) {
+  m1, m2 := a.Dims()
+  n1, n2 := b.Dims()
+  if m1 != m2 || n1 != n2 {
+    return nil, fmt.Errorf("`a' and `b' must be square matrices")
+  }
+  if r1, r2 := c.Dims(); r1 != m1 || r2 != n1 {
+    return nil, fmt.Errorf("c has invalid dimension (%dx%d instead of %dx%d)", r1, r2, m1, n1)
+  }
+  // arguments passed on to the qrAlgorithm
+  var args []interface{}
+  // loop over optional arguments
+  for _, arg := range args_ {
+    switch arg.(type) {
+    case qrAlgorithm.ComputeU:
+      // drop this option
+    default:
+      args = append(args, arg)
+    }
+  }
+  return sylvester(a, b, c, args...)
+}
+
+// Solve the continuous Lyapunov equation A X + X A^T + Q = 0. If A is
+// stable, i.e. all eigenvalues have negative real part, and Q is symmetric
+// positive semi-definite, X is the stationary covariance of the linear
+// stochastic system dx = A x dt + dW with Cov(dW) = Q dt. All options are
+// passed to the QR algorithm.
+func Lyapunov(a, q Matrix, args ...interface{}) (Matrix, error) {
+  n, _ := a.Dims()
+  if r1, r2 := q.Dims(); r1 != n || r2 != n {
+    return nil, fmt.Errorf("q has invalid dimension (%dx%d instead of %dx%d)", r1, r2, n, n)
+  }
+  c := NullDenseMatrix(q.ElementType(), n, n)
+  c.MmulS(q, ConstFloat64(-1.0))
+  b := NullDenseMatrix(a.ElementType(), n, n)
+  b.Set(a.T())
+  return Run(a, b, c, args...)
+}
@@ -0,0 +1,149 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sylvester
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+
+/* -------------------------------------------------------------------------- */
+
+func TestSylvester1(test *testing.T) {
+  // A and B have real and complex eigenvalues
+  a := NewDenseFloat64Matrix([]float64{
+    4, 1, 0, 2,
+    1, 3, 1, 0,
+   -2, 0, 5, 1,
+    0, 1,-1, 2 }, 4, 4)
+  b := NewDenseFloat64Matrix([]float64{
+    1,-2, 0,
+    3, 1, 1,
+    0, 1, 2 }, 3, 3)
+  c := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    4, 5, 6,
+    7, 8, 9,
+    1, 0,-1 }, 4, 3)
+
+  x, err := Run(a, b, c)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  r1 := NullDenseFloat64Matrix(4, 3)
+  r2 := NullDenseFloat64Matrix(4, 3)
+  r1.MdotM(a, x)
+  r2.MdotM(x, b)
+  r1.MaddM(r1, r2)
+  if !r1.Equals(c, 1e-10) {
+    test.Error("test failed")
+  }
+}
+
+func TestSylvester2(test *testing.T) {
+  // A and -B have a common eigenvalue
+  a := NewDenseFloat64Matrix([]float64{
+    1, 0,
+    0, 2 }, 2, 2)
+  b := NewDenseFloat64Matrix([]float64{
+   -1, 1,
+    0, 3 }, 2, 2)
+  c := NewDenseFloat64Matrix([]float64{
+    1, 0,
+    0, 1 }, 2, 2)
+  if _, err := Run(a, b, c); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestSylvester3(test *testing.T) {
+  // stationary covariance of an Ornstein-Uhlenbeck process
+  a := NewDenseFloat64Matrix([]float64{
+   -1, 2, 0,
+   -2,-1, 0,
+    1, 0,-3 }, 3, 3)
+  q := NewDenseFloat64Matrix([]float64{
+    2, 1, 0,
+    1, 2, 0,
+    0, 0, 1 }, 3, 3)
+
+  x, err := Lyapunov(a, q)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  r1 := NullDenseFloat64Matrix(3, 3)
+  r2 := NullDenseFloat64Matrix(3, 3)
+  r1.MdotM(a, x)
+  r2.MdotM(x, a.T())
+  r1.MaddM(r1, r2)
+  r1.MaddM(r1, q)
+  for i := 0; i < 3; i++ {
+    for j := 0; j < 3; j++ {
+      if math.Abs(r1.At(i, j).GetFloat64()) > 1e-10 {
+        test.Error("test failed")
+      }
+      if math.Abs(x.At(i, j).GetFloat64() - x.At(j, i).GetFloat64()) > 1e-10 {
+        test.Error("test failed")
+      }
+    }
+  }
+}
+
+func TestSylvester4(test *testing.T) {
+  // compare derivatives with finite differences
+  v := []float64{
+    4, 1, 0,
+    1, 3, 2,
+   -2, 0, 5 }
+  b := NewDenseFloat64Matrix([]float64{
+    1,-2,
+    3, 1 }, 2, 2)
+  c := NewDenseFloat64Matrix([]float64{
+    1, 2,
+    3, 4,
+    5, 6 }, 3, 2)
+  a := NewDenseReal64Matrix(v, 3, 3)
+  a.Variables(1)
+
+  x, err := Run(a, b, c)
+  if err != nil {
+    test.Error(err)
+    return
+  }
+  h := 1e-6
+  for k := 0; k < 9; k++ {
+    v1 := append([]float64{}, v...)
+    v2 := append([]float64{}, v...)
+    v1[k] += h
+    v2[k] -= h
+    x1, _ := Run(NewDenseFloat64Matrix(v1, 3, 3), b, c)
+    x2, _ := Run(NewDenseFloat64Matrix(v2, 3, 3), b, c)
+    for i := 0; i < 3; i++ {
+      for j := 0; j < 2; j++ {
+        d := (x1.At(i, j).GetFloat64() - x2.At(i, j).GetFloat64())/(2*h)
+        if math.Abs(x.At(i, j).GetDerivative(k) - d) > 1e-6 {
+          test.Errorf("test failed for derivative %d of element (%d,%d)", k, i, j)
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,342 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Reference:
+// Nocedal, Jorge, and Stephen Wright. Numerical optimization.
+// Springer Science & Business Media, 2006. (Chapters 4 and 7)
+
+/* -------------------------------------------------------------------------- */
+
+package trustRegion
+
+/* -------------------------------------------------------------------------- */
+
+import   "fmt"
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+type Epsilon struct {
+  Value float64
+}
+
+type MaxIterations struct {
+  Value int
+}
+
+// Method for solving the trust region subproblem, i.e. "Steihaug" or
+// "Dogleg"
+type Method struct {
+  Value string
+}
+
+// Initial trust region radius
+type Radius struct {
+  Value float64
+}
+
+type MaxRadius struct {
+  Value float64
+}
+
+// Hessian-vector product H(x) v, which replaces the explicit computation
+// of the Hessian
+type HessianVector struct {
+  Value func(x, v ConstVector) (ConstVector, error)
+}
+
+type Constraints struct {
+  Value func(x Vector) bool
+}
+
+type Hook struct {
+  Value func(x, gradient ConstVector, H ConstMatrix, y ConstScalar) bool
+}
+
+// Returned if the trust region collapses without further progress. The
+// position of the last iteration is returned together with this error.
+var ErrRadius = fmt.Errorf("trust region radius too small")
+
+/* -------------------------------------------------------------------------- */
+
+type objective func(x ConstVector) (float64, model, error)
+
+func trustRegion(f objective, x0 ConstVector, epsilon Epsilon, maxIterations MaxIterations, method Method, radius Radius, maxRadius MaxRadius, constraints Constraints, hook Hook) (Vector, error) {
+  // minimum ratio of actual and predicted reduction for accepting a step
+  eta := 1e-4
+
+  x1 := AsDenseFloat64Vector(x0)
+  x2 := AsDenseFloat64Vector(x0)
+
+  delta := radius.Value
+
+  // check initial value
+  if constraints.Value != nil && !constraints.Value(x1) {
+    return x1, fmt.Errorf("invalid initial value: %v", x1)
+  }
+  y1, m, err := f(x1)
+  if err != nil {
+    return x1, fmt.Errorf("invalid initial value: %s", err)
+  }
+  for i := 0; i < maxIterations.Value; i++ {
+    // execute hook if available
+    if hook.Value != nil && hook.Value(x1, m.g, m.B, ConstFloat64(y1)) {
+      break
+    }
+    // evaluate stop criterion
+    if norm(m.g) < epsilon.Value {
+      break
+    }
+    if delta < 1e-16*math.Max(1.0, norm(x1)) {
+      return x1, ErrRadius
+    }
+    // solve trust region subproblem
+    var p DenseFloat64Vector
+    switch method.Value {
+    case "Steihaug":
+      p, err = steihaug(m, delta)
+    case "Dogleg":
+      p, err = dogleg(m, delta)
+    default:
+      panic("invalid trust region method")
+    }
+    if err != nil {
+      return x1, err
+    }
+    // predicted reduction
+    pred, err := m.eval(p)
+    if err != nil {
+      return x1, err
+    }
+    pred = -pred
+    // evaluate objective at new position
+    x2.VaddV(x1, p)
+    rho := -1.0
+    var y2 float64
+    var m2 model
+    if constraints.Value == nil || constraints.Value(x2) {
+      if y2, m2, err = f(x2); err == nil && !math.IsNaN(y2) {
+        if pred > 0.0 {
+          rho = (y1 - y2)/pred
+        }
+        // actual and predicted reductions are at the level of rounding
+        // errors, consider the model accurate
+        if e := 1e-14*math.Max(1.0, math.Abs(y1)); math.Abs(y1 - y2) <= e && math.Abs(pred) <= e {
+          rho = 1.0
+        }
+      }
+    }
+    // update trust region radius
+    if rho < 0.25 {
+      delta = 0.25*norm(p)
+    } else if rho > 0.75 && math.Abs(norm(p) - delta) <= 1e-8*delta {
+      delta = math.Min(2.0*delta, maxRadius.Value)
+    }
+    // accept step
+    if rho > eta {
+      x1.Set(x2)
+      y1 = y2
+      m  = m2
+    }
+  }
+  return x1, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f with a trust region Newton method starting at x0. The Hessian
+// is computed with autodiff, unless a Hessian-vector product is given with
+// the HessianVector option. In this case the gradient is computed with
+// first order derivatives only and the Steihaug method must be used.
+// Optional arguments:
+//
+//   Epsilon      : stop if the norm of the gradient is smaller than epsilon
+//   MaxIterations: maximum number of iterations
+//   Method       : "Steihaug" (truncated CG, default) or "Dogleg"
+//   Radius       : initial trust region radius
+//   MaxRadius    : maximum trust region radius
+//   HessianVector: Hessian-vector product
+//   Constraints  : restricts the domain of f, infeasible steps are rejected
+//   Hook         : called at every iteration, stop if it returns true
+func Run(f func(ConstVector) (MagicScalar, error), x0 ConstVector, args ...interface{}) (Vector, error) {
+
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  method        := Method       {"Steihaug"}
+  radius        := Radius       {1.0}
+  maxRadius     := MaxRadius    {1e4}
+  hessianVector := HessianVector{nil}
+  constraints   := Constraints  {nil}
+  hook          := Hook         {nil}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Epsilon:
+      epsilon = a
+    case MaxIterations:
+      maxIterations = a
+    case Method:
+      method = a
+    case Radius:
+      radius = a
+    case MaxRadius:
+      maxRadius = a
+    case HessianVector:
+      hessianVector = a
+    case Constraints:
+      constraints = a
+    case Hook:
+      hook = a
+    default:
+      panic("TrustRegion(): Invalid optional argument!")
+    }
+  }
+  switch method.Value {
+  case "Steihaug":
+  case "Dogleg":
+    if hessianVector.Value != nil {
+      return nil, fmt.Errorf("dogleg method requires an explicit Hessian")
+    }
+  default:
+    return nil, fmt.Errorf("invalid trust region method: %s", method.Value)
+  }
+  if radius.Value <= 0.0 || maxRadius.Value < radius.Value {
+    return nil, fmt.Errorf("invalid trust region radius")
+  }
+  n := x0.Dim()
+  // copy of x for computing derivatives
+  X := NullDenseReal64Vector(n)
+
+  var g objective
+  if hessianVector.Value == nil {
+    g = func(x ConstVector) (float64, model, error) {
+      X.Set(x)
+      if err := X.Variables(2); err != nil {
+        return 0.0, model{}, err
+      }
+      // evaluate objective function
+      Y, err := f(X)
+      if err != nil {
+        return 0.0, model{}, err
+      }
+      m := model{}
+      m.g = NullDenseFloat64Vector(n)
+      m.B = NullDenseFloat64Matrix(n, n)
+      CopyGradient(m.g, Y)
+      CopyHessian (m.B, Y)
+      m.Bv = func(v DenseFloat64Vector) (DenseFloat64Vector, error) {
+        r := NullDenseFloat64Vector(n)
+        r.MdotV(m.B, v)
+        return r, nil
+      }
+      return Y.GetFloat64(), m, nil
+    }
+  } else {
+    g = func(x ConstVector) (float64, model, error) {
+      X.Set(x)
+      if err := X.Variables(1); err != nil {
+        return 0.0, model{}, err
+      }
+      // evaluate objective function
+      Y, err := f(X)
+      if err != nil {
+        return 0.0, model{}, err
+      }
+      m := model{}
+      m.g = NullDenseFloat64Vector(n)
+      CopyGradient(m.g, Y)
+      // copy of x, which is captured by the Hessian-vector product
+      z := AsDenseFloat64Vector(x)
+      m.Bv = func(v DenseFloat64Vector) (DenseFloat64Vector, error) {
+        r, err := hessianVector.Value(z, v)
+        if err != nil {
+          return nil, err
+        }
+        return AsDenseFloat64Vector(r), nil
+      }
+      return Y.GetFloat64(), m, nil
+    }
+  }
+  return trustRegion(g, x0, epsilon, maxIterations, method, radius, maxRadius, constraints, hook)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Minimize f starting at x0 and return the solution together with
+// convergence diagnostics. Accepts the same optional arguments as Run and
+// additionally optimize.Trace.
+func Minimize(f func(ConstVector) (MagicScalar, error), x0 ConstVector, args ...interface{}) (optimize.Result, error) {
+  r, args := optimize.NewRecorder(args)
+
+  hook          := Hook         {nil}
+  epsilon       := Epsilon      {1e-8}
+  maxIterations := MaxIterations{int(^uint(0) >> 1)}
+  options       := []interface{}{}
+
+  for _, arg := range args {
+    switch a := arg.(type) {
+    case Hook:
+      hook = a
+    case Epsilon:
+      epsilon = a
+      options = append(options, a)
+    case MaxIterations:
+      maxIterations = a
+      options = append(options, a)
+    default:
+      options = append(options, a)
+    }
+  }
+  // count function evaluations
+  g := func(x ConstVector) (MagicScalar, error) {
+    r.Evaluation()
+    return f(x)
+  }
+  // the hook is called at the beginning of every iteration
+  first := true
+  options = append(options, Hook{func(x, gradient ConstVector, H ConstMatrix, y ConstScalar) bool {
+    if first {
+      r.Init(x, y.GetFloat64(), optimize.Norm(gradient)); first = false
+    } else {
+      r.Iteration(x, y.GetFloat64(), optimize.Norm(gradient))
+    }
+    return hook.Value != nil && r.Hook(hook.Value(x, gradient, H, y))
+  }})
+  x, err := Run(g, x0, options...)
+  if x == nil {
+    return r.Result(x0, math.NaN(), math.NaN(), optimize.Failed), err
+  }
+  y, gnorm, e := optimize.Evaluate(f, x)
+  switch {
+  case err == ErrRadius:
+    return r.Result(x, y, gnorm, optimize.Stalled), nil
+  case err != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), err
+  case e != nil:
+    return r.Result(x, y, gnorm, optimize.Failed), e
+  case gnorm < epsilon.Value:
+    return r.Result(x, y, gnorm, optimize.Converged), nil
+  default:
+    // the hook is not called after the last iteration
+    if !r.Stopped() {
+      r.SetIterations(maxIterations.Value)
+    }
+    return r.Result(x, y, gnorm, optimize.MaxIterationsReached), nil
+  }
+}
@@ -0,0 +1,175 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trustRegion
+
+/* -------------------------------------------------------------------------- */
+
+import   "math"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/cholesky"
+
+/* -------------------------------------------------------------------------- */
+
+// quadratic model m(p) = g^T p + 1/2 p^T B p of the objective function
+type model struct {
+  g  DenseFloat64Vector
+  // Hessian, nil if only Hessian-vector products are available
+  B  Matrix
+  // Hessian-vector product
+  Bv func(v DenseFloat64Vector) (DenseFloat64Vector, error)
+}
+
+func (m model) eval(p DenseFloat64Vector) (float64, error) {
+  Bp, err := m.Bv(p)
+  if err != nil {
+    return 0.0, err
+  }
+  return dot(m.g, p) + 0.5*dot(p, Bp), nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+func dot(a, b DenseFloat64Vector) float64 {
+  r := 0.0
+  for i := 0; i < a.Dim(); i++ {
+    r += a[i]*b[i]
+  }
+  return r
+}
+
+func norm(a DenseFloat64Vector) float64 {
+  return math.Sqrt(dot(a, a))
+}
+
+// returns z + tau d, where tau >= 0 such that ||z + tau d|| = delta
+func toBoundary(z, d DenseFloat64Vector, delta float64) DenseFloat64Vector {
+  a := dot(d, d)
+  b := 2.0*dot(z, d)
+  c := dot(z, z) - delta*delta
+  tau := (-b + math.Sqrt(b*b - 4.0*a*c))/(2.0*a)
+  r := NullDenseFloat64Vector(z.Dim())
+  for i := 0; i < z.Dim(); i++ {
+    r[i] = z[i] + tau*d[i]
+  }
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Steihaug's truncated conjugate gradient method, which requires only
+// Hessian-vector products. The iteration stops at the trust region boundary
+// or if a direction of negative curvature is detected.
+func steihaug(m model, delta float64) (DenseFloat64Vector, error) {
+  n  := m.g.Dim()
+  z  := NullDenseFloat64Vector(n)
+  r  := m.g.Clone()
+  d  := NullDenseFloat64Vector(n)
+  d.VmulS(r, ConstFloat64(-1.0))
+  // forcing sequence for superlinear convergence
+  eps := math.Min(0.5, math.Sqrt(norm(r)))*norm(r)
+  if norm(r) < eps {
+    return z, nil
+  }
+  for j := 0; j < n; j++ {
+    Bd, err := m.Bv(d)
+    if err != nil {
+      return nil, err
+    }
+    kappa := dot(d, Bd)
+    if kappa <= 0.0 {
+      // direction of negative curvature
+      return toBoundary(z, d, delta), nil
+    }
+    rr    := dot(r, r)
+    alpha := rr/kappa
+    z1    := NullDenseFloat64Vector(n)
+    for i := 0; i < n; i++ {
+      z1[i] = z[i] + alpha*d[i]
+    }
+    if norm(z1) >= delta {
+      return toBoundary(z, d, delta), nil
+    }
+    z = z1
+    for i := 0; i < n; i++ {
+      r[i] += alpha*Bd[i]
+    }
+    if norm(r) < eps {
+      break
+    }
+    beta := dot(r, r)/rr
+    for i := 0; i < n; i++ {
+      d[i] = -r[i] + beta*d[i]
+    }
+  }
+  return z, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Dogleg method, which requires an explicit Hessian. If the Hessian is not
+// positive definite, the Cauchy point is used.
+func dogleg(m model, delta float64) (DenseFloat64Vector, error) {
+  n  := m.g.Dim()
+  Bg, err := m.Bv(m.g)
+  if err != nil {
+    return nil, err
+  }
+  gg  := dot(m.g, m.g)
+  gBg := dot(m.g, Bg)
+  // Cauchy point
+  cauchy := func() DenseFloat64Vector {
+    // zero step at stationary points
+    if gg == 0.0 {
+      return NullDenseFloat64Vector(n)
+    }
+    tau := 1.0
+    if gBg > 0.0 {
+      tau = math.Min(gg*math.Sqrt(gg)/(delta*gBg), 1.0)
+    }
+    r := NullDenseFloat64Vector(n)
+    r.VmulS(m.g, ConstFloat64(-tau*delta/math.Sqrt(gg)))
+    return r
+  }
+  if gBg <= 0.0 {
+    return cauchy(), nil
+  }
+  // full Newton step pB = -B^-1 g
+  L, _, err := cholesky.Run(m.B)
+  if err != nil {
+    return cauchy(), nil
+  }
+  pb_, err := cholesky.Solve(L, nil, m.g)
+  if err != nil {
+    return cauchy(), nil
+  }
+  pb := AsDenseFloat64Vector(pb_)
+  pb.VmulS(pb, ConstFloat64(-1.0))
+  if norm(pb) <= delta {
+    return pb, nil
+  }
+  // minimizer along the steepest descent direction
+  pu := NullDenseFloat64Vector(n)
+  pu.VmulS(m.g, ConstFloat64(-gg/gBg))
+  if norm(pu) >= delta {
+    pu.VmulS(pu, ConstFloat64(delta/norm(pu)))
+    return pu, nil
+  }
+  // intersection of the path pU + tau (pB - pU) with the boundary
+  pb.VsubV(pb, pu)
+  return toBoundary(pu, pb, delta), nil
+}
@@ -0,0 +1,143 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trustRegion
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import . "github.com/pbenner/autodiff"
+import   "github.com/pbenner/autodiff/algorithm/optimize"
+
+/* -------------------------------------------------------------------------- */
+
+func rosenbrock(x ConstVector) (MagicScalar, error) {
+  // f(x1, x2) = (a - x1)^2 + b(x2 - x1^2)^2
+  // a = 1
+  // b = 100
+  // minimum: (x1,x2) = (a, a^2)
+  a  := ConstFloat64(  1.0)
+  b  := ConstFloat64(100.0)
+  c  := ConstFloat64(  2.0)
+  t1 := NullReal64()
+  t2 := NullReal64()
+  t1.Mul(b, t1.Pow(t1.Sub(x.ConstAt(1), t1.Mul(x.ConstAt(0), x.ConstAt(0))), c))
+  t2.Pow(t2.Sub(a, x.ConstAt(0)), c)
+  t1.Add(t1, t2)
+  return t1, nil
+}
+
+/* -------------------------------------------------------------------------- */
+
+func TestTrustRegionRosenbrock(test *testing.T) {
+  x0 := NewDenseFloat64Vector([]float64{-1.2, 1})
+  xr := NewDenseFloat64Vector([]float64{   1, 1})
+  for _, method := range []string{"Steihaug", "Dogleg"} {
+    xn, err := Run(rosenbrock, x0, Method{method}, Epsilon{1e-10})
+    if err != nil {
+      test.Error(err); continue
+    }
+    if !xn.Equals(xr, 1e-8) {
+      test.Errorf("%s Rosenbrock test failed", method)
+    }
+  }
+}
+
+func TestTrustRegionHessianVector(test *testing.T) {
+  // Hessian-vector product of the Rosenbrock function
+  hv := func(x, v ConstVector) (ConstVector, error) {
+    x1 := x.ConstAt(0).GetFloat64()
+    x2 := x.ConstAt(1).GetFloat64()
+    v1 := v.ConstAt(0).GetFloat64()
+    v2 := v.ConstAt(1).GetFloat64()
+    h11 := 1200.0*x1*x1 - 400.0*x2 + 2.0
+    h12 := -400.0*x1
+    h22 := 200.0
+    return NewDenseFloat64Vector([]float64{h11*v1 + h12*v2, h12*v1 + h22*v2}), nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{-1.2, 1})
+  xr := NewDenseFloat64Vector([]float64{   1, 1})
+  xn, err := Run(rosenbrock, x0, HessianVector{hv}, Epsilon{1e-10})
+  if err != nil {
+    test.Error(err); return
+  }
+  if !xn.Equals(xr, 1e-8) {
+    test.Error("test failed")
+  }
+  if _, err := Run(rosenbrock, x0, HessianVector{hv}, Method{"Dogleg"}); err == nil {
+    test.Error("test failed")
+  }
+}
+
+func TestTrustRegionSaddle(test *testing.T) {
+  // f(x1, x2) = x1^2 - x2^2 + x2^4/4 has a saddle point at (0, 0) and
+  // minima at (0, +/- sqrt(2))
+  f := func(x ConstVector) (MagicScalar, error) {
+    r := NullReal64()
+    t := NullReal64()
+    r.Mul(x.ConstAt(0), x.ConstAt(0))
+    t.Mul(x.ConstAt(1), x.ConstAt(1))
+    r.Sub(r, t)
+    t.Mul(t, t)
+    t.Div(t, ConstFloat64(4.0))
+    r.Add(r, t)
+    return r, nil
+  }
+  x0 := NewDenseFloat64Vector([]float64{1, 1e-4})
+  for _, method := range []string{"Steihaug", "Dogleg"} {
+    xn, err := Run(f, x0, Method{method}, Epsilon{1e-10})
+    if err != nil {
+      test.Error(err); continue
+    }
+    if math.Abs(xn.At(0).GetFloat64()) > 1e-8 || math.Abs(math.Abs(xn.At(1).GetFloat64()) - math.Sqrt(2.0)) > 1e-8 {
+      test.Errorf("%s saddle test failed: %v", method, xn)
+    }
+  }
+}
+
+func TestTrustRegionStationary(test *testing.T) {
+  // dogleg step at a stationary point
+  m := model{}
+  m.g  = NullDenseFloat64Vector(2)
+  m.B  = DenseIdentityMatrix(Float64Type, 2)
+  m.Bv = func(v DenseFloat64Vector) (DenseFloat64Vector, error) {
+    return v, nil
+  }
+  p, err := dogleg(m, 1.0)
+  if err != nil {
+    test.Error(err); return
+  }
+  if p.At(0).GetFloat64() != 0.0 || p.At(1).GetFloat64() != 0.0 {
+    test.Errorf("test failed: %v", p)
+  }
+}
+
+func TestTrustRegionMinimize(test *testing.T) {
+  x0 := NewDenseFloat64Vector([]float64{-1.2, 1})
+  r, err := Minimize(rosenbrock, x0, Epsilon{1e-10}, optimize.Trace{Value: true})
+  if err != nil {
+    test.Error(err)
+  }
+  if r.Status != optimize.Converged || r.GradientNorm >= 1e-10 || len(r.Trace) != r.Iterations+1 {
+    test.Error("test failed")
+  }
+  if r, _ := Minimize(rosenbrock, x0, MaxIterations{2}); r.Status != optimize.MaxIterationsReached || r.Iterations != 2 {
+    test.Error("test failed")
+  }
+}
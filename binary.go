@@ -0,0 +1,563 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "bytes"
+import "encoding/binary"
+import "fmt"
+import "math"
+
+/* binary format
+ * -------------------------------------------------------------------------- */
+
+// Version of the binary format written by MarshalBinary. Data written
+// by older versions can always be read by newer versions of autodiff.
+const BinaryFormatVersion uint16 = 1
+
+// All binary encoded containers start with this magic string, followed by the
+// format version (uint16), the container kind (uint8) and the scalar type
+// (uint8). Dimensions and indices are stored as unsigned varints, values are
+// stored in little endian byte order.
+const binaryMagic = "ADBF"
+
+const (
+  binaryDenseVector  uint8 = 1
+  binarySparseVector uint8 = 2
+  binaryDenseMatrix  uint8 = 3
+  binarySparseMatrix uint8 = 4
+)
+
+// Codes for scalar types. Constant scalars share the code of the
+// corresponding mutable type, since both store the same values. These codes
+// must never change.
+const (
+  binaryInt8    uint8 = 1
+  binaryInt16   uint8 = 2
+  binaryInt32   uint8 = 3
+  binaryInt64   uint8 = 4
+  binaryInt     uint8 = 5
+  binaryFloat32 uint8 = 6
+  binaryFloat64 uint8 = 7
+  binaryReal32  uint8 = 8
+  binaryReal64  uint8 = 9
+)
+
+func binaryScalarCode(t ScalarType) (uint8, error) {
+  switch t {
+  case Int8Type,    ConstInt8Type:
+    return binaryInt8, nil
+  case Int16Type,   ConstInt16Type:
+    return binaryInt16, nil
+  case Int32Type,   ConstInt32Type:
+    return binaryInt32, nil
+  case Int64Type,   ConstInt64Type:
+    return binaryInt64, nil
+  case IntType,     ConstIntType:
+    return binaryInt, nil
+  case Float32Type, ConstFloat32Type:
+    return binaryFloat32, nil
+  case Float64Type, ConstFloat64Type:
+    return binaryFloat64, nil
+  case Real32Type:
+    return binaryReal32, nil
+  case Real64Type:
+    return binaryReal64, nil
+  default:
+    return 0, fmt.Errorf("binary encoding of scalar type `%v' is not supported", t)
+  }
+}
+
+func binaryScalarType(code uint8) (ScalarType, error) {
+  switch code {
+  case binaryInt8:
+    return Int8Type, nil
+  case binaryInt16:
+    return Int16Type, nil
+  case binaryInt32:
+    return Int32Type, nil
+  case binaryInt64:
+    return Int64Type, nil
+  case binaryInt:
+    return IntType, nil
+  case binaryFloat32:
+    return Float32Type, nil
+  case binaryFloat64:
+    return Float64Type, nil
+  case binaryReal32:
+    return Real32Type, nil
+  case binaryReal64:
+    return Real64Type, nil
+  default:
+    return nil, fmt.Errorf("invalid scalar type `%d' in binary data", code)
+  }
+}
+
+/* encoder
+ * -------------------------------------------------------------------------- */
+
+type binaryEncoder struct {
+  bytes.Buffer
+  code uint8
+  tmp  [binary.MaxVarintLen64]byte
+}
+
+func newBinaryEncoder(kind uint8, t ScalarType) (*binaryEncoder, error) {
+  code, err := binaryScalarCode(t)
+  if err != nil {
+    return nil, err
+  }
+  e := binaryEncoder{code: code}
+  e.WriteString(binaryMagic)
+  e.writeUint16(BinaryFormatVersion)
+  e.WriteByte(kind)
+  e.WriteByte(code)
+  return &e, nil
+}
+
+func (e *binaryEncoder) writeInt(i int) {
+  n := binary.PutUvarint(e.tmp[:], uint64(i))
+  e.Write(e.tmp[:n])
+}
+
+func (e *binaryEncoder) writeUint16(v uint16) {
+  binary.LittleEndian.PutUint16(e.tmp[:], v)
+  e.Write(e.tmp[:2])
+}
+
+func (e *binaryEncoder) writeUint32(v uint32) {
+  binary.LittleEndian.PutUint32(e.tmp[:], v)
+  e.Write(e.tmp[:4])
+}
+
+func (e *binaryEncoder) writeUint64(v uint64) {
+  binary.LittleEndian.PutUint64(e.tmp[:], v)
+  e.Write(e.tmp[:8])
+}
+
+func (e *binaryEncoder) writeScalar(s ConstScalar) {
+  switch e.code {
+  case binaryInt8:
+    e.WriteByte(uint8(s.GetInt8()))
+  case binaryInt16:
+    e.writeUint16(uint16(s.GetInt16()))
+  case binaryInt32:
+    e.writeUint32(uint32(s.GetInt32()))
+  case binaryInt64, binaryInt:
+    e.writeUint64(uint64(s.GetInt64()))
+  case binaryFloat32:
+    e.writeUint32(math.Float32bits(s.GetFloat32()))
+  case binaryFloat64:
+    e.writeUint64(math.Float64bits(s.GetFloat64()))
+  case binaryReal32:
+    e.writeUint32(math.Float32bits(s.GetFloat32()))
+    e.writeDerivatives(s, func(v float64) { e.writeUint32(math.Float32bits(float32(v))) })
+  case binaryReal64:
+    e.writeUint64(math.Float64bits(s.GetFloat64()))
+    e.writeDerivatives(s, func(v float64) { e.writeUint64(math.Float64bits(v)) })
+  }
+}
+
+func (e *binaryEncoder) writeDerivatives(s ConstScalar, write func(float64)) {
+  order := s.GetOrder()
+  n     := s.GetN()
+  e.writeInt(order)
+  e.writeInt(n)
+  if order >= 1 {
+    for i := 0; i < n; i++ {
+      write(s.GetDerivative(i))
+    }
+  }
+  if order >= 2 {
+    for i := 0; i < n; i++ {
+      for j := 0; j < n; j++ {
+        write(s.GetHessian(i, j))
+      }
+    }
+  }
+}
+
+/* decoder
+ * -------------------------------------------------------------------------- */
+
+type binaryDecoder struct {
+  *bytes.Reader
+  code uint8
+  t    ScalarType
+}
+
+func newBinaryDecoder(data []byte, kind uint8, t ScalarType) (*binaryDecoder, error) {
+  code, err := binaryScalarCode(t)
+  if err != nil {
+    return nil, err
+  }
+  d := binaryDecoder{Reader: bytes.NewReader(data)}
+  magic := make([]byte, len(binaryMagic))
+  if _, err := d.Read(magic); err != nil || string(magic) != binaryMagic {
+    return nil, fmt.Errorf("invalid binary data")
+  }
+  if version, err := d.readUint16(); err != nil {
+    return nil, err
+  } else {
+    if version > BinaryFormatVersion {
+      return nil, fmt.Errorf("binary data has format version `%d' which is not supported by this version of autodiff", version)
+    }
+  }
+  if k, err := d.ReadByte(); err != nil {
+    return nil, fmt.Errorf("invalid binary data")
+  } else {
+    if k != kind {
+      return nil, fmt.Errorf("binary data contains a different container type")
+    }
+  }
+  if c, err := d.ReadByte(); err != nil {
+    return nil, fmt.Errorf("invalid binary data")
+  } else {
+    if c != code {
+      return nil, fmt.Errorf("binary data contains scalars of type `%v', expected type `%v'", binaryTypeName(c), t)
+    }
+    d.code = c
+  }
+  if d.t, err = binaryScalarType(code); err != nil {
+    return nil, err
+  }
+  return &d, nil
+}
+
+func binaryTypeName(code uint8) string {
+  if t, err := binaryScalarType(code); err != nil {
+    return "unknown"
+  } else {
+    return fmt.Sprintf("%v", t)
+  }
+}
+
+func (d *binaryDecoder) readInt() (int, error) {
+  if v, err := binary.ReadUvarint(d); err != nil {
+    return 0, fmt.Errorf("invalid binary data")
+  } else {
+    if v > uint64(math.MaxInt64) || int64(int(v)) != int64(v) {
+      return 0, fmt.Errorf("invalid binary data")
+    }
+    return int(v), nil
+  }
+}
+
+// Read a dimension or number of elements. Each element requires at least
+// one byte, which allows to reject corrupted data before allocating memory.
+func (d *binaryDecoder) readLength() (int, error) {
+  n, err := d.readInt()
+  if err != nil {
+    return 0, err
+  }
+  if n < 0 || n > d.Len() {
+    return 0, fmt.Errorf("invalid binary data")
+  }
+  return n, nil
+}
+
+// Read matrix dimensions. For dense matrices the number of elements is
+// bounded by the length of the remaining data.
+func (d *binaryDecoder) readDims(dense bool) (int, int, error) {
+  n, err := d.readInt()
+  if err != nil {
+    return 0, 0, err
+  }
+  m, err := d.readInt()
+  if err != nil {
+    return 0, 0, err
+  }
+  if dense && m > 0 && n > d.Len()/m {
+    return 0, 0, fmt.Errorf("invalid binary data")
+  }
+  if m > 0 && n > math.MaxInt32/m {
+    return 0, 0, fmt.Errorf("invalid binary data")
+  }
+  return n, m, nil
+}
+
+func (d *binaryDecoder) readUint16() (uint16, error) {
+  var b [2]byte
+  if _, err := d.Read(b[:]); err != nil {
+    return 0, fmt.Errorf("invalid binary data")
+  }
+  return binary.LittleEndian.Uint16(b[:]), nil
+}
+
+func (d *binaryDecoder) readUint32() (uint32, error) {
+  var b [4]byte
+  if n, err := d.Read(b[:]); err != nil || n != 4 {
+    return 0, fmt.Errorf("invalid binary data")
+  }
+  return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+func (d *binaryDecoder) readUint64() (uint64, error) {
+  var b [8]byte
+  if n, err := d.Read(b[:]); err != nil || n != 8 {
+    return 0, fmt.Errorf("invalid binary data")
+  }
+  return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+func (d *binaryDecoder) readFloat32() (float64, error) {
+  v, err := d.readUint32()
+  return float64(math.Float32frombits(v)), err
+}
+
+func (d *binaryDecoder) readFloat64() (float64, error) {
+  v, err := d.readUint64()
+  return math.Float64frombits(v), err
+}
+
+// Read the next scalar and store its value (and derivatives) in s.
+func (d *binaryDecoder) readScalar(s Scalar) error {
+  switch d.code {
+  case binaryInt8:
+    if v, err := d.ReadByte(); err != nil {
+      return fmt.Errorf("invalid binary data")
+    } else {
+      s.SetInt8(int8(v))
+    }
+  case binaryInt16:
+    if v, err := d.readUint16(); err != nil {
+      return err
+    } else {
+      s.SetInt16(int16(v))
+    }
+  case binaryInt32:
+    if v, err := d.readUint32(); err != nil {
+      return err
+    } else {
+      s.SetInt32(int32(v))
+    }
+  case binaryInt64, binaryInt:
+    if v, err := d.readUint64(); err != nil {
+      return err
+    } else {
+      s.SetInt64(int64(v))
+    }
+  case binaryFloat32:
+    if v, err := d.readUint32(); err != nil {
+      return err
+    } else {
+      s.SetFloat32(math.Float32frombits(v))
+    }
+  case binaryFloat64:
+    if v, err := d.readFloat64(); err != nil {
+      return err
+    } else {
+      s.SetFloat64(v)
+    }
+  case binaryReal32:
+    if v, err := d.readUint32(); err != nil {
+      return err
+    } else {
+      s.SetFloat32(math.Float32frombits(v))
+    }
+    return d.readDerivatives(s, d.readFloat32, 4)
+  case binaryReal64:
+    if v, err := d.readFloat64(); err != nil {
+      return err
+    } else {
+      s.SetFloat64(v)
+    }
+    return d.readDerivatives(s, d.readFloat64, 8)
+  }
+  return nil
+}
+
+// Read the next scalar into a newly allocated scalar of the stored type.
+func (d *binaryDecoder) readConstScalar() (ConstScalar, error) {
+  s := NullScalar(d.t)
+  if err := d.readScalar(s); err != nil {
+    return nil, err
+  }
+  return s, nil
+}
+
+// Read derivatives of order one or two, where each derivative is stored with
+// size bytes.
+func (d *binaryDecoder) readDerivatives(s_ Scalar, read func() (float64, error), size int) error {
+  s, ok := s_.(MagicScalar)
+  if !ok {
+    return fmt.Errorf("binary data contains derivatives, but target scalar is not a MagicScalar")
+  }
+  order, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  if order > 2 {
+    return fmt.Errorf("invalid binary data")
+  }
+  n, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  // number of stored derivatives, which must not exceed the remaining data
+  // so that corrupted data is rejected before allocating memory
+  k := 0
+  if order >= 1 {
+    k += n
+  }
+  if order >= 2 {
+    if n > 0 && n > d.Len()/n {
+      return fmt.Errorf("invalid binary data")
+    }
+    k += n*n
+  }
+  if k > d.Len()/size {
+    return fmt.Errorf("invalid binary data")
+  }
+  s.Alloc(n, order)
+  if order >= 1 {
+    for i := 0; i < n; i++ {
+      if v, err := read(); err != nil {
+        return err
+      } else {
+        s.SetDerivative(i, v)
+      }
+    }
+  }
+  if order >= 2 {
+    for i := 0; i < n; i++ {
+      for j := 0; j < n; j++ {
+        if v, err := read(); err != nil {
+          return err
+        } else {
+          s.SetHessian(i, j, v)
+        }
+      }
+    }
+  }
+  return nil
+}
+
+/* generic encoding of containers
+ * -------------------------------------------------------------------------- */
+
+func marshalBinaryDenseVector(v ConstVector) ([]byte, error) {
+  e, err := newBinaryEncoder(binaryDenseVector, v.ElementType())
+  if err != nil {
+    return nil, err
+  }
+  e.writeInt(v.Dim())
+  for i := 0; i < v.Dim(); i++ {
+    e.writeScalar(v.ConstAt(i))
+  }
+  return e.Bytes(), nil
+}
+
+func marshalBinarySparseVector(v ConstVector) ([]byte, error) {
+  e, err := newBinaryEncoder(binarySparseVector, v.ElementType())
+  if err != nil {
+    return nil, err
+  }
+  k := []int{}
+  s := []ConstScalar{}
+  for it := v.ConstIterator(); it.Ok(); it.Next() {
+    k = append(k, it.Index())
+    s = append(s, it.GetConst())
+  }
+  e.writeInt(v.Dim())
+  e.writeInt(len(k))
+  // indices are sorted, store differences
+  for i := 0; i < len(k); i++ {
+    if i == 0 {
+      e.writeInt(k[i])
+    } else {
+      e.writeInt(k[i]-k[i-1])
+    }
+    e.writeScalar(s[i])
+  }
+  return e.Bytes(), nil
+}
+
+func marshalBinaryDenseMatrix(m ConstMatrix) ([]byte, error) {
+  e, err := newBinaryEncoder(binaryDenseMatrix, m.ElementType())
+  if err != nil {
+    return nil, err
+  }
+  n1, n2 := m.Dims()
+  e.writeInt(n1)
+  e.writeInt(n2)
+  for i := 0; i < n1; i++ {
+    for j := 0; j < n2; j++ {
+      e.writeScalar(m.ConstAt(i, j))
+    }
+  }
+  return e.Bytes(), nil
+}
+
+func marshalBinarySparseMatrix(m ConstMatrix) ([]byte, error) {
+  e, err := newBinaryEncoder(binarySparseMatrix, m.ElementType())
+  if err != nil {
+    return nil, err
+  }
+  n1, n2 := m.Dims()
+  k := []int{}
+  s := []ConstScalar{}
+  for it := m.ConstIterator(); it.Ok(); it.Next() {
+    i, j := it.Index()
+    // iterators of sliced matrices may return elements outside the slice
+    if i < 0 || i >= n1 || j < 0 || j >= n2 {
+      continue
+    }
+    k = append(k, i*n2+j)
+    s = append(s, it.GetConst())
+  }
+  e.writeInt(n1)
+  e.writeInt(n2)
+  e.writeInt(len(k))
+  // entries are stored in row-major order, store index differences
+  for i := 0; i < len(k); i++ {
+    if i == 0 {
+      e.writeInt(k[i])
+    } else {
+      e.writeInt(k[i]-k[i-1])
+    }
+    e.writeScalar(s[i])
+  }
+  return e.Bytes(), nil
+}
+
+// Read indices and values of a sparse container with n elements. The function
+// f is called for every index-value pair.
+func (d *binaryDecoder) readSparse(n int, f func(int) error) error {
+  nnz, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  for i, k := 0, 0; i < nnz; i++ {
+    if delta, err := d.readInt(); err != nil {
+      return err
+    } else {
+      if i > 0 && delta == 0 {
+        return fmt.Errorf("invalid binary data")
+      }
+      k += delta
+    }
+    if k < 0 || k >= n {
+      return fmt.Errorf("invalid binary data")
+    }
+    if err := f(k); err != nil {
+      return err
+    }
+  }
+  return nil
+}
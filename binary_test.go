@@ -0,0 +1,215 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+//import "fmt"
+import "bytes"
+import "encoding/gob"
+import "math"
+import "runtime"
+import "testing"
+
+/* -------------------------------------------------------------------------- */
+
+func TestBinaryDenseVector(t *testing.T) {
+  {
+    r1 := NewDenseInt64Vector([]int64{1, -2, 1<<60, 4})
+    r2 := DenseInt64Vector{}
+
+    if b, err := r1.MarshalBinary(); err != nil {
+      t.Error(err); return
+    } else {
+      if err := r2.UnmarshalBinary(b); err != nil {
+        t.Error(err); return
+      }
+    }
+    if r1.Dim() != r2.Dim() || r2[2] != 1<<60 || r2[1] != -2 {
+      t.Error("test failed")
+    }
+  }
+  {
+    r1 := NewDenseReal64Vector([]float64{1,2,3,4})
+    r1.Variables(2)
+    r2 := DenseReal64Vector{}
+
+    if b, err := r1.MarshalBinary(); err != nil {
+      t.Error(err); return
+    } else {
+      if err := r2.UnmarshalBinary(b); err != nil {
+        t.Error(err); return
+      }
+    }
+    if !r1.Equals(r2, 1e-12) {
+      t.Error("test failed")
+    }
+    for i := 0; i < r1.Dim(); i++ {
+      if r2[i].GetOrder() != 2 || r2[i].GetN() != 4 {
+        t.Error("test failed")
+      }
+      for j := 0; j < r1.Dim(); j++ {
+        if r2[i].GetDerivative(j) != r1[i].GetDerivative(j) {
+          t.Error("test failed")
+        }
+      }
+    }
+  }
+}
+
+func TestBinarySparseVector(t *testing.T) {
+  {
+    r1 := NewSparseReal32Vector([]int{1,10,7}, []float32{1,2,3}, 100)
+    r1.AT(1).Alloc(2, 1)
+    r1.AT(1).SetDerivative(1, 4.0)
+    r2 := NullSparseReal32Vector(0)
+
+    if b, err := r1.MarshalBinary(); err != nil {
+      t.Error(err); return
+    } else {
+      if err := r2.UnmarshalBinary(b); err != nil {
+        t.Error(err); return
+      }
+    }
+    if r2.Dim() != 100 || !r1.Equals(r2, 1e-12) {
+      t.Error("test failed")
+    }
+    if r2.AT(1).GetOrder() != 1 || r2.AT(1).GetDerivative(1) != 4.0 {
+      t.Error("test failed")
+    }
+  }
+  {
+    r1 := NewSparseFloat64Vector([]int{1,10,7}, []float64{1,2,3}, 100)
+    r2 := SparseConstFloat64Vector{}
+
+    if b, err := r1.MarshalBinary(); err != nil {
+      t.Error(err); return
+    } else {
+      if err := r2.UnmarshalBinary(b); err != nil {
+        t.Error(err); return
+      }
+    }
+    if r2.Dim() != 100 || !r1.Equals(r2, 1e-12) {
+      t.Error("test failed")
+    }
+  }
+}
+
+func TestBinaryMatrix(t *testing.T) {
+  {
+    r1 := NewDenseReal64Matrix([]float64{1,2,3,4,5,6}, 2, 3)
+    r1.Variables(1)
+    r2 := NullDenseReal64Matrix(0, 0)
+
+    // encode transposed matrix
+    if b, err := r1.T().MarshalBinary(); err != nil {
+      t.Error(err); return
+    } else {
+      if err := r2.UnmarshalBinary(b); err != nil {
+        t.Error(err); return
+      }
+    }
+    if n, m := r2.Dims(); n != 3 || m != 2 {
+      t.Error("test failed")
+    }
+    if !r1.T().Equals(r2, 1e-12) {
+      t.Error("test failed")
+    }
+    if r2.AT(2,1).GetDerivative(5) != 1.0 {
+      t.Error("test failed")
+    }
+  }
+  {
+    r1 := NewSparseInt16Matrix([]int{0,2,1}, []int{1,2,0}, []int16{1,2,-3}, 3, 3)
+    r2 := NullSparseInt16Matrix(0, 0)
+
+    if b, err := r1.Slice(1,3,0,3).MarshalBinary(); err != nil {
+      t.Error(err); return
+    } else {
+      if err := r2.UnmarshalBinary(b); err != nil {
+        t.Error(err); return
+      }
+    }
+    if n, m := r2.Dims(); n != 2 || m != 3 {
+      t.Error("test failed")
+    }
+    if r2.AT(0,0).GetInt16() != -3 || r2.AT(1,2).GetInt16() != 2 || r2.AT(0,1).GetInt16() != 0 {
+      t.Error("test failed")
+    }
+  }
+}
+
+func TestBinaryGob(t *testing.T) {
+  r1 := NewDenseReal64Matrix([]float64{1,2,3,4}, 2, 2)
+  r1.Variables(2)
+  r2 := NullDenseReal64Matrix(0, 0)
+
+  buffer := bytes.Buffer{}
+  if err := gob.NewEncoder(&buffer).Encode(r1); err != nil {
+    t.Error(err); return
+  }
+  if err := gob.NewDecoder(&buffer).Decode(r2); err != nil {
+    t.Error(err); return
+  }
+  if !r1.Equals(r2, 1e-12) {
+    t.Error("test failed")
+  }
+  if r2.AT(1,1).GetHessian(3,3) != r1.AT(1,1).GetHessian(3,3) {
+    t.Error("test failed")
+  }
+}
+
+func TestBinaryErrors(t *testing.T) {
+  b, _ := NewDenseFloat64Vector([]float64{1,2,3}).MarshalBinary()
+  // wrong scalar type
+  if err := (&DenseFloat32Vector{}).UnmarshalBinary(b); err == nil {
+    t.Error("test failed")
+  }
+  // wrong container type
+  if err := NullDenseFloat64Matrix(0, 0).UnmarshalBinary(b); err == nil {
+    t.Error("test failed")
+  }
+  // truncated data
+  if err := (&DenseFloat64Vector{}).UnmarshalBinary(b[0:len(b)-1]); err == nil {
+    t.Error("test failed")
+  }
+  // corrupted number of derivatives, which would require a Hessian with
+  // 2^32 elements
+  if e, err := newBinaryEncoder(binaryDenseVector, Real64Type); err != nil {
+    t.Error(err)
+  } else {
+    e.writeInt(1)
+    e.writeUint64(math.Float64bits(1.0))
+    e.writeInt(2)
+    e.writeInt(1 << 16)
+    e.Write(make([]byte, 1 << 16))
+    var m1, m2 runtime.MemStats
+    runtime.ReadMemStats(&m1)
+    if err := (&DenseReal64Vector{}).UnmarshalBinary(e.Bytes()); err == nil {
+      t.Error("test failed")
+    }
+    runtime.ReadMemStats(&m2)
+    if m2.TotalAlloc - m1.TotalAlloc > 1 << 20 {
+      t.Error("test failed")
+    }
+  }
+  // unsupported future version
+  b[len(binaryMagic)] = 0xff
+  if err := (&DenseFloat64Vector{}).UnmarshalBinary(b); err == nil {
+    t.Error("test failed")
+  }
+}
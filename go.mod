@@ -5,5 +5,6 @@ go 1.14
 require (
 	github.com/pbenner/threadpool v0.0.0-20191122191339-0302c226b91e
 	github.com/pborman/getopt v0.0.0-20190409184431-ee0cd42419d3
+	gonum.org/v1/gonum v0.8.2
 	gonum.org/v1/plot v0.7.0
 )
@@ -0,0 +1,214 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "sync/atomic"
+import "unsafe"
+
+import "gonum.org/v1/gonum/blas"
+import "gonum.org/v1/gonum/blas/blas64"
+import "gonum.org/v1/gonum/mat"
+
+/* gonum backend
+ * -------------------------------------------------------------------------- */
+
+// accessed atomically, since the backend may be switched while other
+// goroutines compute matrix products
+var gonumBackend int32
+
+// If enabled, products of DenseFloat64Matrix types as well as Cholesky,
+// singular value and symmetric eigen decompositions of DenseFloat64Matrix
+// types are computed with gonum's BLAS and LAPACK implementations. Types
+// that carry derivatives are not affected.
+func SetGonumBackend(value bool) {
+  if value {
+    atomic.StoreInt32(&gonumBackend, 1)
+  } else {
+    atomic.StoreInt32(&gonumBackend, 0)
+  }
+}
+
+func GonumBackend() bool {
+  return atomic.LoadInt32(&gonumBackend) != 0
+}
+
+/* zero-copy adapters
+ * -------------------------------------------------------------------------- */
+
+// Returns the underlying row-major storage of a. If a is a transposed
+// matrix, the storage of a^T is returned.
+func gonumGeneral(a *DenseFloat64Matrix) (blas64.General, bool) {
+  n, m := a.Dims()
+  if a.transposed {
+    n, m = m, n
+  }
+  r := blas64.General{Rows: n, Cols: m}
+  if a.transposed {
+    r.Stride = a.rowMax
+    r.Data   = a.values[a.colOffset*a.rowMax + a.rowOffset:]
+  } else {
+    r.Stride = a.colMax
+    r.Data   = a.values[a.rowOffset*a.colMax + a.colOffset:]
+  }
+  if n > 0 && m > 0 {
+    r.Data = r.Data[0:(n-1)*r.Stride + m]
+  }
+  return r, a.transposed
+}
+
+// Returns a gonum matrix that shares memory with a. Transposed matrices
+// are returned as mat.Transpose.
+func AsGonumMatrix(a *DenseFloat64Matrix) mat.Matrix {
+  r := AsGonumDense(a.untransposed())
+  if a.transposed {
+    return r.T()
+  }
+  return r
+}
+
+// Returns a gonum dense matrix that shares memory with a. The matrix a
+// must not be transposed.
+func AsGonumDense(a *DenseFloat64Matrix) *mat.Dense {
+  g, transposed := gonumGeneral(a)
+  if transposed {
+    panic("AsGonumDense(): matrix is transposed")
+  }
+  r := &mat.Dense{}
+  r.SetRawMatrix(g)
+  return r
+}
+
+// Returns a gonum symmetric matrix that shares memory with a. Only the
+// upper triangular part of the storage is referenced by gonum, the lower
+// triangular part is assumed to be identical.
+func AsGonumSymDense(a *DenseFloat64Matrix) *mat.SymDense {
+  n, m := a.Dims()
+  if n != m {
+    panic("AsGonumSymDense(): not a square matrix!")
+  }
+  g, _ := gonumGeneral(a)
+  r := &mat.SymDense{}
+  r.SetRawSymmetric(blas64.Symmetric{
+    N     : n,
+    Stride: g.Stride,
+    Data  : g.Data,
+    Uplo  : blas.Upper })
+  return r
+}
+
+// Returns a gonum vector that shares memory with v.
+func AsGonumVecDense(v DenseFloat64Vector) *mat.VecDense {
+  r := &mat.VecDense{}
+  r.SetRawVector(blas64.Vector{N: len(v), Data: v, Inc: 1})
+  return r
+}
+
+// Returns a matrix that shares memory with a.
+func FromGonumDense(a *mat.Dense) *DenseFloat64Matrix {
+  g := a.RawMatrix()
+  return &DenseFloat64Matrix{
+    values: g.Data,
+    rows  : g.Rows,
+    cols  : g.Cols,
+    rowMax: g.Rows,
+    colMax: g.Stride }
+}
+
+// Returns a matrix that shares memory with a. Gonum only stores the upper
+// triangular part of symmetric matrices, the lower triangular part of the
+// storage is overwritten with a copy of the upper part.
+func FromGonumSymDense(a *mat.SymDense) *DenseFloat64Matrix {
+  g := a.RawSymmetric()
+  for i := 0; i < g.N; i++ {
+    for j := i+1; j < g.N; j++ {
+      g.Data[j*g.Stride+i] = g.Data[i*g.Stride+j]
+    }
+  }
+  return &DenseFloat64Matrix{
+    values: g.Data,
+    rows  : g.N,
+    cols  : g.N,
+    rowMax: g.N,
+    colMax: g.Stride }
+}
+
+// Returns a vector that shares memory with v. If the elements of v are not
+// stored contiguously, a copy is returned.
+func FromGonumVecDense(v *mat.VecDense) DenseFloat64Vector {
+  g := v.RawVector()
+  if g.Inc == 1 {
+    return DenseFloat64Vector(g.Data[0:g.N])
+  }
+  r := NullDenseFloat64Vector(g.N)
+  for i := 0; i < g.N; i++ {
+    r[i] = g.Data[i*g.Inc]
+  }
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+func (a *DenseFloat64Matrix) untransposed() *DenseFloat64Matrix {
+  if a.transposed {
+    return a.T().(*DenseFloat64Matrix)
+  }
+  return a
+}
+
+// Returns true if the storage of a and b overlaps, i.e. if both are views
+// of the same memory
+func gonumOverlap(a, b *DenseFloat64Matrix) bool {
+  ga, _ := gonumGeneral(a)
+  gb, _ := gonumGeneral(b)
+  if len(ga.Data) == 0 || len(gb.Data) == 0 {
+    return false
+  }
+  a0 := uintptr(unsafe.Pointer(&ga.Data[0]))
+  a1 := uintptr(unsafe.Pointer(&ga.Data[len(ga.Data)-1]))
+  b0 := uintptr(unsafe.Pointer(&gb.Data[0]))
+  b1 := uintptr(unsafe.Pointer(&gb.Data[len(gb.Data)-1]))
+  return a0 <= b1 && b0 <= a1
+}
+
+// Compute the matrix product r = a b with gonum if all matrices are of
+// type DenseFloat64Matrix. Returns false if the product was not computed.
+func gonumMdotM(r Matrix, a, b ConstMatrix) bool {
+  r_, ok1 := r.(*DenseFloat64Matrix)
+  a_, ok2 := a.(*DenseFloat64Matrix)
+  b_, ok3 := b.(*DenseFloat64Matrix)
+  if !ok1 || !ok2 || !ok3 {
+    return false
+  }
+  n, m := r_.Dims()
+  _, k := a_.Dims()
+  if n == 0 || m == 0 || k == 0 {
+    return false
+  }
+  if gonumOverlap(r_, a_) || gonumOverlap(r_, b_) {
+    t := mat.NewDense(n, m, nil)
+    t.Mul(AsGonumMatrix(a_), AsGonumMatrix(b_))
+    r_.Set(FromGonumDense(t))
+  } else if r_.transposed {
+    // compute r^T = b^T a^T
+    AsGonumDense(r_.untransposed()).Mul(AsGonumMatrix(b_).T(), AsGonumMatrix(a_).T())
+  } else {
+    AsGonumDense(r_).Mul(AsGonumMatrix(a_), AsGonumMatrix(b_))
+  }
+  return true
+}
@@ -0,0 +1,115 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+//import "fmt"
+import "testing"
+
+import "gonum.org/v1/gonum/mat"
+
+/* -------------------------------------------------------------------------- */
+
+func TestGonumAdapters(t *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    4, 5, 6,
+    7, 8, 9 }, 3, 3)
+
+  // sub-matrix
+  g := AsGonumDense(a.Slice(1, 3, 1, 3).(*DenseFloat64Matrix))
+  if g.At(1, 0) != 8 {
+    t.Error("test failed")
+  }
+  g.Set(0, 1, -6)
+  if a.At(1, 2).GetFloat64() != -6 {
+    t.Error("test failed")
+  }
+  // transposed matrix
+  if h := AsGonumMatrix(a.T().(*DenseFloat64Matrix)); h.At(0, 1) != 4 {
+    t.Error("test failed")
+  }
+  // symmetric matrix
+  if s := AsGonumSymDense(a); s.At(2, 0) != 3 {
+    t.Error("test failed")
+  }
+  // vectors
+  v := NewDenseFloat64Vector([]float64{1, 2, 3})
+  w := AsGonumVecDense(v)
+  w.ScaleVec(2, w)
+  if v[2] != 6 {
+    t.Error("test failed")
+  }
+  // gonum to autodiff
+  b := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+  c := FromGonumDense(b)
+  c.At(1, 0).SetFloat64(-3)
+  if b.At(1, 0) != -3 {
+    t.Error("test failed")
+  }
+  s := mat.NewSymDense(2, []float64{1, 2, 0, 4})
+  if !FromGonumSymDense(s).Equals(NewDenseFloat64Matrix([]float64{1, 2, 2, 4}, 2, 2), 1e-12) {
+    t.Error("test failed")
+  }
+  if r := FromGonumVecDense(mat.NewVecDense(2, []float64{1, 2})); r[1] != 2 {
+    t.Error("test failed")
+  }
+}
+
+func TestGonumMdotM(t *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    4, 5, 6 }, 2, 3)
+  b := NewDenseFloat64Matrix([]float64{
+    1, 2,
+    3, 4,
+    5, 6 }, 3, 2)
+
+  r1 := NullDenseFloat64Matrix(2, 2)
+  r2 := NullDenseFloat64Matrix(2, 2)
+  r3 := NullDenseFloat64Matrix(2, 2)
+  r1.MdotM(a, b)
+
+  SetGonumBackend(true)
+  defer SetGonumBackend(false)
+
+  r2.MdotM(a, b)
+  r3.T().MdotM(b.T(), a.T())
+
+  if !r1.Equals(r2, 1e-12) {
+    t.Error("test failed")
+  }
+  if !r1.Equals(r3, 1e-12) {
+    t.Error("test failed")
+  }
+  // aliasing
+  r2.MdotM(r2, DenseIdentityMatrix(Float64Type, 2))
+  if !r1.Equals(r2, 1e-12) {
+    t.Error("test failed")
+  }
+  // matrices with overlapping storage
+  v  := []float64{1, 2, 3, 4, 5, 6}
+  d  := NewDenseFloat64Matrix([]float64{
+    1, 2,
+    3, 4 }, 2, 2)
+  r4 := NewDenseFloat64Matrix(v[0:4], 2, 2)
+  r4.MdotM(NewDenseFloat64Matrix(v[2:6], 2, 2), d)
+  if !r4.Equals(NewDenseFloat64Matrix([]float64{15, 22, 23, 34}, 2, 2), 1e-12) {
+    t.Error("test failed")
+  }
+}
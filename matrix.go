@@ -18,6 +18,7 @@ package autodiff
 
 /* -------------------------------------------------------------------------- */
 
+import "encoding"
 import "encoding/json"
 
 /* -------------------------------------------------------------------------- */
@@ -93,6 +94,8 @@ type constMatrix interface {
   storageLocation  () uintptr
   // json
   json.Marshaler
+  // binary encoding
+  encoding.BinaryMarshaler
 }
 
 type ConstMatrix interface {
@@ -140,6 +143,15 @@ type matrix interface {
   MdivS(a ConstMatrix, b ConstScalar)              Matrix
   MdotM(a,             b ConstMatrix)              Matrix
   Outer(a,             b ConstVector)              Matrix
+  // broadcasting of vectors over rows and columns
+  MaddVrows(a ConstMatrix, b ConstVector)          Matrix
+  MaddVcols(a ConstMatrix, b ConstVector)          Matrix
+  MsubVrows(a ConstMatrix, b ConstVector)          Matrix
+  MsubVcols(a ConstMatrix, b ConstVector)          Matrix
+  MmulVrows(a ConstMatrix, b ConstVector)          Matrix
+  MmulVcols(a ConstMatrix, b ConstVector)          Matrix
+  MdivVrows(a ConstMatrix, b ConstVector)          Matrix
+  MdivVcols(a ConstMatrix, b ConstVector)          Matrix
   Jacobian(f func(ConstVector) ConstVector, x_ MagicVector) Matrix
   Hessian (f func(ConstVector) ConstScalar, x_ MagicVector) Matrix
 }
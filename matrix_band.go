@@ -0,0 +1,128 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+
+// Band matrices with kl subdiagonals and ku superdiagonals store each row
+// in kl+ku+1 consecutive elements, i.e. element (p, q) is stored at
+// position p(kl+ku+1) + q-p+kl. Elements outside the matrix are unused.
+type bandLayout struct {
+  rows, cols int
+  kl, ku     int
+}
+
+func (l bandLayout) dims() (int, int) {
+  return l.rows, l.cols
+}
+
+func (l bandLayout) size() int {
+  return l.rows*(l.kl+l.ku+1)
+}
+
+func (l bandLayout) index(p, q int) int {
+  if d := q-p; d < -l.kl || d > l.ku {
+    return -1
+  } else {
+    return p*(l.kl+l.ku+1) + d + l.kl
+  }
+}
+
+func (l bandLayout) rowRange(p int) (int, int) {
+  return clipRange(p-l.kl, p+l.ku+1, l.cols)
+}
+
+func (l bandLayout) colRange(q int) (int, int) {
+  return clipRange(q-l.ku, q+l.kl+1, l.rows)
+}
+
+func (l bandLayout) primary(p, q int) bool {
+  return true
+}
+
+func (l bandLayout) name() string {
+  return "band"
+}
+
+/* -------------------------------------------------------------------------- */
+
+// A matrix where all non-zero elements are on the diagonal, the first kl
+// subdiagonals or the first ku superdiagonals.
+type BandMatrix struct {
+  structuredMatrix
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+// Create a band matrix with kl subdiagonals and ku superdiagonals. The
+// vector must contain rows*(kl+ku+1) elements, where row i is stored
+// at positions i*(kl+ku+1) to (i+1)*(kl+ku+1)-1 starting with element
+// (i, i-kl). The matrix shares memory with the vector.
+func NewBandMatrix(values Vector, rows, cols, kl, ku int) *BandMatrix {
+  if kl < 0 || ku < 0 {
+    panic("invalid number of sub- or superdiagonals")
+  }
+  r := &BandMatrix{newStructuredMatrix(values, bandLayout{rows, cols, kl, ku})}
+  r.self = r
+  return r
+}
+
+func NullBandMatrix(t ScalarType, rows, cols, kl, ku int) *BandMatrix {
+  return NewBandMatrix(NullDenseVector(t, rows*(kl+ku+1)), rows, cols, kl, ku)
+}
+
+// Copy the band of matrix a with kl subdiagonals and ku superdiagonals.
+func AsBandMatrix(t ScalarType, a ConstMatrix, kl, ku int) *BandMatrix {
+  n, m := a.Dims()
+  r := NullBandMatrix(t, n, m, kl, ku)
+  r.Set(a)
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+func (matrix *BandMatrix) Clone() *BandMatrix {
+  r := &BandMatrix{matrix.clone()}
+  r.self = r
+  return r
+}
+
+func (matrix *BandMatrix) CloneMatrix() Matrix {
+  return matrix.Clone()
+}
+
+func (matrix *BandMatrix) CloneConstMatrix() ConstMatrix {
+  return matrix.Clone()
+}
+
+func (matrix *BandMatrix) T() Matrix {
+  r := &BandMatrix{matrix.transpose()}
+  r.self = r
+  return r
+}
+
+// Returns the number of subdiagonals kl and superdiagonals ku.
+func (matrix *BandMatrix) Bandwidth() (int, int) {
+  l := matrix.layout.(bandLayout)
+  if matrix.transposed {
+    return l.ku, l.kl
+  } else {
+    return l.kl, l.ku
+  }
+}
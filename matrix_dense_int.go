@@ -570,6 +570,31 @@ func (a *DenseIntMatrix) UnmarshalJSON(data []byte) error {
   a.transposed = false
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *DenseIntMatrix) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseMatrix(obj)
+}
+func (obj *DenseIntMatrix) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseMatrix, IntType)
+  if err != nil {
+    return err
+  }
+  n, m, err := d.readDims(true)
+  if err != nil {
+    return err
+  }
+  r := NullDenseIntMatrix(n, m)
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      if err := d.readScalar(r.AT(i, j)); err != nil {
+        return err
+      }
+    }
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (m *DenseIntMatrix) Iterator() MatrixIterator {
@@ -570,6 +570,31 @@ func (a *DenseInt16Matrix) UnmarshalJSON(data []byte) error {
   a.transposed = false
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *DenseInt16Matrix) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseMatrix(obj)
+}
+func (obj *DenseInt16Matrix) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseMatrix, Int16Type)
+  if err != nil {
+    return err
+  }
+  n, m, err := d.readDims(true)
+  if err != nil {
+    return err
+  }
+  r := NullDenseInt16Matrix(n, m)
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      if err := d.readScalar(r.AT(i, j)); err != nil {
+        return err
+      }
+    }
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (m *DenseInt16Matrix) Iterator() MatrixIterator {
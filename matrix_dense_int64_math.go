@@ -170,6 +170,9 @@ func (r *DenseInt64Matrix) MdotM(a, b ConstMatrix) Matrix {
   if n1 != n || m2 != m || m1 != n2 {
     panic("matrix dimensions do not match!")
   }
+  if GonumBackend() && gonumMdotM(r, a, b) {
+    return r
+  }
   t1 := int64(0)
   t2 := int64(0)
   if r.storageLocation() == b.storageLocation() {
@@ -177,7 +180,7 @@ func (r *DenseInt64Matrix) MdotM(a, b ConstMatrix) Matrix {
     for j := 0; j < m; j++ {
       for i := 0; i < n; i++ {
         t2 = 0.0
-        for k := 0; k < m1; k++ {
+        for k, kto := constMatrixProductRange(a, b, i, j); k < kto; k++ {
           t1 = a.ConstAt(i, k).GetInt64()*b.ConstAt(k, j).GetInt64()
           t2 = t2 + t1
         }
@@ -192,7 +195,7 @@ func (r *DenseInt64Matrix) MdotM(a, b ConstMatrix) Matrix {
     for i := 0; i < n; i++ {
       for j := 0; j < m; j++ {
         t2 = int64(0)
-        for k := 0; k < m1; k++ {
+        for k, kto := constMatrixProductRange(a, b, i, j); k < kto; k++ {
           t1 = a.ConstAt(i, k).GetInt64()*b.ConstAt(k, j).GetInt64()
           t2 = t2 + t1
         }
@@ -258,3 +261,117 @@ func (r *DenseInt64Matrix) Hessian(f func(ConstVector) ConstScalar, x_ MagicVect
   }
   return r
 }
+/* broadcasting of vectors over matrix rows and columns
+ * -------------------------------------------------------------------------- */
+// Element-wise addition of a and vector b, where b is broadcast over all rows
+// of a, i.e. r_ij = a_ij + b_j. The result is stored in r.
+func (r *DenseInt64Matrix) MaddVrows(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Add(a.ConstAt(i, j), b.ConstAt(j))
+    }
+  }
+  return r
+}
+// Element-wise addition of a and vector b, where b is broadcast over all
+// columns of a, i.e. r_ij = a_ij + b_i. The result is stored in r.
+func (r *DenseInt64Matrix) MaddVcols(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Add(a.ConstAt(i, j), b.ConstAt(i))
+    }
+  }
+  return r
+}
+// Element-wise substraction of a and vector b, where b is broadcast over all rows
+// of a, i.e. r_ij = a_ij - b_j. The result is stored in r.
+func (r *DenseInt64Matrix) MsubVrows(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Sub(a.ConstAt(i, j), b.ConstAt(j))
+    }
+  }
+  return r
+}
+// Element-wise substraction of a and vector b, where b is broadcast over all
+// columns of a, i.e. r_ij = a_ij - b_i. The result is stored in r.
+func (r *DenseInt64Matrix) MsubVcols(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Sub(a.ConstAt(i, j), b.ConstAt(i))
+    }
+  }
+  return r
+}
+// Element-wise multiplication of a and vector b, where b is broadcast over all rows
+// of a, i.e. r_ij = a_ij * b_j. The result is stored in r.
+func (r *DenseInt64Matrix) MmulVrows(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Mul(a.ConstAt(i, j), b.ConstAt(j))
+    }
+  }
+  return r
+}
+// Element-wise multiplication of a and vector b, where b is broadcast over all
+// columns of a, i.e. r_ij = a_ij * b_i. The result is stored in r.
+func (r *DenseInt64Matrix) MmulVcols(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Mul(a.ConstAt(i, j), b.ConstAt(i))
+    }
+  }
+  return r
+}
+// Element-wise division of a and vector b, where b is broadcast over all rows
+// of a, i.e. r_ij = a_ij / b_j. The result is stored in r.
+func (r *DenseInt64Matrix) MdivVrows(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Div(a.ConstAt(i, j), b.ConstAt(j))
+    }
+  }
+  return r
+}
+// Element-wise division of a and vector b, where b is broadcast over all
+// columns of a, i.e. r_ij = a_ij / b_i. The result is stored in r.
+func (r *DenseInt64Matrix) MdivVcols(a ConstMatrix, b ConstVector) Matrix {
+  n, m := a.Dims()
+  if n1, m1 := r.Dims(); n1 != n || m1 != m || b.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r.AT(i, j).Div(a.ConstAt(i, j), b.ConstAt(i))
+    }
+  }
+  return r
+}
@@ -673,6 +673,31 @@ func (obj *DenseReal64Matrix) UnmarshalJSON(data []byte) error {
   obj.initTmp()
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *DenseReal64Matrix) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseMatrix(obj)
+}
+func (obj *DenseReal64Matrix) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseMatrix, Real64Type)
+  if err != nil {
+    return err
+  }
+  n, m, err := d.readDims(true)
+  if err != nil {
+    return err
+  }
+  r := NullDenseReal64Matrix(n, m)
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      if err := d.readScalar(r.AT(i, j)); err != nil {
+        return err
+      }
+    }
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *DenseReal64Matrix) ConstIterator() MatrixConstIterator {
@@ -480,3 +480,26 @@ func TestRealMatrixTransposeInPlace(t *testing.T) {
     t.Error("test failed")
   }
 }
+
+func TestRealMatrixReductions(t *testing.T) {
+  a := NewDenseReal64Matrix([]float64{1,2,3,4}, 2, 2)
+  a.Variables(1)
+
+  // log-normalize rows of a
+  s := NullDenseReal64Vector(2)
+  s.MlogSumExpRows(a)
+  b := NullDenseReal64Matrix(2, 2)
+  b.MsubVcols(a, s)
+
+  // d/da_00 log(exp(a_00)/(exp(a_00)+exp(a_01)))
+  p := math.Exp(1)/(math.Exp(1)+math.Exp(2))
+  if math.Abs(b.At(0,0).GetDerivative(0) - (1-p)) > 1e-12 {
+    t.Error("test failed")
+  }
+  if math.Abs(b.At(0,0).GetDerivative(1) + (1-p)) > 1e-12 {
+    t.Error("test failed")
+  }
+  if b.At(0,0).GetDerivative(2) != 0.0 {
+    t.Error("test failed")
+  }
+}
@@ -401,3 +401,39 @@ func TestMatrixTransposeInPlace(t *testing.T) {
     t.Error("test failed")
   }
 }
+
+func TestMatrixReductions(t *testing.T) {
+  a := NewDenseFloat64Matrix([]float64{1,2,3,6,5,4}, 2, 3)
+
+  r1 := NullDenseFloat64Vector(2)
+  r2 := NullDenseFloat64Vector(3)
+
+  if r1.MsumRows(a); !r1.Equals(NewDenseFloat64Vector([]float64{6,15}), 1e-12) {
+    t.Error("test failed")
+  }
+  if r2.MsumCols(a); !r2.Equals(NewDenseFloat64Vector([]float64{7,7,7}), 1e-12) {
+    t.Error("test failed")
+  }
+  if r1.MmeanRows(a); !r1.Equals(NewDenseFloat64Vector([]float64{2,5}), 1e-12) {
+    t.Error("test failed")
+  }
+  if r1.MargmaxRows(a); !r1.Equals(NewDenseFloat64Vector([]float64{2,0}), 1e-12) {
+    t.Error("test failed")
+  }
+  if r1.MargmaxCols(a.T()); !r1.Equals(NewDenseFloat64Vector([]float64{2,0}), 1e-12) {
+    t.Error("test failed")
+  }
+  if r1.MlogSumExpRows(a); math.Abs(r1[0] - math.Log(math.Exp(1)+math.Exp(2)+math.Exp(3))) > 1e-12 {
+    t.Error("test failed")
+  }
+  // normalize rows
+  b := NullDenseFloat64Matrix(2, 3)
+  b.MdivVcols(a, r1.MsumRows(a))
+  if r1.MsumRows(b); !r1.Equals(NewDenseFloat64Vector([]float64{1,1}), 1e-12) {
+    t.Error("test failed")
+  }
+  b.MaddVrows(a, NewDenseFloat64Vector([]float64{1,2,3}))
+  if !b.Equals(NewDenseFloat64Matrix([]float64{2,4,6,7,7,7}, 2, 3), 1e-12) {
+    t.Error("test failed")
+  }
+}
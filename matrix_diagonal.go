@@ -0,0 +1,110 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+
+type diagonalLayout struct {
+  n int
+}
+
+func (l diagonalLayout) dims() (int, int) {
+  return l.n, l.n
+}
+
+func (l diagonalLayout) size() int {
+  return l.n
+}
+
+func (l diagonalLayout) index(p, q int) int {
+  if p != q {
+    return -1
+  }
+  return p
+}
+
+func (l diagonalLayout) rowRange(p int) (int, int) {
+  return p, p+1
+}
+
+func (l diagonalLayout) colRange(q int) (int, int) {
+  return q, q+1
+}
+
+func (l diagonalLayout) primary(p, q int) bool {
+  return true
+}
+
+func (l diagonalLayout) name() string {
+  return "diagonal"
+}
+
+/* -------------------------------------------------------------------------- */
+
+// A square matrix that stores only its diagonal elements.
+type DiagonalMatrix struct {
+  structuredMatrix
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+// Create a diagonal matrix with the given diagonal elements. The matrix
+// shares memory with the vector.
+func NewDiagonalMatrix(values Vector) *DiagonalMatrix {
+  r := &DiagonalMatrix{newStructuredMatrix(values, diagonalLayout{values.Dim()})}
+  r.self = r
+  return r
+}
+
+func NullDiagonalMatrix(t ScalarType, n int) *DiagonalMatrix {
+  return NewDiagonalMatrix(NullDenseVector(t, n))
+}
+
+// Copy the diagonal of a square matrix.
+func AsDiagonalMatrix(t ScalarType, a ConstMatrix) *DiagonalMatrix {
+  n, m := a.Dims()
+  if n != m {
+    panic("not a square matrix")
+  }
+  r := NullDiagonalMatrix(t, n)
+  r.Set(a)
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+func (matrix *DiagonalMatrix) Clone() *DiagonalMatrix {
+  r := &DiagonalMatrix{matrix.clone()}
+  r.self = r
+  return r
+}
+
+func (matrix *DiagonalMatrix) CloneMatrix() Matrix {
+  return matrix.Clone()
+}
+
+func (matrix *DiagonalMatrix) CloneConstMatrix() ConstMatrix {
+  return matrix.Clone()
+}
+
+func (matrix *DiagonalMatrix) T() Matrix {
+  r := &DiagonalMatrix{matrix.transpose()}
+  r.self = r
+  return r
+}
@@ -654,6 +654,27 @@ func (obj *SparseIntMatrix) UnmarshalJSON(data []byte) error {
   obj.initTmp()
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseIntMatrix) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseMatrix(obj)
+}
+func (obj *SparseIntMatrix) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseMatrix, IntType)
+  if err != nil {
+    return err
+  }
+  n, m, err := d.readDims(false)
+  if err != nil {
+    return err
+  }
+  r := NullSparseIntMatrix(n, m)
+  if err := d.readSparse(n*m, func(k int) error { return d.readScalar(r.values.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseIntMatrix) ConstIterator() MatrixConstIterator {
@@ -654,6 +654,27 @@ func (obj *SparseInt16Matrix) UnmarshalJSON(data []byte) error {
   obj.initTmp()
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseInt16Matrix) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseMatrix(obj)
+}
+func (obj *SparseInt16Matrix) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseMatrix, Int16Type)
+  if err != nil {
+    return err
+  }
+  n, m, err := d.readDims(false)
+  if err != nil {
+    return err
+  }
+  r := NullSparseInt16Matrix(n, m)
+  if err := d.readSparse(n*m, func(k int) error { return d.readScalar(r.values.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseInt16Matrix) ConstIterator() MatrixConstIterator {
@@ -654,6 +654,27 @@ func (obj *SparseInt32Matrix) UnmarshalJSON(data []byte) error {
   obj.initTmp()
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseInt32Matrix) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseMatrix(obj)
+}
+func (obj *SparseInt32Matrix) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseMatrix, Int32Type)
+  if err != nil {
+    return err
+  }
+  n, m, err := d.readDims(false)
+  if err != nil {
+    return err
+  }
+  r := NullSparseInt32Matrix(n, m)
+  if err := d.readSparse(n*m, func(k int) error { return d.readScalar(r.values.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseInt32Matrix) ConstIterator() MatrixConstIterator {
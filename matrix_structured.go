@@ -0,0 +1,975 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "fmt"
+import "bufio"
+import "bytes"
+import "os"
+import "reflect"
+
+/* -------------------------------------------------------------------------- */
+
+// A matrixLayout maps storage coordinates (p, q) of a structured matrix
+// to positions in its compact storage.
+type matrixLayout interface {
+  // dimension of the full matrix
+  dims() (int, int)
+  // number of stored elements
+  size() int
+  // position of element (p, q) in the compact storage, or -1 if the
+  // element is structurally zero
+  index(p, q int) int
+  // range [from, to) of columns in row p that are not structurally zero
+  rowRange(p int) (int, int)
+  // range [from, to) of rows in column q that are not structurally zero
+  colRange(q int) (int, int)
+  // elements that share storage with other elements are visited only at
+  // their primary position
+  primary(p, q int) bool
+  name() string
+}
+
+// Matrices with structural zeros implement this interface so that generic
+// matrix products can skip zero blocks.
+type structuredConstMatrix interface {
+  rowRange(i int) (int, int)
+  colRange(j int) (int, int)
+}
+
+// Range [from, to) of columns in row i of a that may contain non-zero
+// elements.
+func constMatrixRowRange(a ConstMatrix, i int) (int, int) {
+  if s, ok := a.(structuredConstMatrix); ok {
+    return s.rowRange(i)
+  }
+  _, m := a.Dims()
+  return 0, m
+}
+
+// Range [from, to) of rows in column j of a that may contain non-zero
+// elements.
+func constMatrixColRange(a ConstMatrix, j int) (int, int) {
+  if s, ok := a.(structuredConstMatrix); ok {
+    return s.colRange(j)
+  }
+  n, _ := a.Dims()
+  return 0, n
+}
+
+// Range [from, to) of indices k for which a_ik b_kj may be non-zero.
+func constMatrixProductRange(a, b ConstMatrix, i, j int) (int, int) {
+  from1, to1 := constMatrixRowRange(a, i)
+  from2, to2 := constMatrixColRange(b, j)
+  return clipRange(iMax(from1, from2), iMin(to1, to2), to1)
+}
+
+func clipRange(from, to, n int) (int, int) {
+  if from < 0 {
+    from = 0
+  }
+  if to > n {
+    to = n
+  }
+  if to < from {
+    to = from
+  }
+  return from, to
+}
+
+/* -------------------------------------------------------------------------- */
+
+// structuredMatrix implements the Matrix interface for all matrix types
+// with compact storage. Elements that are structurally zero can be read
+// but not modified.
+type structuredMatrix struct {
+  values     Vector
+  layout     matrixLayout
+  zero       ConstScalar
+  rows       int
+  cols       int
+  pOffset    int
+  qOffset    int
+  transposed bool
+  // matrix returned by all methods that return the receiver
+  self       Matrix
+}
+
+func newStructuredMatrix(values Vector, layout matrixLayout) structuredMatrix {
+  if values.Dim() != layout.size() {
+    panic(fmt.Sprintf("%s matrix requires %d values, but got %d", layout.name(), layout.size(), values.Dim()))
+  }
+  n, m := layout.dims()
+  r := structuredMatrix{}
+  r.values = values
+  r.layout = layout
+  r.zero   = NullScalar(values.ElementType())
+  r.rows   = n
+  r.cols   = m
+  return r
+}
+
+func (matrix *structuredMatrix) clone() structuredMatrix {
+  r := *matrix
+  r.values = matrix.values.CloneVector()
+  r.self   = nil
+  return r
+}
+
+// Return a view of the matrix with the same storage.
+func (matrix *structuredMatrix) view() *structuredMatrix {
+  r := *matrix
+  r.self = &r
+  return &r
+}
+
+func (matrix *structuredMatrix) transpose() structuredMatrix {
+  r := *matrix
+  r.rows, r.cols = matrix.cols, matrix.rows
+  r.transposed   = !matrix.transposed
+  r.self         = nil
+  return r
+}
+
+/* indexing
+ * -------------------------------------------------------------------------- */
+
+func (matrix *structuredMatrix) storage(i, j int) (int, int) {
+  if i < 0 || j < 0 || i >= matrix.rows || j >= matrix.cols {
+    panic(fmt.Errorf("index (%d,%d) out of bounds for matrix of dimension %dx%d", i, j, matrix.rows, matrix.cols))
+  }
+  if matrix.transposed {
+    return matrix.pOffset + j, matrix.qOffset + i
+  } else {
+    return matrix.pOffset + i, matrix.qOffset + j
+  }
+}
+
+func (matrix *structuredMatrix) index(i, j int) int {
+  return matrix.layout.index(matrix.storage(i, j))
+}
+
+func (matrix *structuredMatrix) rowRange(i int) (int, int) {
+  var from, to, offset int
+  if matrix.transposed {
+    from, to = matrix.layout.colRange(matrix.qOffset + i)
+    offset   = matrix.pOffset
+  } else {
+    from, to = matrix.layout.rowRange(matrix.pOffset + i)
+    offset   = matrix.qOffset
+  }
+  return clipRange(from-offset, to-offset, matrix.cols)
+}
+
+func (matrix *structuredMatrix) colRange(j int) (int, int) {
+  var from, to, offset int
+  if matrix.transposed {
+    from, to = matrix.layout.rowRange(matrix.pOffset + j)
+    offset   = matrix.qOffset
+  } else {
+    from, to = matrix.layout.colRange(matrix.qOffset + j)
+    offset   = matrix.pOffset
+  }
+  return clipRange(from-offset, to-offset, matrix.rows)
+}
+
+// Check if the matrix covers the full storage.
+func (matrix *structuredMatrix) isFull() bool {
+  n, m := matrix.layout.dims()
+  if matrix.transposed {
+    n, m = m, n
+  }
+  return matrix.pOffset == 0 && matrix.qOffset == 0 && matrix.rows == n && matrix.cols == m
+}
+
+// Call f for all stored elements of the matrix, where k is the position of
+// element (i, j) in the compact storage. Elements that share storage are
+// visited only once.
+func (matrix *structuredMatrix) forEachStored(f func(i, j, k int)) {
+  var visited []bool
+  if !matrix.isFull() {
+    visited = make([]bool, matrix.values.Dim())
+  }
+  for i := 0; i < matrix.rows; i++ {
+    from, to := matrix.rowRange(i)
+    for j := from; j < to; j++ {
+      p, q := matrix.storage(i, j)
+      k    := matrix.layout.index(p, q)
+      if k < 0 {
+        continue
+      }
+      if visited == nil {
+        if !matrix.layout.primary(p, q) {
+          continue
+        }
+      } else {
+        if visited[k] {
+          continue
+        }
+        visited[k] = true
+      }
+      f(i, j, k)
+    }
+  }
+}
+
+/* matrix interface
+ * -------------------------------------------------------------------------- */
+
+func (matrix *structuredMatrix) CloneMatrix() Matrix {
+  r := matrix.clone()
+  r.self = &r
+  return &r
+}
+
+func (matrix *structuredMatrix) At(i, j int) Scalar {
+  k := matrix.index(i, j)
+  if k < 0 {
+    panic(fmt.Sprintf("element (%d,%d) of %s matrix is structurally zero", i, j, matrix.layout.name()))
+  }
+  return matrix.values.At(k)
+}
+
+func (matrix *structuredMatrix) Reset() {
+  matrix.forEachStored(func(i, j, k int) {
+    matrix.values.At(k).Reset()
+  })
+}
+
+// Copy all elements of b that are stored in a. All other elements
+// of b are ignored.
+func (a *structuredMatrix) Set(b ConstMatrix) {
+  n1, m1 := a.Dims()
+  n2, m2 := b.Dims()
+  if n1 != n2 || m1 != m2 {
+    panic("Copy(): Matrix dimension does not match!")
+  }
+  a.forEachStored(func(i, j, k int) {
+    a.values.At(k).Set(b.ConstAt(i, j))
+  })
+}
+
+func (matrix *structuredMatrix) SetIdentity() {
+  matrix.forEachStored(func(i, j, k int) {
+    if i == j {
+      matrix.values.At(k).SetFloat64(1.0)
+    } else {
+      matrix.values.At(k).Reset()
+    }
+  })
+}
+
+func (matrix *structuredMatrix) Row(i int) Vector {
+  v := NullDenseVector(matrix.ElementType(), matrix.cols)
+  from, to := matrix.rowRange(i)
+  for j := from; j < to; j++ {
+    v.At(j).Set(matrix.ConstAt(i, j))
+  }
+  return v
+}
+
+func (matrix *structuredMatrix) Col(j int) Vector {
+  v := NullDenseVector(matrix.ElementType(), matrix.rows)
+  from, to := matrix.colRange(j)
+  for i := from; i < to; i++ {
+    v.At(i).Set(matrix.ConstAt(i, j))
+  }
+  return v
+}
+
+func (matrix *structuredMatrix) Diag() Vector {
+  n, m := matrix.Dims()
+  if n != m {
+    panic("Diag(): not a square matrix!")
+  }
+  v := NullDenseVector(matrix.ElementType(), n)
+  for i := 0; i < n; i++ {
+    v.At(i).Set(matrix.ConstAt(i, i))
+  }
+  return v
+}
+
+// Return a slice of the matrix that shares storage with the original
+// matrix.
+func (matrix *structuredMatrix) Slice(rfrom, rto, cfrom, cto int) Matrix {
+  r := matrix.view()
+  if matrix.transposed {
+    r.pOffset += cfrom
+    r.qOffset += rfrom
+  } else {
+    r.pOffset += rfrom
+    r.qOffset += cfrom
+  }
+  r.rows = rto - rfrom
+  r.cols = cto - cfrom
+  return r
+}
+
+func (matrix *structuredMatrix) Swap(i1, j1, i2, j2 int) {
+  s1 := matrix.At(i1, j1)
+  s2 := matrix.At(i2, j2)
+  t  := s1.CloneScalar()
+  s1.Set(s2)
+  s2.Set(t)
+}
+
+func (matrix *structuredMatrix) T() Matrix {
+  r := matrix.transpose()
+  r.self = &r
+  return &r
+}
+
+func (matrix *structuredMatrix) Tip() {
+  self   := matrix.self
+  *matrix = matrix.transpose()
+  matrix.self = self
+}
+
+// Returns the compact storage of the matrix.
+func (matrix *structuredMatrix) AsVector() Vector {
+  return matrix.values
+}
+
+func (matrix *structuredMatrix) storageLocation() uintptr {
+  return reflect.ValueOf(matrix.values).Pointer()
+}
+
+/* const interface
+ * -------------------------------------------------------------------------- */
+
+func (matrix *structuredMatrix) CloneConstMatrix() ConstMatrix {
+  return matrix.CloneMatrix()
+}
+
+func (matrix *structuredMatrix) Dims() (int, int) {
+  return matrix.rows, matrix.cols
+}
+
+func (a *structuredMatrix) Equals(b ConstMatrix, epsilon float64) bool {
+  n1, m1 := a.Dims()
+  n2, m2 := b.Dims()
+  if n1 != n2 || m1 != m2 {
+    panic("MEqual(): matrix dimensions do not match!")
+  }
+  for i := 0; i < n1; i++ {
+    for j := 0; j < m1; j++ {
+      if !a.ConstAt(i, j).Equals(b.ConstAt(i, j), epsilon) {
+        return false
+      }
+    }
+  }
+  return true
+}
+
+func (matrix *structuredMatrix) Int8At(i, j int) int8 {
+  return matrix.ConstAt(i, j).GetInt8()
+}
+
+func (matrix *structuredMatrix) Int16At(i, j int) int16 {
+  return matrix.ConstAt(i, j).GetInt16()
+}
+
+func (matrix *structuredMatrix) Int32At(i, j int) int32 {
+  return matrix.ConstAt(i, j).GetInt32()
+}
+
+func (matrix *structuredMatrix) Int64At(i, j int) int64 {
+  return matrix.ConstAt(i, j).GetInt64()
+}
+
+func (matrix *structuredMatrix) IntAt(i, j int) int {
+  return matrix.ConstAt(i, j).GetInt()
+}
+
+func (matrix *structuredMatrix) Float32At(i, j int) float32 {
+  return matrix.ConstAt(i, j).GetFloat32()
+}
+
+func (matrix *structuredMatrix) Float64At(i, j int) float64 {
+  return matrix.ConstAt(i, j).GetFloat64()
+}
+
+func (matrix *structuredMatrix) ConstAt(i, j int) ConstScalar {
+  if k := matrix.index(i, j); k < 0 {
+    return matrix.zero
+  } else {
+    return matrix.values.ConstAt(k)
+  }
+}
+
+func (matrix *structuredMatrix) ConstSlice(rfrom, rto, cfrom, cto int) ConstMatrix {
+  return matrix.Slice(rfrom, rto, cfrom, cto)
+}
+
+func (matrix *structuredMatrix) ConstRow(i int) ConstVector {
+  return matrix.Row(i)
+}
+
+func (matrix *structuredMatrix) ConstCol(j int) ConstVector {
+  return matrix.Col(j)
+}
+
+func (matrix *structuredMatrix) ConstDiag() ConstVector {
+  return matrix.Diag()
+}
+
+func (matrix *structuredMatrix) IsSymmetric(epsilon float64) bool {
+  n, m := matrix.Dims()
+  if n != m {
+    return false
+  }
+  for i := 0; i < n; i++ {
+    for j := i+1; j < m; j++ {
+      if !matrix.ConstAt(i,j).Equals(matrix.ConstAt(j,i), epsilon) {
+        return false
+      }
+    }
+  }
+  return true
+}
+
+func (matrix *structuredMatrix) AsConstVector() ConstVector {
+  return matrix.values
+}
+
+/* implement ScalarContainer
+ * -------------------------------------------------------------------------- */
+
+// Apply f to all stored elements of the matrix.
+func (matrix *structuredMatrix) Map(f func(Scalar)) {
+  matrix.forEachStored(func(i, j, k int) {
+    f(matrix.values.At(k))
+  })
+}
+
+// Apply f to all stored elements of the matrix.
+func (matrix *structuredMatrix) MapSet(f func(ConstScalar) Scalar) {
+  matrix.forEachStored(func(i, j, k int) {
+    s := matrix.values.At(k)
+    s.Set(f(s))
+  })
+}
+
+func (matrix *structuredMatrix) Reduce(f func(Scalar, ConstScalar) Scalar, r Scalar) Scalar {
+  n, m := matrix.Dims()
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      r = f(r, matrix.ConstAt(i, j))
+    }
+  }
+  return r
+}
+
+func (matrix *structuredMatrix) ElementType() ScalarType {
+  return matrix.values.ElementType()
+}
+
+/* permutations
+ * -------------------------------------------------------------------------- */
+
+// Apply a permutation to a dense copy of the matrix and copy the result
+// back. An error is returned if the permuted matrix does not fit the
+// structure of the matrix.
+func (matrix *structuredMatrix) permute(f func(Matrix) error) error {
+  n, m := matrix.Dims()
+  tmp  := AsDenseMatrix(matrix.ElementType(), matrix)
+  if err := f(tmp); err != nil {
+    return err
+  }
+  r := matrix.clone()
+  r.Set(tmp)
+  for i := 0; i < n; i++ {
+    for j := 0; j < m; j++ {
+      if r.Float64At(i, j) != tmp.Float64At(i, j) {
+        return fmt.Errorf("permutation does not preserve the structure of the %s matrix", matrix.layout.name())
+      }
+    }
+  }
+  matrix.Set(tmp)
+  return nil
+}
+
+func (matrix *structuredMatrix) SwapRows(i, j int) error {
+  return matrix.permute(func(a Matrix) error { return a.SwapRows(i, j) })
+}
+
+func (matrix *structuredMatrix) SwapColumns(i, j int) error {
+  return matrix.permute(func(a Matrix) error { return a.SwapColumns(i, j) })
+}
+
+func (matrix *structuredMatrix) PermuteRows(pi []int) error {
+  return matrix.permute(func(a Matrix) error { return a.PermuteRows(pi) })
+}
+
+func (matrix *structuredMatrix) PermuteColumns(pi []int) error {
+  return matrix.permute(func(a Matrix) error { return a.PermuteColumns(pi) })
+}
+
+func (matrix *structuredMatrix) SymmetricPermutation(pi []int) error {
+  return matrix.permute(func(a Matrix) error { return a.SymmetricPermutation(pi) })
+}
+
+/* type conversion
+ * -------------------------------------------------------------------------- */
+
+func (m *structuredMatrix) String() string {
+  var buffer bytes.Buffer
+  buffer.WriteString("[")
+  for i := 0; i < m.rows; i++ {
+    if i != 0 {
+      buffer.WriteString(",\n ")
+    }
+    buffer.WriteString("[")
+    for j := 0; j < m.cols; j++ {
+      if j != 0 {
+        buffer.WriteString(", ")
+      }
+      buffer.WriteString(m.ConstAt(i,j).String())
+    }
+    buffer.WriteString("]")
+  }
+  buffer.WriteString("]")
+  return buffer.String()
+}
+
+func (a *structuredMatrix) Table() string {
+  var buffer bytes.Buffer
+  n, m := a.Dims()
+  for i := 0; i < n; i++ {
+    if i != 0 {
+      buffer.WriteString("\n")
+    }
+    for j := 0; j < m; j++ {
+      if j != 0 {
+        buffer.WriteString(" ")
+      }
+      buffer.WriteString(a.ConstAt(i,j).String())
+    }
+  }
+  return buffer.String()
+}
+
+func (m *structuredMatrix) Export(filename string) error {
+  f, err := os.Create(filename)
+  if err != nil {
+    return err
+  }
+  defer f.Close()
+
+  w := bufio.NewWriter(f)
+  defer w.Flush()
+
+  if _, err := fmt.Fprintf(w, "%s\n", m.Table()); err != nil {
+    return err
+  }
+  return nil
+}
+
+/* json and binary encoding
+ * -------------------------------------------------------------------------- */
+
+// Structured matrices are encoded as dense matrices.
+func (a *structuredMatrix) MarshalJSON() ([]byte, error) {
+  return AsDenseMatrix(a.ElementType(), a).MarshalJSON()
+}
+
+func (a *structuredMatrix) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseMatrix(a)
+}
+
+/* math operations
+ * -------------------------------------------------------------------------- */
+
+func (r *structuredMatrix) checkDims(a ConstMatrix) {
+  if n, m := a.Dims(); n != r.rows || m != r.cols {
+    panic("matrix dimensions do not match!")
+  }
+}
+
+// Element-wise addition of two matrices. Only elements stored in r are
+// computed. The result is stored in r.
+func (r *structuredMatrix) MaddM(a, b ConstMatrix) Matrix {
+  r.checkDims(a)
+  r.checkDims(b)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Add(a.ConstAt(i, j), b.ConstAt(i, j))
+  })
+  return r.self
+}
+
+// Element-wise addition of a matrix and a scalar. Only elements stored in
+// r are computed. The result is stored in r.
+func (r *structuredMatrix) MaddS(a ConstMatrix, b ConstScalar) Matrix {
+  r.checkDims(a)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Add(a.ConstAt(i, j), b)
+  })
+  return r.self
+}
+
+// Element-wise substraction of two matrices. Only elements stored in r are
+// computed. The result is stored in r.
+func (r *structuredMatrix) MsubM(a, b ConstMatrix) Matrix {
+  r.checkDims(a)
+  r.checkDims(b)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Sub(a.ConstAt(i, j), b.ConstAt(i, j))
+  })
+  return r.self
+}
+
+// Element-wise substractor of a matrix and a scalar. Only elements stored
+// in r are computed. The result is stored in r.
+func (r *structuredMatrix) MsubS(a ConstMatrix, b ConstScalar) Matrix {
+  r.checkDims(a)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Sub(a.ConstAt(i, j), b)
+  })
+  return r.self
+}
+
+// Element-wise multiplication of two matrices. Only elements stored in r
+// are computed. The result is stored in r.
+func (r *structuredMatrix) MmulM(a, b ConstMatrix) Matrix {
+  r.checkDims(a)
+  r.checkDims(b)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Mul(a.ConstAt(i, j), b.ConstAt(i, j))
+  })
+  return r.self
+}
+
+// Element-wise multiplication of a matrix and a scalar. Only elements
+// stored in r are computed. The result is stored in r.
+func (r *structuredMatrix) MmulS(a ConstMatrix, b ConstScalar) Matrix {
+  r.checkDims(a)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Mul(a.ConstAt(i, j), b)
+  })
+  return r.self
+}
+
+// Element-wise division of two matrices. Only elements stored in r are
+// computed. The result is stored in r.
+func (r *structuredMatrix) MdivM(a, b ConstMatrix) Matrix {
+  r.checkDims(a)
+  r.checkDims(b)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Div(a.ConstAt(i, j), b.ConstAt(i, j))
+  })
+  return r.self
+}
+
+// Element-wise division of a matrix and a scalar. Only elements stored in
+// r are computed. The result is stored in r.
+func (r *structuredMatrix) MdivS(a ConstMatrix, b ConstScalar) Matrix {
+  r.checkDims(a)
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Div(a.ConstAt(i, j), b)
+  })
+  return r.self
+}
+
+// Matrix product of a and b. Only elements stored in r are computed and
+// structural zeros of a and b are skipped. The result is stored in r.
+func (r *structuredMatrix) MdotM(a, b ConstMatrix) Matrix {
+  n , m  := r.Dims()
+  n1, m1 := a.Dims()
+  n2, m2 := b.Dims()
+  if n1 != n || m2 != m || m1 != n2 {
+    panic("matrix dimensions do not match!")
+  }
+  t1 := NullScalar(r.ElementType())
+  t2 := NullDenseVector(r.ElementType(), r.values.Dim())
+  r.forEachStored(func(i, j, k int) {
+    s := t2.At(k)
+    from, to := constMatrixProductRange(a, b, i, j)
+    for l := from; l < to; l++ {
+      t1.Mul(a.ConstAt(i, l), b.ConstAt(l, j))
+      s.Add(s, t1)
+    }
+  })
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Set(t2.ConstAt(k))
+  })
+  return r.self
+}
+
+// Outer product of two vectors. Only elements stored in r are computed.
+// The result is stored in r.
+func (r *structuredMatrix) Outer(a, b ConstVector) Matrix {
+  n, m := r.Dims()
+  if a.Dim() != n || b.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).Mul(a.ConstAt(i), b.ConstAt(j))
+  })
+  return r.self
+}
+
+// Compute the Jacobian of f at x_. Only elements stored in r are computed.
+// The result is stored in r.
+func (r *structuredMatrix) Jacobian(f func(ConstVector) ConstVector, x_ MagicVector) Matrix {
+  n, m := r.Dims()
+  x := x_.CloneMagicVector()
+  x.Variables(1)
+  // compute Jacobian
+  y := f(x)
+  if x.Dim() != m || y.Dim() != n {
+    panic("invalid dimension")
+  }
+  // copy derivatives
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).SetFloat64(y.ConstAt(i).GetDerivative(j))
+  })
+  return r.self
+}
+
+// Compute the Hessian of f at x_. Only elements stored in r are computed.
+// The result is stored in r.
+func (r *structuredMatrix) Hessian(f func(ConstVector) ConstScalar, x_ MagicVector) Matrix {
+  n, m := r.Dims()
+  if x_.Dim() != n || n != m {
+    panic("invalid dimension")
+  }
+  x := x_.CloneMagicVector()
+  x.Variables(2)
+  // evaluate function
+  y := f(x)
+  // copy second derivatives
+  r.forEachStored(func(i, j, k int) {
+    r.values.At(k).SetFloat64(y.GetHessian(i, j))
+  })
+  return r.self
+}
+
+/* broadcasting of vectors over matrix rows and columns
+ * -------------------------------------------------------------------------- */
+
+func (r *structuredMatrix) broadcast(a ConstMatrix, b ConstVector, rows bool, f func(Scalar, ConstScalar, ConstScalar)) Matrix {
+  r.checkDims(a)
+  if (rows && b.Dim() != r.cols) || (!rows && b.Dim() != r.rows) {
+    panic("matrix/vector dimensions do not match!")
+  }
+  r.forEachStored(func(i, j, k int) {
+    if rows {
+      f(r.values.At(k), a.ConstAt(i, j), b.ConstAt(j))
+    } else {
+      f(r.values.At(k), a.ConstAt(i, j), b.ConstAt(i))
+    }
+  })
+  return r.self
+}
+
+func (r *structuredMatrix) MaddVrows(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, true,  func(s Scalar, x, y ConstScalar) { s.Add(x, y) })
+}
+
+func (r *structuredMatrix) MaddVcols(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, false, func(s Scalar, x, y ConstScalar) { s.Add(x, y) })
+}
+
+func (r *structuredMatrix) MsubVrows(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, true,  func(s Scalar, x, y ConstScalar) { s.Sub(x, y) })
+}
+
+func (r *structuredMatrix) MsubVcols(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, false, func(s Scalar, x, y ConstScalar) { s.Sub(x, y) })
+}
+
+func (r *structuredMatrix) MmulVrows(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, true,  func(s Scalar, x, y ConstScalar) { s.Mul(x, y) })
+}
+
+func (r *structuredMatrix) MmulVcols(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, false, func(s Scalar, x, y ConstScalar) { s.Mul(x, y) })
+}
+
+func (r *structuredMatrix) MdivVrows(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, true,  func(s Scalar, x, y ConstScalar) { s.Div(x, y) })
+}
+
+func (r *structuredMatrix) MdivVcols(a ConstMatrix, b ConstVector) Matrix {
+  return r.broadcast(a, b, false, func(s Scalar, x, y ConstScalar) { s.Div(x, y) })
+}
+
+/* iterator methods
+ * -------------------------------------------------------------------------- */
+
+func (m *structuredMatrix) Iterator() MatrixIterator {
+  return m.iteratorFrom(0, 0)
+}
+
+func (m *structuredMatrix) IteratorFrom(i, j int) MatrixIterator {
+  return m.iteratorFrom(i, j)
+}
+
+func (m *structuredMatrix) ConstIterator() MatrixConstIterator {
+  return m.iteratorFrom(0, 0)
+}
+
+func (m *structuredMatrix) ConstIteratorFrom(i, j int) MatrixConstIterator {
+  return m.iteratorFrom(i, j)
+}
+
+func (m *structuredMatrix) JointIterator(b ConstMatrix) MatrixJointIterator {
+  r := structuredMatrixJointIterator{it1: m.iteratorFrom(0, 0), it2: b.ConstIterator()}
+  r.Next()
+  return &r
+}
+
+func (m *structuredMatrix) iteratorFrom(i, j int) *structuredMatrixIterator {
+  r := structuredMatrixIterator{m, i, j-1}
+  r.Next()
+  return &r
+}
+
+/* iterator
+ * -------------------------------------------------------------------------- */
+
+// Iterator over all non-zero elements of a structured matrix in row-major
+// order.
+type structuredMatrixIterator struct {
+  m   *structuredMatrix
+  i, j int
+}
+
+func (obj *structuredMatrixIterator) Get() Scalar {
+  return obj.m.values.At(obj.m.index(obj.i, obj.j))
+}
+
+func (obj *structuredMatrixIterator) GetConst() ConstScalar {
+  return obj.m.values.ConstAt(obj.m.index(obj.i, obj.j))
+}
+
+func (obj *structuredMatrixIterator) Ok() bool {
+  return obj.i < obj.m.rows
+}
+
+func (obj *structuredMatrixIterator) next() {
+  from, to := obj.m.rowRange(obj.i)
+  if obj.j++; obj.j < from {
+    obj.j = from
+  }
+  for obj.j >= to {
+    if obj.i++; obj.i >= obj.m.rows {
+      return
+    }
+    from, to = obj.m.rowRange(obj.i)
+    obj.j    = from
+  }
+}
+
+func (obj *structuredMatrixIterator) Next() {
+  for obj.next(); obj.Ok(); obj.next() {
+    if k := obj.m.index(obj.i, obj.j); k >= 0 && obj.m.values.Float64At(k) != 0.0 {
+      break
+    }
+  }
+}
+
+func (obj *structuredMatrixIterator) Index() (int, int) {
+  return obj.i, obj.j
+}
+
+func (obj *structuredMatrixIterator) CloneIterator() MatrixIterator {
+  return &structuredMatrixIterator{obj.m, obj.i, obj.j}
+}
+
+func (obj *structuredMatrixIterator) CloneConstIterator() MatrixConstIterator {
+  return &structuredMatrixIterator{obj.m, obj.i, obj.j}
+}
+
+/* joint iterator
+ * -------------------------------------------------------------------------- */
+
+type structuredMatrixJointIterator struct {
+  it1 *structuredMatrixIterator
+  it2  MatrixConstIterator
+  i, j int
+  s1   Scalar
+  s2   ConstScalar
+}
+
+func (obj *structuredMatrixJointIterator) Index() (int, int) {
+  return obj.i, obj.j
+}
+
+func (obj *structuredMatrixJointIterator) Ok() bool {
+  return obj.s1 != nil || obj.s2 != nil
+}
+
+func (obj *structuredMatrixJointIterator) Next() {
+  ok1 := obj.it1.Ok()
+  ok2 := obj.it2.Ok()
+  obj.s1 = nil
+  obj.s2 = nil
+  if ok1 {
+    obj.i, obj.j = obj.it1.Index()
+    obj.s1       = obj.it1.Get()
+  }
+  if ok2 {
+    i, j := obj.it2.Index()
+    switch {
+    case obj.i > i || (obj.i == i && obj.j > j) || !ok1:
+      obj.i, obj.j = i, j
+      obj.s1       = nil
+      obj.s2       = obj.it2.GetConst()
+    case obj.i == i && obj.j == j:
+      obj.s2       = obj.it2.GetConst()
+    }
+  }
+  if obj.s1 != nil {
+    obj.it1.Next()
+  }
+  if obj.s2 != nil {
+    obj.it2.Next()
+  }
+}
+
+func (obj *structuredMatrixJointIterator) Get() (Scalar, ConstScalar) {
+  if obj.s2 == nil && obj.s1 != nil {
+    return obj.s1, obj.it1.m.zero
+  }
+  return obj.s1, obj.s2
+}
+
+func (obj *structuredMatrixJointIterator) GetConst() (ConstScalar, ConstScalar) {
+  s1, s2 := obj.Get()
+  if s1 == nil {
+    return nil, s2
+  }
+  return s1, s2
+}
+
+func (obj *structuredMatrixJointIterator) clone() *structuredMatrixJointIterator {
+  r := *obj
+  r.it1 = &structuredMatrixIterator{obj.it1.m, obj.it1.i, obj.it1.j}
+  r.it2 = obj.it2.CloneConstIterator()
+  return &r
+}
+
+func (obj *structuredMatrixJointIterator) CloneJointIterator() MatrixJointIterator {
+  return obj.clone()
+}
+
+func (obj *structuredMatrixJointIterator) CloneConstJointIterator() MatrixConstJointIterator {
+  return obj.clone()
+}
@@ -0,0 +1,131 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+//import "fmt"
+import "testing"
+
+/* -------------------------------------------------------------------------- */
+
+func TestStructuredMatrix(t *testing.T) {
+  m := NewDenseFloat64Matrix([]float64{
+    1, 2, 3, 4,
+    5, 6, 7, 8,
+    9,10,11,12,
+   13,14,15,16 }, 4, 4)
+  v := NewDenseFloat64Vector([]float64{1,-1,2,-2})
+
+  for _, a := range []Matrix{
+    AsDiagonalMatrix       (Float64Type, m),
+    AsUpperTriangularMatrix(Float64Type, m),
+    AsLowerTriangularMatrix(Float64Type, m),
+    AsSymmetricPackedMatrix(Float64Type, m),
+    AsBandMatrix           (Float64Type, m, 1, 2) } {
+    d := AsDenseFloat64Matrix(a)
+
+    // matrix vector products
+    r1 := NullDenseFloat64Vector(4)
+    r2 := NullDenseFloat64Vector(4)
+    if !r1.MdotV(a, v).Equals(r2.MdotV(d, v), 1e-12) {
+      t.Error("test failed")
+    }
+    if !r1.VdotM(v, a).Equals(r2.VdotM(v, d), 1e-12) {
+      t.Error("test failed")
+    }
+    // matrix products
+    s1 := NullDenseFloat64Matrix(4, 4)
+    s2 := NullDenseFloat64Matrix(4, 4)
+    if !s1.MdotM(a, a.T()).Equals(s2.MdotM(d, d.T()), 1e-12) {
+      t.Error("test failed")
+    }
+    if !s1.MdotM(m, a).Equals(s2.MdotM(m, d), 1e-12) {
+      t.Error("test failed")
+    }
+    // sparse matrix products use iterators
+    s3 := NullSparseFloat64Matrix(4, 4)
+    if !s3.MdotM(a, m).Equals(s2.MdotM(d, m), 1e-12) {
+      t.Error("test failed")
+    }
+    // slices share memory
+    if !a.Slice(1, 3, 0, 4).Equals(d.Slice(1, 3, 0, 4), 1e-12) {
+      t.Error("test failed")
+    }
+    a.Slice(1, 3, 1, 3).At(1, 1).SetFloat64(-1)
+    if a.Float64At(2, 2) != -1 {
+      t.Error("test failed")
+    }
+  }
+}
+
+func TestStructuredMatrixStorage(t *testing.T) {
+  a := NullSymmetricPackedMatrix(Float64Type, 3)
+  a.At(0, 2).SetFloat64(2)
+  if a.Float64At(2, 0) != 2 || a.AsVector().Dim() != 6 {
+    t.Error("test failed")
+  }
+  u := NewUpperTriangularMatrix(NewDenseFloat64Vector([]float64{1,2,3,4,5,6}), 3)
+  if u.Float64At(0, 2) != 4 || u.Float64At(2, 0) != 0 || u.T().Float64At(2, 1) != 5 {
+    t.Error("test failed")
+  }
+  if _, ok := u.T().(*LowerTriangularMatrix); !ok {
+    t.Error("test failed")
+  }
+  // structural zeros cannot be modified
+  func() {
+    defer func() {
+      if recover() == nil {
+        t.Error("test failed")
+      }
+    }()
+    u.At(2, 0).SetFloat64(1)
+  }()
+  // permutations must preserve the structure
+  if err := u.SwapRows(0, 1); err == nil {
+    t.Error("test failed")
+  }
+  if err := a.SymmetricPermutation([]int{2,1,0}); err != nil || a.Float64At(0, 2) != 2 {
+    t.Error("test failed")
+  }
+  b := NullBandMatrix(Float64Type, 4, 3, 1, 0)
+  b.SetIdentity()
+  if kl, ku := b.T().(*BandMatrix).Bandwidth(); kl != 0 || ku != 1 {
+    t.Error("test failed")
+  }
+  n := 0
+  for it := b.ConstIterator(); it.Ok(); it.Next() {
+    if i, j := it.Index(); i != j {
+      t.Error("test failed")
+    }
+    n++
+  }
+  if n != 3 {
+    t.Error("test failed")
+  }
+}
+
+func TestStructuredMatrixReal(t *testing.T) {
+  a := NewDiagonalMatrix(NewDenseReal64Vector([]float64{1,2}))
+  a.AsVector().(DenseReal64Vector).Variables(1)
+
+  r := NullDenseReal64Vector(2)
+  r.MdotV(a, NewDenseReal64Vector([]float64{3,4}))
+  if r[1].GetFloat64() != 8 || r[1].GetDerivative(1) != 4 || r[1].GetDerivative(0) != 0 {
+    t.Error("test failed")
+  }
+}
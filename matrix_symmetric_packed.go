@@ -0,0 +1,118 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+
+// Elements of a symmetric matrix are stored as the lower triangular part
+// in row-major order. Element (p, q) and (q, p) share storage.
+type symmetricPackedLayout struct {
+  n int
+}
+
+func (l symmetricPackedLayout) dims() (int, int) {
+  return l.n, l.n
+}
+
+func (l symmetricPackedLayout) size() int {
+  return l.n*(l.n+1)/2
+}
+
+func (l symmetricPackedLayout) index(p, q int) int {
+  if p < q {
+    p, q = q, p
+  }
+  return p*(p+1)/2 + q
+}
+
+func (l symmetricPackedLayout) rowRange(p int) (int, int) {
+  return 0, l.n
+}
+
+func (l symmetricPackedLayout) colRange(q int) (int, int) {
+  return 0, l.n
+}
+
+func (l symmetricPackedLayout) primary(p, q int) bool {
+  return p >= q
+}
+
+func (l symmetricPackedLayout) name() string {
+  return "symmetric"
+}
+
+/* -------------------------------------------------------------------------- */
+
+// A symmetric matrix that stores only its lower triangular part. Setting
+// element (i, j) also sets element (j, i).
+type SymmetricPackedMatrix struct {
+  structuredMatrix
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+// Create a symmetric n x n matrix. The vector must contain the n(n+1)/2
+// lower triangular elements in row-major order. The matrix shares memory
+// with the vector.
+func NewSymmetricPackedMatrix(values Vector, n int) *SymmetricPackedMatrix {
+  r := &SymmetricPackedMatrix{newStructuredMatrix(values, symmetricPackedLayout{n})}
+  r.self = r
+  return r
+}
+
+func NullSymmetricPackedMatrix(t ScalarType, n int) *SymmetricPackedMatrix {
+  return NewSymmetricPackedMatrix(NullDenseVector(t, n*(n+1)/2), n)
+}
+
+// Copy the lower triangular part of a square matrix.
+func AsSymmetricPackedMatrix(t ScalarType, a ConstMatrix) *SymmetricPackedMatrix {
+  n, m := a.Dims()
+  if n != m {
+    panic("not a square matrix")
+  }
+  r := NullSymmetricPackedMatrix(t, n)
+  r.Set(a)
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+func (matrix *SymmetricPackedMatrix) Clone() *SymmetricPackedMatrix {
+  r := &SymmetricPackedMatrix{matrix.clone()}
+  r.self = r
+  return r
+}
+
+func (matrix *SymmetricPackedMatrix) CloneMatrix() Matrix {
+  return matrix.Clone()
+}
+
+func (matrix *SymmetricPackedMatrix) CloneConstMatrix() ConstMatrix {
+  return matrix.Clone()
+}
+
+func (matrix *SymmetricPackedMatrix) T() Matrix {
+  r := &SymmetricPackedMatrix{matrix.transpose()}
+  r.self = r
+  return r
+}
+
+func (matrix *SymmetricPackedMatrix) IsSymmetric(epsilon float64) bool {
+  return true
+}
@@ -0,0 +1,218 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+
+// Upper triangular elements are stored column by column, i.e. element
+// (p, q) with p <= q is stored at position q(q+1)/2 + p. This is the same
+// order as row-wise storage of the lower triangular transposed matrix.
+type upperTriangularLayout struct {
+  n int
+}
+
+func (l upperTriangularLayout) dims() (int, int) {
+  return l.n, l.n
+}
+
+func (l upperTriangularLayout) size() int {
+  return l.n*(l.n+1)/2
+}
+
+func (l upperTriangularLayout) index(p, q int) int {
+  if p > q {
+    return -1
+  }
+  return q*(q+1)/2 + p
+}
+
+func (l upperTriangularLayout) rowRange(p int) (int, int) {
+  return p, l.n
+}
+
+func (l upperTriangularLayout) colRange(q int) (int, int) {
+  return 0, q+1
+}
+
+func (l upperTriangularLayout) primary(p, q int) bool {
+  return true
+}
+
+func (l upperTriangularLayout) name() string {
+  return "triangular"
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Lower triangular elements are stored row by row, i.e. element (p, q)
+// with p >= q is stored at position p(p+1)/2 + q.
+type lowerTriangularLayout struct {
+  n int
+}
+
+func (l lowerTriangularLayout) dims() (int, int) {
+  return l.n, l.n
+}
+
+func (l lowerTriangularLayout) size() int {
+  return l.n*(l.n+1)/2
+}
+
+func (l lowerTriangularLayout) index(p, q int) int {
+  if p < q {
+    return -1
+  }
+  return p*(p+1)/2 + q
+}
+
+func (l lowerTriangularLayout) rowRange(p int) (int, int) {
+  return 0, p+1
+}
+
+func (l lowerTriangularLayout) colRange(q int) (int, int) {
+  return q, l.n
+}
+
+func (l lowerTriangularLayout) primary(p, q int) bool {
+  return true
+}
+
+func (l lowerTriangularLayout) name() string {
+  return "triangular"
+}
+
+/* -------------------------------------------------------------------------- */
+
+// A square matrix that stores only elements on and above the diagonal.
+type UpperTriangularMatrix struct {
+  structuredMatrix
+}
+
+// A square matrix that stores only elements on and below the diagonal.
+type LowerTriangularMatrix struct {
+  structuredMatrix
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+// Create an upper triangular n x n matrix. The vector must contain the
+// n(n+1)/2 upper triangular elements in column-major order. The matrix
+// shares memory with the vector.
+func NewUpperTriangularMatrix(values Vector, n int) *UpperTriangularMatrix {
+  r := &UpperTriangularMatrix{newStructuredMatrix(values, upperTriangularLayout{n})}
+  r.self = r
+  return r
+}
+
+func NullUpperTriangularMatrix(t ScalarType, n int) *UpperTriangularMatrix {
+  return NewUpperTriangularMatrix(NullDenseVector(t, n*(n+1)/2), n)
+}
+
+// Copy the upper triangular part of a square matrix.
+func AsUpperTriangularMatrix(t ScalarType, a ConstMatrix) *UpperTriangularMatrix {
+  n, m := a.Dims()
+  if n != m {
+    panic("not a square matrix")
+  }
+  r := NullUpperTriangularMatrix(t, n)
+  r.Set(a)
+  return r
+}
+
+// Create a lower triangular n x n matrix. The vector must contain the
+// n(n+1)/2 lower triangular elements in row-major order. The matrix
+// shares memory with the vector.
+func NewLowerTriangularMatrix(values Vector, n int) *LowerTriangularMatrix {
+  r := &LowerTriangularMatrix{newStructuredMatrix(values, lowerTriangularLayout{n})}
+  r.self = r
+  return r
+}
+
+func NullLowerTriangularMatrix(t ScalarType, n int) *LowerTriangularMatrix {
+  return NewLowerTriangularMatrix(NullDenseVector(t, n*(n+1)/2), n)
+}
+
+// Copy the lower triangular part of a square matrix.
+func AsLowerTriangularMatrix(t ScalarType, a ConstMatrix) *LowerTriangularMatrix {
+  n, m := a.Dims()
+  if n != m {
+    panic("not a square matrix")
+  }
+  r := NullLowerTriangularMatrix(t, n)
+  r.Set(a)
+  return r
+}
+
+/* upper triangular matrix
+ * -------------------------------------------------------------------------- */
+
+func (matrix *UpperTriangularMatrix) Clone() *UpperTriangularMatrix {
+  r := &UpperTriangularMatrix{matrix.clone()}
+  r.self = r
+  return r
+}
+
+func (matrix *UpperTriangularMatrix) CloneMatrix() Matrix {
+  return matrix.Clone()
+}
+
+func (matrix *UpperTriangularMatrix) CloneConstMatrix() ConstMatrix {
+  return matrix.Clone()
+}
+
+// Returns a lower triangular matrix that shares memory with the original
+// matrix.
+func (matrix *UpperTriangularMatrix) T() Matrix {
+  r := &LowerTriangularMatrix{matrix.transpose()}
+  r.self = r
+  return r
+}
+
+func (matrix *UpperTriangularMatrix) Tip() {
+  panic("Tip(): triangular matrices cannot be transposed in-place")
+}
+
+/* lower triangular matrix
+ * -------------------------------------------------------------------------- */
+
+func (matrix *LowerTriangularMatrix) Clone() *LowerTriangularMatrix {
+  r := &LowerTriangularMatrix{matrix.clone()}
+  r.self = r
+  return r
+}
+
+func (matrix *LowerTriangularMatrix) CloneMatrix() Matrix {
+  return matrix.Clone()
+}
+
+func (matrix *LowerTriangularMatrix) CloneConstMatrix() ConstMatrix {
+  return matrix.Clone()
+}
+
+// Returns an upper triangular matrix that shares memory with the original
+// matrix.
+func (matrix *LowerTriangularMatrix) T() Matrix {
+  r := &UpperTriangularMatrix{matrix.transpose()}
+  r.self = r
+  return r
+}
+
+func (matrix *LowerTriangularMatrix) Tip() {
+  panic("Tip(): triangular matrices cannot be transposed in-place")
+}
@@ -18,13 +18,14 @@ package scalarEstimator
 
 /* -------------------------------------------------------------------------- */
 
-//import   "fmt"
+import   "fmt"
 import   "math"
 
 import . "github.com/pbenner/autodiff/statistics"
 
 import . "github.com/pbenner/autodiff"
 import   "github.com/pbenner/autodiff/algorithm/bfgs"
+import   "github.com/pbenner/autodiff/algorithm/lbfgs"
 import   "github.com/pbenner/autodiff/algorithm/newton"
 import   "github.com/pbenner/autodiff/algorithm/rprop"
 import . "github.com/pbenner/threadpool"
@@ -39,6 +40,11 @@ type NumericEstimator struct {
   MaxIterations   int
   StepInit        float64
   Eta           []float64
+  // lower and upper bounds for the parameters of the density function (nil
+  // if not bounded), which are handled explicitly by the lbfgs method and
+  // otherwise added to the feasibility constraints
+  LowerBounds   []float64
+  UpperBounds   []float64
   Hook            func(variables ConstVector, r ConstScalar) error
 }
 
@@ -65,6 +71,8 @@ func (obj *NumericEstimator) Clone() *NumericEstimator {
   r.Hook          = obj.Hook
   r.StepInit      = obj.StepInit
   r.Eta           = []float64{obj.Eta[0], obj.Eta[1]}
+  r.LowerBounds   = obj.LowerBounds
+  r.UpperBounds   = obj.UpperBounds
   r.x             = obj.x
   return r
 }
@@ -96,6 +104,14 @@ func (obj *NumericEstimator) Estimate(gamma ConstVector, p ThreadPool) error {
     f[i] = obj.ScalarPdf.CloneScalarPdf()
   }
   constraints_f := func(variables Vector) bool {
+    for i := 0; i < variables.Dim(); i++ {
+      if obj.LowerBounds != nil && variables.At(i).GetFloat64() < obj.LowerBounds[i] {
+        return false
+      }
+      if obj.UpperBounds != nil && variables.At(i).GetFloat64() > obj.UpperBounds[i] {
+        return false
+      }
+    }
     if err := f[0].SetParameters(variables); err != nil {
       return false
     }
@@ -159,6 +175,13 @@ func (obj *NumericEstimator) Estimate(gamma ConstVector, p ThreadPool) error {
   theta_0 := obj.ScalarPdf.GetParameters()
   theta_0  = AsDenseReal64Vector(theta_0)
 
+  if obj.LowerBounds != nil && len(obj.LowerBounds) != theta_0.Dim() {
+    return fmt.Errorf("lower bounds have invalid length (%d instead of %d)", len(obj.LowerBounds), theta_0.Dim())
+  }
+  if obj.UpperBounds != nil && len(obj.UpperBounds) != theta_0.Dim() {
+    return fmt.Errorf("upper bounds have invalid length (%d instead of %d)", len(obj.UpperBounds), theta_0.Dim())
+  }
+
   var theta_n ConstVector
   var err error
 
@@ -175,6 +198,12 @@ func (obj *NumericEstimator) Estimate(gamma ConstVector, p ThreadPool) error {
       bfgs.Epsilon      {obj.Epsilon},
       bfgs.MaxIterations{obj.MaxIterations},
       bfgs.Constraints  {constraints_f})
+  case "lbfgs":
+    theta_n, err = lbfgs.Run(objective_f, theta_0,
+      lbfgs.Epsilon      {Value: obj.Epsilon},
+      lbfgs.MaxIterations{Value: obj.MaxIterations},
+      lbfgs.Constraints  {Value: constraints_f},
+      lbfgs.Bounds       {Lower: obj.LowerBounds, Upper: obj.UpperBounds})
   case "rprop":
     theta_n, err = rprop.Run(objective_f, theta_0, obj.StepInit, obj.Eta,
       rprop.Epsilon      {obj.Epsilon},
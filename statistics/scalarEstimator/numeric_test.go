@@ -0,0 +1,70 @@
+/* Copyright (C) 2017-2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package scalarEstimator
+
+/* -------------------------------------------------------------------------- */
+
+//import   "fmt"
+import   "math"
+import   "testing"
+
+import   "github.com/pbenner/autodiff/statistics/scalarDistribution"
+
+import . "github.com/pbenner/autodiff"
+import . "github.com/pbenner/threadpool"
+
+/* -------------------------------------------------------------------------- */
+
+func TestNumericBounds(test *testing.T) {
+  x := NewDenseFloat64Vector([]float64{
+    // > rgamma(20, 10, 10)
+    0.7620401, 0.8951688, 1.1219072, 0.7852892, 1.3953805, 1.3548050,
+    0.9736968, 0.6513262, 1.2858288, 0.8097734, 0.9810374, 1.5896749,
+    1.4171935, 0.9386839, 0.9078442, 0.8887607, 0.8886225, 0.9651098,
+    1.0939781, 0.6725607 })
+  mean := 0.0
+  for i := 0; i < x.Dim(); i++ {
+    mean += x[i]/float64(x.Dim())
+  }
+  g, _ := scalarDistribution.NewGammaDistribution(NewFloat64(2.0), NewFloat64(2.0))
+
+  e, _ := NewNumericEstimator(g)
+  e.Method        = "lbfgs"
+  e.MaxIterations = 1000
+  // the unconstrained estimate of alpha is much larger than one
+  e.LowerBounds   = []float64{1e-8, 1e-8}
+  e.UpperBounds   = []float64{1.0, math.Inf(1)}
+
+  if err := e.EstimateOnData(x, nil, ThreadPool{}); err != nil {
+    test.Error(err); return
+  }
+  r, _ := e.GetEstimate()
+  p    := r.GetParameters()
+  // alpha is at the upper bound and beta is the maximum likelihood
+  // estimate of an exponential distribution
+  if math.Abs(p.At(0).GetFloat64() - 1.0) > 1e-10 {
+    test.Error("test failed")
+  }
+  if math.Abs(p.At(1).GetFloat64() - 1.0/mean) > 1e-6 {
+    test.Error("test failed")
+  }
+  // bounds must match the number of parameters
+  e.LowerBounds = []float64{1e-8}
+  if err := e.EstimateOnData(x, nil, ThreadPool{}); err == nil {
+    test.Error("test failed")
+  }
+}
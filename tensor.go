@@ -0,0 +1,255 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "fmt"
+
+/* tensor type declaration
+ * -------------------------------------------------------------------------- */
+
+type constTensor interface {
+  CloneConstTensor()                   ConstTensor
+  Rank            ()                   int
+  Shape           ()                   []int
+  Strides         ()                   []int
+  Size            ()                   int
+  Equals          (ConstTensor, float64) bool
+  Float64At       (...int)             float64
+  ConstAt         (...int)             ConstScalar
+  ConstSlice      (from, to []int)     ConstTensor
+  ConstIndex      (axis, i int)        ConstTensor
+  ConstReshape    (...int)             (ConstTensor, error)
+  ConstTranspose  (...int)             (ConstTensor, error)
+  // type conversions
+  AsConstVector   ()                   ConstVector
+  AsConstMatrix   ()                   ConstMatrix
+}
+
+type ConstTensor interface {
+  ConstScalarContainer
+  constTensor
+}
+
+type tensor interface {
+  constTensor
+  CloneTensor     ()                   Tensor
+  At              (...int)             Scalar
+  Reset           ()
+  Set             (ConstTensor)
+  // views
+  Slice           (from, to []int)     Tensor
+  Index           (axis, i int)        Tensor
+  Reshape         (...int)             (Tensor, error)
+  Transpose       (...int)             (Tensor, error)
+  // type conversions
+  AsVector        ()                   Vector
+  AsMatrix        ()                   Matrix
+  // element-wise operations with broadcasting
+  TaddT(a,             b ConstTensor)  Tensor
+  TsubT(a,             b ConstTensor)  Tensor
+  TmulT(a,             b ConstTensor)  Tensor
+  TdivT(a,             b ConstTensor)  Tensor
+  TaddS(a ConstTensor, b ConstScalar)  Tensor
+  TsubS(a ConstTensor, b ConstScalar)  Tensor
+  TmulS(a ConstTensor, b ConstScalar)  Tensor
+  TdivS(a ConstTensor, b ConstScalar)  Tensor
+  // reductions along a single axis
+  TsumAxis (a ConstTensor, axis int)   Tensor
+  TmeanAxis(a ConstTensor, axis int)   Tensor
+  TmaxAxis (a ConstTensor, axis int)   Tensor
+  TminAxis (a ConstTensor, axis int)   Tensor
+}
+
+type Tensor interface {
+  ScalarContainer
+  tensor
+}
+
+type MagicTensor interface {
+  MagicScalarContainer
+  tensor
+  CloneMagicTensor()                   MagicTensor
+  MagicAt         (...int)             MagicScalar
+  MagicSlice      (from, to []int)     MagicTensor
+  MagicIndex      (axis, i int)        MagicTensor
+  MagicReshape    (...int)             (MagicTensor, error)
+  MagicTranspose  (...int)             (MagicTensor, error)
+  ResetDerivatives()
+  // type conversions
+  AsMagicVector   ()                   MagicVector
+  AsMagicMatrix   ()                   MagicMatrix
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+// Allocate a new dense tensor with the given shape. All scalars are set
+// to zero.
+func NullDenseTensor(t ScalarType, shape ...int) *DenseTensor {
+  return NewDenseTensor(NullDenseVector(t, tensorSize(shape)), shape...)
+}
+
+func NullDenseMagicTensor(t ScalarType, shape ...int) *DenseMagicTensor {
+  return NewDenseMagicTensor(NullDenseMagicVector(t, tensorSize(shape)), shape...)
+}
+
+// Convert tensor type. The result is a contiguous copy of a.
+func AsDenseTensor(t ScalarType, a ConstTensor) *DenseTensor {
+  r := NullDenseTensor(t, a.Shape()...)
+  r.Set(a)
+  return r
+}
+
+func AsDenseMagicTensor(t ScalarType, a ConstTensor) *DenseMagicTensor {
+  r := NullDenseMagicTensor(t, a.Shape()...)
+  r.Set(a)
+  return r
+}
+
+// Create a rank-1 tensor from a vector. The tensor shares memory with
+// the vector.
+func DenseTensorFromVector(v Vector) *DenseTensor {
+  return NewDenseTensor(v, v.Dim())
+}
+
+func DenseMagicTensorFromVector(v MagicVector) *DenseMagicTensor {
+  return NewDenseMagicTensor(v, v.Dim())
+}
+
+// Create a rank-2 tensor from a matrix. Values and derivatives are copied.
+func DenseTensorFromMatrix(m ConstMatrix) *DenseTensor {
+  n1, n2 := m.Dims()
+  r := NullDenseTensor(m.ElementType(), n1, n2)
+  for i := 0; i < n1; i++ {
+    for j := 0; j < n2; j++ {
+      r.At(i, j).Set(m.ConstAt(i, j))
+    }
+  }
+  return r
+}
+
+func DenseMagicTensorFromMatrix(m ConstMatrix) *DenseMagicTensor {
+  n1, n2 := m.Dims()
+  r := NullDenseMagicTensor(m.ElementType(), n1, n2)
+  for i := 0; i < n1; i++ {
+    for j := 0; j < n2; j++ {
+      r.At(i, j).Set(m.ConstAt(i, j))
+    }
+  }
+  return r
+}
+
+/* broadcasting
+ * -------------------------------------------------------------------------- */
+
+// Compute the shape of the result of an element-wise operation on tensors
+// with shapes a and b. Shapes are aligned at the last axis, and axes of
+// length one are stretched to match the other shape (NumPy broadcasting).
+func BroadcastShape(a, b []int) ([]int, error) {
+  n := iMax(len(a), len(b))
+  r := make([]int, n)
+  for k := 0; k < n; k++ {
+    i := len(a) - n + k
+    j := len(b) - n + k
+    switch {
+    case i < 0:
+      r[k] = b[j]
+    case j < 0:
+      r[k] = a[i]
+    case a[i] == b[j] || b[j] == 1:
+      r[k] = a[i]
+    case a[i] == 1:
+      r[k] = b[j]
+    default:
+      return nil, fmt.Errorf("shapes %v and %v cannot be broadcast", a, b)
+    }
+  }
+  return r, nil
+}
+
+// Map index idx of the broadcast result onto tensor with given shape.
+func broadcastIndex(dst, idx, shape []int) {
+  d := len(idx) - len(shape)
+  for k := 0; k < len(shape); k++ {
+    if shape[k] == 1 {
+      dst[k] = 0
+    } else {
+      dst[k] = idx[d+k]
+    }
+  }
+}
+
+/* utility
+ * -------------------------------------------------------------------------- */
+
+func tensorSize(shape []int) int {
+  n := 1
+  for _, k := range shape {
+    if k < 0 {
+      panic("negative tensor dimension")
+    }
+    n *= k
+  }
+  return n
+}
+
+// Row-major strides for a contiguous tensor of the given shape.
+func tensorStrides(shape []int) []int {
+  r := make([]int, len(shape))
+  s := 1
+  for k := len(shape)-1; k >= 0; k-- {
+    r[k] = s
+    s   *= shape[k]
+  }
+  return r
+}
+
+func tensorShapeEquals(a, b []int) bool {
+  if len(a) != len(b) {
+    return false
+  }
+  for k := 0; k < len(a); k++ {
+    if a[k] != b[k] {
+      return false
+    }
+  }
+  return true
+}
+
+// Call f for all indices of a tensor with the given shape in row-major
+// order. The index slice is reused between calls.
+func tensorForEach(shape []int, f func([]int)) {
+  if tensorSize(shape) == 0 {
+    return
+  }
+  idx := make([]int, len(shape))
+  for {
+    f(idx)
+    k := len(shape)-1
+    for ; k >= 0; k-- {
+      if idx[k]++; idx[k] < shape[k] {
+        break
+      }
+      idx[k] = 0
+    }
+    if k < 0 {
+      return
+    }
+  }
+}
@@ -0,0 +1,479 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "fmt"
+import "bytes"
+
+/* tensor type declaration
+ * -------------------------------------------------------------------------- */
+
+// A dense tensor stores its elements in a dense vector. Element (i1, ..., in)
+// is located at position offset + i1*strides[0] + ... + in*strides[n-1] of
+// the vector, which allows slices, transpositions and reshapes to share
+// memory with the original tensor.
+type DenseTensor struct {
+  values  Vector
+  shape   []int
+  strides []int
+  offset  int
+}
+
+// A dense tensor of MagicScalars.
+type DenseMagicTensor struct {
+  DenseTensor
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+// Create a contiguous tensor with the given shape that uses values as
+// storage. The number of elements in values must match the shape.
+func NewDenseTensor(values Vector, shape ...int) *DenseTensor {
+  if values.Dim() != tensorSize(shape) {
+    panic("NewDenseTensor(): Tensor dimension does not fit input vector!")
+  }
+  r := DenseTensor{}
+  r.values  = values
+  r.shape   = append([]int{}, shape...)
+  r.strides = tensorStrides(shape)
+  r.offset  = 0
+  return &r
+}
+
+func NewDenseMagicTensor(values MagicVector, shape ...int) *DenseMagicTensor {
+  return &DenseMagicTensor{*NewDenseTensor(values, shape...)}
+}
+
+/* cloning
+ * -------------------------------------------------------------------------- */
+
+// Create a deep copy of the tensor. The result is always contiguous.
+func (obj *DenseTensor) Clone() *DenseTensor {
+  r := NewDenseTensor(NullDenseVector(obj.ElementType(), obj.Size()), obj.shape...)
+  r.Set(obj)
+  return r
+}
+
+func (obj *DenseTensor) CloneConstTensor() ConstTensor {
+  return obj.Clone()
+}
+
+func (obj *DenseTensor) CloneTensor() Tensor {
+  return obj.Clone()
+}
+
+func (obj *DenseMagicTensor) Clone() *DenseMagicTensor {
+  return &DenseMagicTensor{*obj.DenseTensor.Clone()}
+}
+
+func (obj *DenseMagicTensor) CloneMagicTensor() MagicTensor {
+  return obj.Clone()
+}
+
+/* indexing
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseTensor) index(idx []int) int {
+  if len(idx) != len(obj.shape) {
+    panic(fmt.Errorf("invalid number of indices for tensor of rank %d", len(obj.shape)))
+  }
+  k := obj.offset
+  for i := 0; i < len(idx); i++ {
+    if idx[i] < 0 || idx[i] >= obj.shape[i] {
+      panic(fmt.Errorf("index %v out of bounds for tensor of shape %v", idx, obj.shape))
+    }
+    k += idx[i]*obj.strides[i]
+  }
+  return k
+}
+
+// Returns true if elements are stored in row-major order without gaps.
+func (obj *DenseTensor) isContiguous() bool {
+  s := 1
+  for k := len(obj.shape)-1; k >= 0; k-- {
+    if obj.shape[k] != 1 && obj.strides[k] != s {
+      return false
+    }
+    s *= obj.shape[k]
+  }
+  return true
+}
+
+func (obj *DenseTensor) view(shape, strides []int, offset int) *DenseTensor {
+  return &DenseTensor{values: obj.values, shape: shape, strides: strides, offset: offset}
+}
+
+/* native tensor methods
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseTensor) SLICE(from, to []int) *DenseTensor {
+  if len(from) != len(obj.shape) || len(to) != len(obj.shape) {
+    panic("Slice(): invalid number of indices")
+  }
+  shape  := make([]int, len(obj.shape))
+  offset := obj.offset
+  for k := 0; k < len(obj.shape); k++ {
+    if from[k] < 0 || from[k] > to[k] || to[k] > obj.shape[k] {
+      panic(fmt.Errorf("Slice(): invalid range [%d,%d) for axis %d of length %d", from[k], to[k], k, obj.shape[k]))
+    }
+    shape[k] = to[k] - from[k]
+    offset  += from[k]*obj.strides[k]
+  }
+  return obj.view(shape, append([]int{}, obj.strides...), offset)
+}
+
+// Select the ith element along the given axis. The result has rank one less
+// than the original tensor.
+func (obj *DenseTensor) INDEX(axis, i int) *DenseTensor {
+  if axis < 0 || axis >= len(obj.shape) {
+    panic(fmt.Errorf("Index(): invalid axis %d for tensor of rank %d", axis, len(obj.shape)))
+  }
+  if i < 0 || i >= obj.shape[axis] {
+    panic(fmt.Errorf("Index(): index %d out of bounds for axis of length %d", i, obj.shape[axis]))
+  }
+  shape   := make([]int, 0, len(obj.shape)-1)
+  strides := make([]int, 0, len(obj.shape)-1)
+  shape    = append(append(shape,   obj.shape  [:axis]...), obj.shape  [axis+1:]...)
+  strides  = append(append(strides, obj.strides[:axis]...), obj.strides[axis+1:]...)
+  return obj.view(shape, strides, obj.offset + i*obj.strides[axis])
+}
+
+// Change the shape of the tensor. One dimension may be -1, in which case
+// it is inferred from the size of the tensor. The result shares memory with
+// the original tensor if it is contiguous, otherwise elements are copied.
+func (obj *DenseTensor) RESHAPE(shape ...int) (*DenseTensor, error) {
+  shape = append([]int{}, shape...)
+  n, j := 1, -1
+  for k := 0; k < len(shape); k++ {
+    switch {
+    case shape[k] == -1 && j == -1:
+      j = k
+    case shape[k] < 0:
+      return nil, fmt.Errorf("Reshape(): invalid shape %v", shape)
+    default:
+      n *= shape[k]
+    }
+  }
+  if j != -1 {
+    if n == 0 || obj.Size() % n != 0 {
+      return nil, fmt.Errorf("Reshape(): cannot reshape tensor of size %d into shape %v", obj.Size(), shape)
+    }
+    shape[j] = obj.Size() / n
+    n       *= shape[j]
+  }
+  if n != obj.Size() {
+    return nil, fmt.Errorf("Reshape(): cannot reshape tensor of size %d into shape %v", obj.Size(), shape)
+  }
+  if obj.isContiguous() {
+    return obj.view(shape, tensorStrides(shape), obj.offset), nil
+  } else {
+    r := obj.Clone()
+    return r.view(shape, tensorStrides(shape), 0), nil
+  }
+}
+
+// Permute the axes of the tensor. Axis k of the result is axis perm[k] of
+// the original tensor. Without arguments, the order of all axes is
+// reversed.
+func (obj *DenseTensor) TRANSPOSE(perm ...int) (*DenseTensor, error) {
+  n := len(obj.shape)
+  if len(perm) == 0 {
+    perm = make([]int, n)
+    for k := 0; k < n; k++ {
+      perm[k] = n-k-1
+    }
+  }
+  if len(perm) != n {
+    return nil, fmt.Errorf("Transpose(): permutation has invalid length")
+  }
+  shape   := make([]int,  n)
+  strides := make([]int,  n)
+  visited := make([]bool, n)
+  for k := 0; k < n; k++ {
+    if perm[k] < 0 || perm[k] >= n || visited[perm[k]] {
+      return nil, fmt.Errorf("Transpose(): invalid permutation")
+    }
+    visited[perm[k]] = true
+    shape  [k] = obj.shape  [perm[k]]
+    strides[k] = obj.strides[perm[k]]
+  }
+  return obj.view(shape, strides, obj.offset), nil
+}
+
+/* const interface
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseTensor) Rank() int {
+  return len(obj.shape)
+}
+
+func (obj *DenseTensor) Shape() []int {
+  return append([]int{}, obj.shape...)
+}
+
+func (obj *DenseTensor) Strides() []int {
+  return append([]int{}, obj.strides...)
+}
+
+// Number of elements in the tensor.
+func (obj *DenseTensor) Size() int {
+  return tensorSize(obj.shape)
+}
+
+func (obj *DenseTensor) Float64At(idx ...int) float64 {
+  return obj.values.Float64At(obj.index(idx))
+}
+
+func (obj *DenseTensor) ConstAt(idx ...int) ConstScalar {
+  return obj.values.ConstAt(obj.index(idx))
+}
+
+func (obj *DenseTensor) ConstSlice(from, to []int) ConstTensor {
+  return obj.SLICE(from, to)
+}
+
+func (obj *DenseTensor) ConstIndex(axis, i int) ConstTensor {
+  return obj.INDEX(axis, i)
+}
+
+func (obj *DenseTensor) ConstReshape(shape ...int) (ConstTensor, error) {
+  if r, err := obj.RESHAPE(shape...); err != nil {
+    return nil, err
+  } else {
+    return r, nil
+  }
+}
+
+func (obj *DenseTensor) ConstTranspose(perm ...int) (ConstTensor, error) {
+  if r, err := obj.TRANSPOSE(perm...); err != nil {
+    return nil, err
+  } else {
+    return r, nil
+  }
+}
+
+func (obj *DenseTensor) AsConstVector() ConstVector {
+  return obj.AsVector()
+}
+
+func (obj *DenseTensor) AsConstMatrix() ConstMatrix {
+  return obj.AsMatrix()
+}
+
+/* tensor interface
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseTensor) At(idx ...int) Scalar {
+  return obj.values.At(obj.index(idx))
+}
+
+func (obj *DenseTensor) Reset() {
+  tensorForEach(obj.shape, func(idx []int) {
+    obj.values.At(obj.index(idx)).Reset()
+  })
+}
+
+// Copy scalars from b into this tensor. The shapes of both tensors must
+// match.
+func (obj *DenseTensor) Set(b ConstTensor) {
+  if !tensorShapeEquals(obj.shape, b.Shape()) {
+    panic("Set(): Tensor dimensions do not match!")
+  }
+  tensorForEach(obj.shape, func(idx []int) {
+    obj.At(idx...).Set(b.ConstAt(idx...))
+  })
+}
+
+func (obj *DenseTensor) Slice(from, to []int) Tensor {
+  return obj.SLICE(from, to)
+}
+
+func (obj *DenseTensor) Index(axis, i int) Tensor {
+  return obj.INDEX(axis, i)
+}
+
+func (obj *DenseTensor) Reshape(shape ...int) (Tensor, error) {
+  if r, err := obj.RESHAPE(shape...); err != nil {
+    return nil, err
+  } else {
+    return r, nil
+  }
+}
+
+func (obj *DenseTensor) Transpose(perm ...int) (Tensor, error) {
+  if r, err := obj.TRANSPOSE(perm...); err != nil {
+    return nil, err
+  } else {
+    return r, nil
+  }
+}
+
+// Returns all elements of the tensor in row-major order. The result shares
+// memory with the tensor if it is contiguous.
+func (obj *DenseTensor) AsVector() Vector {
+  if obj.isContiguous() {
+    return obj.values.Slice(obj.offset, obj.offset+obj.Size())
+  }
+  return obj.Clone().values
+}
+
+// Convert a rank-2 tensor to a matrix. The result shares memory with the
+// tensor if it is contiguous.
+func (obj *DenseTensor) AsMatrix() Matrix {
+  if len(obj.shape) != 2 {
+    panic("AsMatrix(): tensor is not of rank 2")
+  }
+  return obj.AsVector().AsMatrix(obj.shape[0], obj.shape[1])
+}
+
+/* magic interface
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseMagicTensor) MagicAt(idx ...int) MagicScalar {
+  return obj.values.(MagicVector).MagicAt(obj.index(idx))
+}
+
+func (obj *DenseMagicTensor) MagicSlice(from, to []int) MagicTensor {
+  return &DenseMagicTensor{*obj.SLICE(from, to)}
+}
+
+func (obj *DenseMagicTensor) MagicIndex(axis, i int) MagicTensor {
+  return &DenseMagicTensor{*obj.INDEX(axis, i)}
+}
+
+func (obj *DenseMagicTensor) MagicReshape(shape ...int) (MagicTensor, error) {
+  if r, err := obj.RESHAPE(shape...); err != nil {
+    return nil, err
+  } else {
+    return &DenseMagicTensor{*r}, nil
+  }
+}
+
+func (obj *DenseMagicTensor) MagicTranspose(perm ...int) (MagicTensor, error) {
+  if r, err := obj.TRANSPOSE(perm...); err != nil {
+    return nil, err
+  } else {
+    return &DenseMagicTensor{*r}, nil
+  }
+}
+
+func (obj *DenseMagicTensor) ResetDerivatives() {
+  tensorForEach(obj.shape, func(idx []int) {
+    obj.MagicAt(idx...).ResetDerivatives()
+  })
+}
+
+func (obj *DenseMagicTensor) AsMagicVector() MagicVector {
+  return obj.AsVector().(MagicVector)
+}
+
+func (obj *DenseMagicTensor) AsMagicMatrix() MagicMatrix {
+  return obj.AsMatrix().(MagicMatrix)
+}
+
+// Declare all elements of the tensor (in row-major order) as variables.
+func (obj *DenseMagicTensor) Variables(order int) error {
+  n := obj.Size()
+  i := 0
+  var err error
+  tensorForEach(obj.shape, func(idx []int) {
+    if err == nil {
+      err = obj.MagicAt(idx...).SetVariable(i, n, order)
+    }
+    i++
+  })
+  return err
+}
+
+/* imlement ScalarContainer
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseTensor) Map(f func(Scalar)) {
+  tensorForEach(obj.shape, func(idx []int) {
+    f(obj.At(idx...))
+  })
+}
+
+func (obj *DenseTensor) MapSet(f func(ConstScalar) Scalar) {
+  tensorForEach(obj.shape, func(idx []int) {
+    s := obj.At(idx...)
+    s.Set(f(s))
+  })
+}
+
+func (obj *DenseTensor) Reduce(f func(Scalar, ConstScalar) Scalar, r Scalar) Scalar {
+  tensorForEach(obj.shape, func(idx []int) {
+    r = f(r, obj.ConstAt(idx...))
+  })
+  return r
+}
+
+func (obj *DenseTensor) ElementType() ScalarType {
+  return obj.values.ElementType()
+}
+
+/* type conversion
+ * -------------------------------------------------------------------------- */
+
+func (obj *DenseTensor) String() string {
+  var buffer bytes.Buffer
+  idx := make([]int, len(obj.shape))
+  var rec func(k int)
+  rec = func(k int) {
+    if k == len(obj.shape) {
+      buffer.WriteString(obj.ConstAt(idx...).String())
+      return
+    }
+    buffer.WriteString("[")
+    for i := 0; i < obj.shape[k]; i++ {
+      if i != 0 {
+        if k == len(obj.shape)-1 {
+          buffer.WriteString(", ")
+        } else {
+          buffer.WriteString(",\n")
+          for j := 0; j <= k; j++ {
+            buffer.WriteString(" ")
+          }
+        }
+      }
+      idx[k] = i
+      rec(k+1)
+    }
+    buffer.WriteString("]")
+  }
+  rec(0)
+  return buffer.String()
+}
+
+/* math
+ * -------------------------------------------------------------------------- */
+
+func (a *DenseTensor) Equals(b ConstTensor, epsilon float64) bool {
+  if !tensorShapeEquals(a.shape, b.Shape()) {
+    panic("Equals(): Tensor dimensions do not match!")
+  }
+  r := true
+  tensorForEach(a.shape, func(idx []int) {
+    if r && !a.ConstAt(idx...).Equals(b.ConstAt(idx...), epsilon) {
+      r = false
+    }
+  })
+  return r
+}
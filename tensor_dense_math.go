@@ -0,0 +1,167 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "fmt"
+
+/* -------------------------------------------------------------------------- */
+
+// Apply f element-wise to a and b, which are broadcast to the shape of r.
+func (r *DenseTensor) broadcast2(name string, a, b ConstTensor, f func(Scalar, ConstScalar, ConstScalar)) {
+  shape, err := BroadcastShape(a.Shape(), b.Shape())
+  if err != nil || !tensorShapeEquals(shape, r.shape) {
+    panic(fmt.Sprintf("%s(): Tensor dimensions do not match!", name))
+  }
+  sa := a.Shape()
+  sb := b.Shape()
+  ia := make([]int, len(sa))
+  ib := make([]int, len(sb))
+  tensorForEach(r.shape, func(idx []int) {
+    broadcastIndex(ia, idx, sa)
+    broadcastIndex(ib, idx, sb)
+    f(r.At(idx...), a.ConstAt(ia...), b.ConstAt(ib...))
+  })
+}
+
+func (r *DenseTensor) apply1(name string, a ConstTensor, b ConstScalar, f func(Scalar, ConstScalar, ConstScalar)) {
+  if !tensorShapeEquals(a.Shape(), r.shape) {
+    panic(fmt.Sprintf("%s(): Tensor dimensions do not match!", name))
+  }
+  tensorForEach(r.shape, func(idx []int) {
+    f(r.At(idx...), a.ConstAt(idx...), b)
+  })
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Element-wise addition of two tensors. Shapes of a and b are broadcast to
+// the shape of r.
+func (r *DenseTensor) TaddT(a, b ConstTensor) Tensor {
+  r.broadcast2("TaddT", a, b, func(s Scalar, x, y ConstScalar) { s.Add(x, y) })
+  return r
+}
+
+// Element-wise substraction of two tensors. Shapes of a and b are broadcast
+// to the shape of r.
+func (r *DenseTensor) TsubT(a, b ConstTensor) Tensor {
+  r.broadcast2("TsubT", a, b, func(s Scalar, x, y ConstScalar) { s.Sub(x, y) })
+  return r
+}
+
+// Element-wise multiplication of two tensors. Shapes of a and b are
+// broadcast to the shape of r.
+func (r *DenseTensor) TmulT(a, b ConstTensor) Tensor {
+  r.broadcast2("TmulT", a, b, func(s Scalar, x, y ConstScalar) { s.Mul(x, y) })
+  return r
+}
+
+// Element-wise division of two tensors. Shapes of a and b are broadcast to
+// the shape of r.
+func (r *DenseTensor) TdivT(a, b ConstTensor) Tensor {
+  r.broadcast2("TdivT", a, b, func(s Scalar, x, y ConstScalar) { s.Div(x, y) })
+  return r
+}
+
+/* -------------------------------------------------------------------------- */
+
+func (r *DenseTensor) TaddS(a ConstTensor, b ConstScalar) Tensor {
+  r.apply1("TaddS", a, b, func(s Scalar, x, y ConstScalar) { s.Add(x, y) })
+  return r
+}
+
+func (r *DenseTensor) TsubS(a ConstTensor, b ConstScalar) Tensor {
+  r.apply1("TsubS", a, b, func(s Scalar, x, y ConstScalar) { s.Sub(x, y) })
+  return r
+}
+
+func (r *DenseTensor) TmulS(a ConstTensor, b ConstScalar) Tensor {
+  r.apply1("TmulS", a, b, func(s Scalar, x, y ConstScalar) { s.Mul(x, y) })
+  return r
+}
+
+func (r *DenseTensor) TdivS(a ConstTensor, b ConstScalar) Tensor {
+  r.apply1("TdivS", a, b, func(s Scalar, x, y ConstScalar) { s.Div(x, y) })
+  return r
+}
+
+/* reductions
+ * -------------------------------------------------------------------------- */
+
+// Reduce a along the given axis. The shape of r must equal the shape of a
+// without the reduced axis. The function init is called with the first
+// element along the axis, f with all remaining elements.
+func (r *DenseTensor) reduceAxis(name string, a ConstTensor, axis int, init func(Scalar, ConstScalar), f func(Scalar, ConstScalar)) {
+  sa := a.Shape()
+  if axis < 0 || axis >= len(sa) {
+    panic(fmt.Sprintf("%s(): invalid axis %d for tensor of rank %d", name, axis, len(sa)))
+  }
+  if sa[axis] == 0 {
+    panic(fmt.Sprintf("%s(): cannot reduce axis of length zero", name))
+  }
+  shape := append(append([]int{}, sa[:axis]...), sa[axis+1:]...)
+  if !tensorShapeEquals(shape, r.shape) {
+    panic(fmt.Sprintf("%s(): Tensor dimensions do not match!", name))
+  }
+  ia := make([]int, len(sa))
+  tensorForEach(r.shape, func(idx []int) {
+    copy(ia[:axis], idx[:axis])
+    copy(ia[axis+1:], idx[axis:])
+    s := r.At(idx...)
+    for k := 0; k < sa[axis]; k++ {
+      ia[axis] = k
+      if k == 0 {
+        init(s, a.ConstAt(ia...))
+      } else {
+        f(s, a.ConstAt(ia...))
+      }
+    }
+  })
+}
+
+// Sum over the given axis of a.
+func (r *DenseTensor) TsumAxis(a ConstTensor, axis int) Tensor {
+  r.reduceAxis("TsumAxis", a, axis,
+    func(s Scalar, x ConstScalar) { s.Set(x) },
+    func(s Scalar, x ConstScalar) { s.Add(s, x) })
+  return r
+}
+
+// Mean over the given axis of a.
+func (r *DenseTensor) TmeanAxis(a ConstTensor, axis int) Tensor {
+  r.TsumAxis(a, axis)
+  n := ConstFloat64(a.Shape()[axis])
+  r.Map(func(s Scalar) { s.Div(s, n) })
+  return r
+}
+
+// Maximum over the given axis of a.
+func (r *DenseTensor) TmaxAxis(a ConstTensor, axis int) Tensor {
+  r.reduceAxis("TmaxAxis", a, axis,
+    func(s Scalar, x ConstScalar) { s.Set(x) },
+    func(s Scalar, x ConstScalar) { s.Max(s, x) })
+  return r
+}
+
+// Minimum over the given axis of a.
+func (r *DenseTensor) TminAxis(a ConstTensor, axis int) Tensor {
+  r.reduceAxis("TminAxis", a, axis,
+    func(s Scalar, x ConstScalar) { s.Set(x) },
+    func(s Scalar, x ConstScalar) { s.Min(s, x) })
+  return r
+}
@@ -0,0 +1,135 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+//import "fmt"
+import "testing"
+
+/* -------------------------------------------------------------------------- */
+
+func TestTensor(t *testing.T) {
+  v := NewDenseFloat64Vector([]float64{0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23})
+  a := NewDenseTensor(v, 2, 3, 4)
+
+  if a.Float64At(1, 2, 3) != 23 || a.Float64At(1, 0, 2) != 14 {
+    t.Error("test failed")
+  }
+  // views share memory with the original tensor
+  b := a.Index(0, 1)
+  b.At(2, 3).SetFloat64(-1)
+  if v[23] != -1 {
+    t.Error("test failed")
+  }
+  c := a.Slice([]int{0,1,1}, []int{2,3,3})
+  if s := c.Shape(); len(s) != 3 || s[0] != 2 || s[1] != 2 || s[2] != 2 {
+    t.Error("test failed")
+  }
+  if c.Float64At(1, 0, 1) != 18 {
+    t.Error("test failed")
+  }
+}
+
+func TestTensorTranspose(t *testing.T) {
+  a := NewDenseTensor(NewDenseFloat64Vector([]float64{0,1,2,3,4,5}), 2, 3)
+
+  b, err := a.Transpose()
+  if err != nil {
+    t.Error(err); return
+  }
+  if b.Float64At(2, 1) != 5 || b.Float64At(1, 0) != 1 {
+    t.Error("test failed")
+  }
+  // transposed tensor is not contiguous, reshape must copy
+  c, err := b.Reshape(-1)
+  if err != nil {
+    t.Error(err); return
+  }
+  r := NewDenseFloat64Vector([]float64{0,3,1,4,2,5})
+  if !c.AsVector().Equals(r, 1e-12) {
+    t.Error("test failed")
+  }
+  if _, err := a.Transpose(0, 0); err == nil {
+    t.Error("test failed")
+  }
+  if _, err := a.Reshape(4, 2); err == nil {
+    t.Error("test failed")
+  }
+}
+
+func TestTensorBroadcast(t *testing.T) {
+  a := NewDenseTensor(NewDenseFloat64Vector([]float64{1,2,3,4,5,6}), 2, 3)
+  b := NewDenseTensor(NewDenseFloat64Vector([]float64{10,20,30}), 3)
+  c := NewDenseTensor(NewDenseFloat64Vector([]float64{100,200}), 2, 1)
+  r := NullDenseTensor(Float64Type, 2, 3)
+
+  r.TaddT(a, b)
+  if !r.AsVector().Equals(NewDenseFloat64Vector([]float64{11,22,33,14,25,36}), 1e-12) {
+    t.Error("test failed")
+  }
+  r.TmulT(a, c)
+  if !r.AsVector().Equals(NewDenseFloat64Vector([]float64{100,200,300,800,1000,1200}), 1e-12) {
+    t.Error("test failed")
+  }
+  if s, err := BroadcastShape([]int{4,1,3}, []int{2,1}); err != nil || !tensorShapeEquals(s, []int{4,2,3}) {
+    t.Error("test failed")
+  }
+  if _, err := BroadcastShape([]int{2,3}, []int{2}); err == nil {
+    t.Error("test failed")
+  }
+}
+
+func TestTensorReduce(t *testing.T) {
+  a := NewDenseTensor(NewDenseFloat64Vector([]float64{1,2,3,4,5,6}), 2, 3)
+
+  r1 := NullDenseTensor(Float64Type, 3)
+  r1.TsumAxis(a, 0)
+  if !r1.AsVector().Equals(NewDenseFloat64Vector([]float64{5,7,9}), 1e-12) {
+    t.Error("test failed")
+  }
+  r2 := NullDenseTensor(Float64Type, 2)
+  r2.TmeanAxis(a, 1)
+  if !r2.AsVector().Equals(NewDenseFloat64Vector([]float64{2,5}), 1e-12) {
+    t.Error("test failed")
+  }
+  r2.TmaxAxis(a, 1)
+  if !r2.AsVector().Equals(NewDenseFloat64Vector([]float64{3,6}), 1e-12) {
+    t.Error("test failed")
+  }
+}
+
+func TestTensorMagic(t *testing.T) {
+  a := NewDenseMagicTensor(NewDenseReal64Vector([]float64{1,2,3,4,5,6}), 2, 3)
+  a.Variables(1)
+
+  r := NullDenseMagicTensor(Real64Type, 3)
+  r.TsumAxis(a, 0)
+  // d/dx_1 of x_1 + x_4
+  if r.MagicAt(1).GetDerivative(1) != 1.0 || r.MagicAt(1).GetDerivative(4) != 1.0 || r.MagicAt(1).GetDerivative(0) != 0.0 {
+    t.Error("test failed")
+  }
+  m := NewDenseReal64Matrix([]float64{1,2,3,4}, 2, 2)
+  b := DenseMagicTensorFromMatrix(m)
+  if !b.AsMagicMatrix().Equals(m, 1e-12) {
+    t.Error("test failed")
+  }
+  c, _ := b.MagicTranspose()
+  if !c.AsMatrix().Equals(m.T(), 1e-12) {
+    t.Error("test failed")
+  }
+}
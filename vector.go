@@ -18,6 +18,7 @@ package autodiff
 
 /* -------------------------------------------------------------------------- */
 
+import "encoding"
 import "encoding/json"
 
 /* -------------------------------------------------------------------------- */
@@ -90,6 +91,8 @@ type constVector interface {
   AsConstMatrix     (n, m int)             ConstMatrix
   // json
   json.Marshaler
+  // binary encoding
+  encoding.BinaryMarshaler
 }
 
 type ConstVector interface {
@@ -130,6 +133,15 @@ type vector interface {
   VdivS(a ConstVector, b ConstScalar)      Vector
   MdotV(a ConstMatrix, b ConstVector)      Vector
   VdotM(a ConstVector, b ConstMatrix)      Vector
+  // reductions over matrix rows and columns
+  MsumRows      (a ConstMatrix)            Vector
+  MsumCols      (a ConstMatrix)            Vector
+  MmeanRows     (a ConstMatrix)            Vector
+  MmeanCols     (a ConstMatrix)            Vector
+  MlogSumExpRows(a ConstMatrix)            Vector
+  MlogSumExpCols(a ConstMatrix)            Vector
+  MargmaxRows   (a ConstMatrix)            Vector
+  MargmaxCols   (a ConstMatrix)            Vector
 }
 
 type Vector interface {
@@ -315,6 +315,29 @@ func (v *DenseFloat64Vector) UnmarshalJSON(data []byte) error {
   *v = r
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (v DenseFloat64Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(v)
+}
+func (v *DenseFloat64Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseVector, Float64Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  r := NullDenseFloat64Vector(n)
+  for i := 0; i < n; i++ {
+    if err := d.readScalar(r.AT(i)); err != nil {
+      return err
+    }
+  }
+  *v = r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (v DenseFloat64Vector) ConstIterator() VectorConstIterator {
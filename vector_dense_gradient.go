@@ -159,6 +159,13 @@ func (obj DenseGradient) MarshalJSON() ([]byte, error) {
   return json.MarshalIndent(r, "", "  ")
 }
 
+/* binary
+ * -------------------------------------------------------------------------- */
+
+func (obj DenseGradient) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(obj)
+}
+
 /* iterator methods
  * -------------------------------------------------------------------------- */
 
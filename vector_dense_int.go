@@ -315,6 +315,29 @@ func (v *DenseIntVector) UnmarshalJSON(data []byte) error {
   *v = r
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (v DenseIntVector) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(v)
+}
+func (v *DenseIntVector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseVector, IntType)
+  if err != nil {
+    return err
+  }
+  n, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  r := NullDenseIntVector(n)
+  for i := 0; i < n; i++ {
+    if err := d.readScalar(r.AT(i)); err != nil {
+      return err
+    }
+  }
+  *v = r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (v DenseIntVector) ConstIterator() VectorConstIterator {
@@ -315,6 +315,29 @@ func (v *DenseInt16Vector) UnmarshalJSON(data []byte) error {
   *v = r
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (v DenseInt16Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(v)
+}
+func (v *DenseInt16Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseVector, Int16Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  r := NullDenseInt16Vector(n)
+  for i := 0; i < n; i++ {
+    if err := d.readScalar(r.AT(i)); err != nil {
+      return err
+    }
+  }
+  *v = r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (v DenseInt16Vector) ConstIterator() VectorConstIterator {
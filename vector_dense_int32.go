@@ -315,6 +315,29 @@ func (v *DenseInt32Vector) UnmarshalJSON(data []byte) error {
   *v = r
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (v DenseInt32Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(v)
+}
+func (v *DenseInt32Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseVector, Int32Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  r := NullDenseInt32Vector(n)
+  for i := 0; i < n; i++ {
+    if err := d.readScalar(r.AT(i)); err != nil {
+      return err
+    }
+  }
+  *v = r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (v DenseInt32Vector) ConstIterator() VectorConstIterator {
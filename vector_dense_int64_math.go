@@ -143,7 +143,8 @@ func (r DenseInt64Vector) MdotV(a ConstMatrix, b ConstVector) Vector {
   t := 0.0
   for i := 0; i < n; i++ {
     r.AT(i).Reset()
-    for j := 0; j < m; j++ {
+    from, to := constMatrixRowRange(a, i)
+    for j := from; j < to; j++ {
       t = a.Float64At(i, j)*b.Float64At(j)
       r.AT(i).Add(r.AT(i), ConstFloat64(t))
     }
@@ -166,10 +167,143 @@ func (r DenseInt64Vector) VdotM(a ConstVector, b ConstMatrix) Vector {
   t := 0.0
   for i := 0; i < m; i++ {
     r.AT(i).Reset()
-    for j := 0; j < n; j++ {
+    from, to := constMatrixColRange(b, i)
+    for j := from; j < to; j++ {
       t = a.Float64At(j)*b.Float64At(j, i)
       r.AT(i).Add(r.AT(i), ConstFloat64(t))
     }
   }
   return r
 }
+/* reductions over matrix rows and columns
+ * -------------------------------------------------------------------------- */
+// Sum over all columns of a, i.e. r_i = sum_j a_ij. The result is stored in r.
+func (r DenseInt64Vector) MsumRows(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    s := r.AT(i)
+    s.Reset()
+    for j := 0; j < m; j++ {
+      s.Add(s, a.ConstAt(i, j))
+    }
+  }
+  return r
+}
+// Sum over all rows of a, i.e. r_j = sum_i a_ij. The result is stored in r.
+func (r DenseInt64Vector) MsumCols(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for j := 0; j < m; j++ {
+    s := r.AT(j)
+    s.Reset()
+    for i := 0; i < n; i++ {
+      s.Add(s, a.ConstAt(i, j))
+    }
+  }
+  return r
+}
+// Mean of each row of a. The result is stored in r.
+func (r DenseInt64Vector) MmeanRows(a ConstMatrix) Vector {
+  _, m := a.Dims()
+  r.MsumRows(a)
+  for i := 0; i < r.Dim(); i++ {
+    r.AT(i).Div(r.AT(i), ConstFloat64(m))
+  }
+  return r
+}
+// Mean of each column of a. The result is stored in r.
+func (r DenseInt64Vector) MmeanCols(a ConstMatrix) Vector {
+  n, _ := a.Dims()
+  r.MsumCols(a)
+  for j := 0; j < r.Dim(); j++ {
+    r.AT(j).Div(r.AT(j), ConstFloat64(n))
+  }
+  return r
+}
+// Compute r_i = log sum_j exp(a_ij) for all rows of a. The result is stored
+// in r.
+func (r DenseInt64Vector) MlogSumExpRows(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if m == 0 {
+    panic("cannot reduce matrix with zero columns")
+  }
+  t := NullInt64()
+  for i := 0; i < n; i++ {
+    s := r.AT(i)
+    s.Set(a.ConstAt(i, 0))
+    for j := 1; j < m; j++ {
+      s.LogAdd(s, a.ConstAt(i, j), t)
+    }
+  }
+  return r
+}
+// Compute r_j = log sum_i exp(a_ij) for all columns of a. The result is
+// stored in r.
+func (r DenseInt64Vector) MlogSumExpCols(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if n == 0 {
+    panic("cannot reduce matrix with zero rows")
+  }
+  t := NullInt64()
+  for j := 0; j < m; j++ {
+    s := r.AT(j)
+    s.Set(a.ConstAt(0, j))
+    for i := 1; i < n; i++ {
+      s.LogAdd(s, a.ConstAt(i, j), t)
+    }
+  }
+  return r
+}
+// Store in r_i the column index of the largest element in row i of a. Ties
+// are resolved in favor of the smallest index.
+func (r DenseInt64Vector) MargmaxRows(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if m == 0 {
+    panic("cannot reduce matrix with zero columns")
+  }
+  for i := 0; i < n; i++ {
+    k := 0
+    for j := 1; j < m; j++ {
+      if a.Float64At(i, j) > a.Float64At(i, k) {
+        k = j
+      }
+    }
+    r.AT(i).SetInt(k)
+  }
+  return r
+}
+// Store in r_j the row index of the largest element in column j of a. Ties
+// are resolved in favor of the smallest index.
+func (r DenseInt64Vector) MargmaxCols(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if n == 0 {
+    panic("cannot reduce matrix with zero rows")
+  }
+  for j := 0; j < m; j++ {
+    k := 0
+    for i := 1; i < n; i++ {
+      if a.Float64At(i, j) > a.Float64At(k, j) {
+        k = i
+      }
+    }
+    r.AT(j).SetInt(k)
+  }
+  return r
+}
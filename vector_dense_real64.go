@@ -411,6 +411,29 @@ func (obj *DenseReal64Vector) UnmarshalJSON(data []byte) error {
   }
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj DenseReal64Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(obj)
+}
+func (obj *DenseReal64Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binaryDenseVector, Real64Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readLength()
+  if err != nil {
+    return err
+  }
+  r := NullDenseReal64Vector(n)
+  for i := 0; i < n; i++ {
+    if err := d.readScalar(r.AT(i)); err != nil {
+      return err
+    }
+  }
+  *obj = r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj DenseReal64Vector) ConstIterator() VectorConstIterator {
@@ -320,6 +320,36 @@ func (obj SparseConstFloat32Vector) MarshalJSON() ([]byte, error) {
   r.Length = obj.n
   return json.MarshalIndent(r, "", "  ")
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj SparseConstFloat32Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseConstFloat32Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, ConstFloat32Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  indices := []int{}
+  values := []float32{}
+  if err := d.readSparse(n, func(k int) error {
+    if s, err := d.readConstScalar(); err != nil {
+      return err
+    } else {
+      indices = append(indices, k)
+      values = append(values, s.GetFloat32())
+    }
+    return nil
+  }); err != nil {
+    return err
+  }
+  *obj = NewSparseConstFloat32Vector(indices, values, n)
+  return nil
+}
 /* iterator
  * -------------------------------------------------------------------------- */
 type SparseConstFloat32VectorIterator struct {
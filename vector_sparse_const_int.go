@@ -320,6 +320,36 @@ func (obj SparseConstIntVector) MarshalJSON() ([]byte, error) {
   r.Length = obj.n
   return json.MarshalIndent(r, "", "  ")
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj SparseConstIntVector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseConstIntVector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, ConstIntType)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  indices := []int{}
+  values := []int{}
+  if err := d.readSparse(n, func(k int) error {
+    if s, err := d.readConstScalar(); err != nil {
+      return err
+    } else {
+      indices = append(indices, k)
+      values = append(values, s.GetInt())
+    }
+    return nil
+  }); err != nil {
+    return err
+  }
+  *obj = NewSparseConstIntVector(indices, values, n)
+  return nil
+}
 /* iterator
  * -------------------------------------------------------------------------- */
 type SparseConstIntVectorIterator struct {
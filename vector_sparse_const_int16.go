@@ -320,6 +320,36 @@ func (obj SparseConstInt16Vector) MarshalJSON() ([]byte, error) {
   r.Length = obj.n
   return json.MarshalIndent(r, "", "  ")
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj SparseConstInt16Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseConstInt16Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, ConstInt16Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  indices := []int{}
+  values := []int16{}
+  if err := d.readSparse(n, func(k int) error {
+    if s, err := d.readConstScalar(); err != nil {
+      return err
+    } else {
+      indices = append(indices, k)
+      values = append(values, s.GetInt16())
+    }
+    return nil
+  }); err != nil {
+    return err
+  }
+  *obj = NewSparseConstInt16Vector(indices, values, n)
+  return nil
+}
 /* iterator
  * -------------------------------------------------------------------------- */
 type SparseConstInt16VectorIterator struct {
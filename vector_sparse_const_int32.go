@@ -320,6 +320,36 @@ func (obj SparseConstInt32Vector) MarshalJSON() ([]byte, error) {
   r.Length = obj.n
   return json.MarshalIndent(r, "", "  ")
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj SparseConstInt32Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseConstInt32Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, ConstInt32Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  indices := []int{}
+  values := []int32{}
+  if err := d.readSparse(n, func(k int) error {
+    if s, err := d.readConstScalar(); err != nil {
+      return err
+    } else {
+      indices = append(indices, k)
+      values = append(values, s.GetInt32())
+    }
+    return nil
+  }); err != nil {
+    return err
+  }
+  *obj = NewSparseConstInt32Vector(indices, values, n)
+  return nil
+}
 /* iterator
  * -------------------------------------------------------------------------- */
 type SparseConstInt32VectorIterator struct {
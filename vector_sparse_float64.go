@@ -603,6 +603,27 @@ func (obj *SparseFloat64Vector) UnmarshalJSON(data []byte) error {
   *obj = *NewSparseFloat64Vector(r.Index, r.Value, r.Length)
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseFloat64Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseFloat64Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, Float64Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  r := NullSparseFloat64Vector(n)
+  if err := d.readSparse(n, func(k int) error { return d.readScalar(r.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseFloat64Vector) ConstIterator() VectorConstIterator {
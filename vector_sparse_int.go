@@ -603,6 +603,27 @@ func (obj *SparseIntVector) UnmarshalJSON(data []byte) error {
   *obj = *NewSparseIntVector(r.Index, r.Value, r.Length)
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseIntVector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseIntVector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, IntType)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  r := NullSparseIntVector(n)
+  if err := d.readSparse(n, func(k int) error { return d.readScalar(r.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseIntVector) ConstIterator() VectorConstIterator {
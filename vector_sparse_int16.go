@@ -603,6 +603,27 @@ func (obj *SparseInt16Vector) UnmarshalJSON(data []byte) error {
   *obj = *NewSparseInt16Vector(r.Index, r.Value, r.Length)
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseInt16Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseInt16Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, Int16Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  r := NullSparseInt16Vector(n)
+  if err := d.readSparse(n, func(k int) error { return d.readScalar(r.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseInt16Vector) ConstIterator() VectorConstIterator {
@@ -603,6 +603,27 @@ func (obj *SparseInt32Vector) UnmarshalJSON(data []byte) error {
   *obj = *NewSparseInt32Vector(r.Index, r.Value, r.Length)
   return nil
 }
+/* binary
+ * -------------------------------------------------------------------------- */
+func (obj *SparseInt32Vector) MarshalBinary() ([]byte, error) {
+  return marshalBinarySparseVector(obj)
+}
+func (obj *SparseInt32Vector) UnmarshalBinary(data []byte) error {
+  d, err := newBinaryDecoder(data, binarySparseVector, Int32Type)
+  if err != nil {
+    return err
+  }
+  n, err := d.readInt()
+  if err != nil {
+    return err
+  }
+  r := NullSparseInt32Vector(n)
+  if err := d.readSparse(n, func(k int) error { return d.readScalar(r.AT(k)) }); err != nil {
+    return err
+  }
+  *obj = *r
+  return nil
+}
 /* iterator methods
  * -------------------------------------------------------------------------- */
 func (obj *SparseInt32Vector) ConstIterator() VectorConstIterator {
@@ -0,0 +1,716 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+import "fmt"
+import "bufio"
+import "bytes"
+import "os"
+
+/* -------------------------------------------------------------------------- */
+
+// StridedVector is a view of the elements (i0 + k*di, j0 + k*dj) for
+// k = 0, ..., n-1 of a matrix. Reading and writing elements of the vector
+// reads and writes the corresponding elements of the matrix, no values are
+// copied. Rows, columns and diagonals of a matrix are strided vectors.
+type StridedVector struct {
+  matrix Matrix
+  i0, j0 int
+  di, dj int
+  n      int
+}
+
+/* constructors
+ * -------------------------------------------------------------------------- */
+
+func NewStridedVector(matrix Matrix, i0, j0, di, dj, n int) StridedVector {
+  if n < 0 {
+    panic("negative vector dimension")
+  }
+  if n > 0 {
+    rows, cols := matrix.Dims()
+    i1 := i0 + (n-1)*di
+    j1 := j0 + (n-1)*dj
+    if i0 < 0 || j0 < 0 || i1 < 0 || j1 < 0 || i0 >= rows || i1 >= rows || j0 >= cols || j1 >= cols {
+      panic(fmt.Sprintf("strided vector exceeds matrix of dimension %dx%d", rows, cols))
+    }
+  }
+  return StridedVector{matrix, i0, j0, di, dj, n}
+}
+
+// Vector view of the ith row of a matrix.
+func RowView(matrix Matrix, i int) StridedVector {
+  _, m := matrix.Dims()
+  return NewStridedVector(matrix, i, 0, 0, 1, m)
+}
+
+// Vector view of the jth column of a matrix.
+func ColView(matrix Matrix, j int) StridedVector {
+  n, _ := matrix.Dims()
+  return NewStridedVector(matrix, 0, j, 1, 0, n)
+}
+
+// Vector view of the diagonal of a square matrix.
+func DiagView(matrix Matrix) StridedVector {
+  n, m := matrix.Dims()
+  if n != m {
+    panic("Diag(): not a square matrix!")
+  }
+  return NewStridedVector(matrix, 0, 0, 1, 1, n)
+}
+
+/* -------------------------------------------------------------------------- */
+
+// Returns a dense copy of the vector.
+func (v StridedVector) Clone() Vector {
+  r := NullDenseVector(v.ElementType(), v.n)
+  r.Set(v)
+  return r
+}
+
+func (v StridedVector) ij(k int) (int, int) {
+  if k < 0 || k >= v.n {
+    panic(fmt.Errorf("index %d out of bounds for vector of dimension %d", k, v.n))
+  }
+  return v.i0 + k*v.di, v.j0 + k*v.dj
+}
+
+/* vector interface
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) CloneVector() Vector {
+  return v.Clone()
+}
+
+func (v StridedVector) At(k int) Scalar {
+  return v.matrix.At(v.ij(k))
+}
+
+func (v StridedVector) Set(w ConstVector) {
+  if v.Dim() != w.Dim() {
+    panic("Set(): Vector dimensions do not match!")
+  }
+  for k := 0; k < v.n; k++ {
+    v.At(k).Set(w.ConstAt(k))
+  }
+}
+
+func (v StridedVector) Reset() {
+  for k := 0; k < v.n; k++ {
+    v.At(k).Reset()
+  }
+}
+
+func (v StridedVector) ReverseOrder() {
+  for k := 0; k < v.n/2; k++ {
+    v.Swap(k, v.n-1-k)
+  }
+}
+
+// Returns a strided vector of the elements i to j-1.
+func (v StridedVector) Slice(i, j int) Vector {
+  if i < 0 || j > v.n || i > j {
+    panic(fmt.Errorf("invalid slice [%d:%d] of vector with dimension %d", i, j, v.n))
+  }
+  return StridedVector{v.matrix, v.i0 + i*v.di, v.j0 + i*v.dj, v.di, v.dj, j-i}
+}
+
+func (v StridedVector) Swap(i, j int) {
+  s1 := v.At(i)
+  s2 := v.At(j)
+  t  := s1.CloneScalar()
+  s1.Set(s2)
+  s2.Set(t)
+}
+
+// Returns a dense copy of the vector with all scalars appended.
+func (v StridedVector) AppendScalar(scalars ...Scalar) Vector {
+  return v.Clone().AppendScalar(scalars...)
+}
+
+// Returns a dense copy of the vector with w appended.
+func (v StridedVector) AppendVector(w Vector) Vector {
+  return v.Clone().AppendVector(w)
+}
+
+// Returns a dense matrix with a copy of all elements of v.
+func (v StridedVector) AsMatrix(n, m int) Matrix {
+  return v.Clone().AsMatrix(n, m)
+}
+
+/* const interface
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) CloneConstVector() ConstVector {
+  return v.Clone()
+}
+
+func (v StridedVector) Dim() int {
+  return v.n
+}
+
+func (v StridedVector) Int8At(k int) int8 {
+  return v.matrix.Int8At(v.ij(k))
+}
+
+func (v StridedVector) Int16At(k int) int16 {
+  return v.matrix.Int16At(v.ij(k))
+}
+
+func (v StridedVector) Int32At(k int) int32 {
+  return v.matrix.Int32At(v.ij(k))
+}
+
+func (v StridedVector) Int64At(k int) int64 {
+  return v.matrix.Int64At(v.ij(k))
+}
+
+func (v StridedVector) IntAt(k int) int {
+  return v.matrix.IntAt(v.ij(k))
+}
+
+func (v StridedVector) Float32At(k int) float32 {
+  return v.matrix.Float32At(v.ij(k))
+}
+
+func (v StridedVector) Float64At(k int) float64 {
+  return v.matrix.Float64At(v.ij(k))
+}
+
+func (v StridedVector) ConstAt(k int) ConstScalar {
+  return v.matrix.ConstAt(v.ij(k))
+}
+
+func (v StridedVector) ConstSlice(i, j int) ConstVector {
+  return v.Slice(i, j)
+}
+
+func (v StridedVector) AsConstMatrix(n, m int) ConstMatrix {
+  return v.AsMatrix(n, m)
+}
+
+func (a StridedVector) Equals(b ConstVector, epsilon float64) bool {
+  if a.Dim() != b.Dim() {
+    panic("VEqual(): Vector dimensions do not match!")
+  }
+  for i := 0; i < a.Dim(); i++ {
+    if !a.ConstAt(i).Equals(b.ConstAt(i), epsilon) {
+      return false
+    }
+  }
+  return true
+}
+
+/* imlement ScalarContainer
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) Map(f func(Scalar)) {
+  for k := 0; k < v.n; k++ {
+    f(v.At(k))
+  }
+}
+
+func (v StridedVector) MapSet(f func(ConstScalar) Scalar) {
+  for k := 0; k < v.n; k++ {
+    v.At(k).Set(f(v.ConstAt(k)))
+  }
+}
+
+func (v StridedVector) Reduce(f func(Scalar, ConstScalar) Scalar, r Scalar) Scalar {
+  for k := 0; k < v.n; k++ {
+    r = f(r, v.ConstAt(k))
+  }
+  return r
+}
+
+func (v StridedVector) ElementType() ScalarType {
+  return v.matrix.ElementType()
+}
+
+/* permutations
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) Permute(pi []int) error {
+  if len(pi) != v.n {
+    return fmt.Errorf("Permute(): permutation vector has invalid length!")
+  }
+  // permute vector
+  for i := 0; i < v.n; i++ {
+    if pi[i] < 0 || pi[i] >= v.n {
+      return fmt.Errorf("Permute(): invalid permutation")
+    }
+    if i != pi[i] && pi[i] > i {
+      // permute elements
+      v.Swap(i, pi[i])
+    }
+  }
+  return nil
+}
+
+/* sorting
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) Sort(reverse bool) {
+  r := v.Clone()
+  r.Sort(reverse)
+  v.Set(r)
+}
+
+/* type conversion
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) String() string {
+  var buffer bytes.Buffer
+  buffer.WriteString("[")
+  for k := 0; k < v.n; k++ {
+    if k != 0 {
+      buffer.WriteString(", ")
+    }
+    buffer.WriteString(v.ConstAt(k).String())
+  }
+  buffer.WriteString("]")
+  return buffer.String()
+}
+
+func (v StridedVector) Table() string {
+  var buffer bytes.Buffer
+  for k := 0; k < v.n; k++ {
+    if k != 0 {
+      buffer.WriteString(" ")
+    }
+    buffer.WriteString(v.ConstAt(k).String())
+  }
+  return buffer.String()
+}
+
+func (v StridedVector) Export(filename string) error {
+  f, err := os.Create(filename)
+  if err != nil {
+    return err
+  }
+  defer f.Close()
+
+  w := bufio.NewWriter(f)
+  defer w.Flush()
+
+  if _, err := fmt.Fprintf(w, "%s\n", v.Table()); err != nil {
+    return err
+  }
+  return nil
+}
+
+/* json and binary encoding
+ * -------------------------------------------------------------------------- */
+
+// Strided vectors are encoded as dense vectors.
+func (v StridedVector) MarshalJSON() ([]byte, error) {
+  return v.Clone().MarshalJSON()
+}
+
+func (v StridedVector) MarshalBinary() ([]byte, error) {
+  return marshalBinaryDenseVector(v)
+}
+
+/* iterator methods
+ * -------------------------------------------------------------------------- */
+
+func (v StridedVector) ConstIterator() VectorConstIterator {
+  return &stridedVectorIterator{v, 0}
+}
+
+func (v StridedVector) ConstIteratorFrom(i int) VectorConstIterator {
+  return &stridedVectorIterator{v, i}
+}
+
+func (v StridedVector) Iterator() VectorIterator {
+  return &stridedVectorIterator{v, 0}
+}
+
+func (v StridedVector) IteratorFrom(i int) VectorIterator {
+  return &stridedVectorIterator{v, i}
+}
+
+func (v StridedVector) JointIterator(b ConstVector) VectorJointIterator {
+  return &stridedVectorJointIterator{v, b, 0}
+}
+
+func (v StridedVector) ConstJointIterator(b ConstVector) VectorConstJointIterator {
+  return &stridedVectorJointIterator{v, b, 0}
+}
+
+/* iterator
+ * -------------------------------------------------------------------------- */
+
+type stridedVectorIterator struct {
+  v StridedVector
+  i int
+}
+
+func (obj *stridedVectorIterator) GetConst() ConstScalar {
+  return obj.v.ConstAt(obj.i)
+}
+
+func (obj *stridedVectorIterator) Get() Scalar {
+  return obj.v.At(obj.i)
+}
+
+func (obj *stridedVectorIterator) Ok() bool {
+  return obj.i < obj.v.n
+}
+
+func (obj *stridedVectorIterator) Next() {
+  obj.i++
+}
+
+func (obj *stridedVectorIterator) Index() int {
+  return obj.i
+}
+
+func (obj *stridedVectorIterator) CloneIterator() VectorIterator {
+  return &stridedVectorIterator{obj.v, obj.i}
+}
+
+func (obj *stridedVectorIterator) CloneConstIterator() VectorConstIterator {
+  return &stridedVectorIterator{obj.v, obj.i}
+}
+
+/* joint iterator
+ * -------------------------------------------------------------------------- */
+
+// Strided vectors have no structural zeros, the joint iterator visits all
+// elements.
+type stridedVectorJointIterator struct {
+  v StridedVector
+  b ConstVector
+  i int
+}
+
+func (obj *stridedVectorJointIterator) Index() int {
+  return obj.i
+}
+
+func (obj *stridedVectorJointIterator) Ok() bool {
+  return obj.i < obj.v.n
+}
+
+func (obj *stridedVectorJointIterator) Next() {
+  obj.i++
+}
+
+func (obj *stridedVectorJointIterator) GetConst() (ConstScalar, ConstScalar) {
+  return obj.v.ConstAt(obj.i), obj.b.ConstAt(obj.i)
+}
+
+func (obj *stridedVectorJointIterator) Get() (Scalar, ConstScalar) {
+  return obj.v.At(obj.i), obj.b.ConstAt(obj.i)
+}
+
+func (obj *stridedVectorJointIterator) CloneJointIterator() VectorJointIterator {
+  return &stridedVectorJointIterator{obj.v, obj.b, obj.i}
+}
+
+func (obj *stridedVectorJointIterator) CloneConstJointIterator() VectorConstJointIterator {
+  return &stridedVectorJointIterator{obj.v, obj.b, obj.i}
+}
+
+/* math operations
+ * -------------------------------------------------------------------------- */
+
+// Element-wise addition of two vectors. The result is stored in r.
+func (r StridedVector) VaddV(a, b ConstVector) Vector {
+  n := r.Dim()
+  if a.Dim() != n || b.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Add(a.ConstAt(i), b.ConstAt(i))
+  }
+  return r
+}
+
+// Element-wise addition of a vector and a scalar. The result is stored in r.
+func (r StridedVector) VaddS(a ConstVector, b ConstScalar) Vector {
+  n := r.Dim()
+  if a.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Add(a.ConstAt(i), b)
+  }
+  return r
+}
+
+// Element-wise substraction of two vectors. The result is stored in r.
+func (r StridedVector) VsubV(a, b ConstVector) Vector {
+  n := r.Dim()
+  if a.Dim() != n || b.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Sub(a.ConstAt(i), b.ConstAt(i))
+  }
+  return r
+}
+
+// Element-wise substraction of a vector and a scalar. The result is stored in r.
+func (r StridedVector) VsubS(a ConstVector, b ConstScalar) Vector {
+  n := r.Dim()
+  if a.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Sub(a.ConstAt(i), b)
+  }
+  return r
+}
+
+// Element-wise multiplication of two vectors. The result is stored in r.
+func (r StridedVector) VmulV(a, b ConstVector) Vector {
+  n := r.Dim()
+  if a.Dim() != n || b.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Mul(a.ConstAt(i), b.ConstAt(i))
+  }
+  return r
+}
+
+// Element-wise multiplication of a vector and a scalar. The result is stored in r.
+func (r StridedVector) VmulS(a ConstVector, b ConstScalar) Vector {
+  n := r.Dim()
+  if a.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Mul(a.ConstAt(i), b)
+  }
+  return r
+}
+
+// Element-wise division of two vectors. The result is stored in r.
+func (r StridedVector) VdivV(a, b ConstVector) Vector {
+  n := r.Dim()
+  if a.Dim() != n || b.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Div(a.ConstAt(i), b.ConstAt(i))
+  }
+  return r
+}
+
+// Element-wise division of a vector and a scalar. The result is stored in r.
+func (r StridedVector) VdivS(a ConstVector, b ConstScalar) Vector {
+  n := r.Dim()
+  if a.Dim() != n {
+    panic("vector dimensions do not match")
+  }
+  for i := 0; i < n; i++ {
+    r.At(i).Div(a.ConstAt(i), b)
+  }
+  return r
+}
+
+// Matrix vector product of a and b. The result is stored in r.
+func (r StridedVector) MdotV(a ConstMatrix, b ConstVector) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n || b.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  t1 := NullScalar(r.ElementType())
+  t2 := NullScalar(r.ElementType())
+  // r may alias a or b, use a temporary vector for the result
+  t3 := NullDenseVector(r.ElementType(), n)
+  for i := 0; i < n; i++ {
+    t2.Reset()
+    from, to := constMatrixRowRange(a, i)
+    for j := from; j < to; j++ {
+      t1.Mul(a.ConstAt(i, j), b.ConstAt(j))
+      t2.Add(t2, t1)
+    }
+    t3.At(i).Set(t2)
+  }
+  r.Set(t3)
+  return r
+}
+
+// Vector matrix product of a and b. The result is stored in r.
+func (r StridedVector) VdotM(a ConstVector, b ConstMatrix) Vector {
+  n, m := b.Dims()
+  if r.Dim() != m || a.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  t1 := NullScalar(r.ElementType())
+  t2 := NullScalar(r.ElementType())
+  // r may alias a or b, use a temporary vector for the result
+  t3 := NullDenseVector(r.ElementType(), m)
+  for i := 0; i < m; i++ {
+    t2.Reset()
+    from, to := constMatrixColRange(b, i)
+    for j := from; j < to; j++ {
+      t1.Mul(a.ConstAt(j), b.ConstAt(j, i))
+      t2.Add(t2, t1)
+    }
+    t3.At(i).Set(t2)
+  }
+  r.Set(t3)
+  return r
+}
+
+/* reductions over matrix rows and columns
+ * -------------------------------------------------------------------------- */
+
+// Sum over all columns of a, i.e. r_i = sum_j a_ij. The result is stored in r.
+func (r StridedVector) MsumRows(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for i := 0; i < n; i++ {
+    s := r.At(i)
+    s.Reset()
+    for j := 0; j < m; j++ {
+      s.Add(s, a.ConstAt(i, j))
+    }
+  }
+  return r
+}
+
+// Sum over all rows of a, i.e. r_j = sum_i a_ij. The result is stored in r.
+func (r StridedVector) MsumCols(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  for j := 0; j < m; j++ {
+    s := r.At(j)
+    s.Reset()
+    for i := 0; i < n; i++ {
+      s.Add(s, a.ConstAt(i, j))
+    }
+  }
+  return r
+}
+
+// Mean of each row of a. The result is stored in r.
+func (r StridedVector) MmeanRows(a ConstMatrix) Vector {
+  _, m := a.Dims()
+  r.MsumRows(a)
+  for i := 0; i < r.Dim(); i++ {
+    r.At(i).Div(r.At(i), ConstFloat64(m))
+  }
+  return r
+}
+
+// Mean of each column of a. The result is stored in r.
+func (r StridedVector) MmeanCols(a ConstMatrix) Vector {
+  n, _ := a.Dims()
+  r.MsumCols(a)
+  for j := 0; j < r.Dim(); j++ {
+    r.At(j).Div(r.At(j), ConstFloat64(n))
+  }
+  return r
+}
+
+// Compute r_i = log sum_j exp(a_ij) for all rows of a. The result is stored
+// in r.
+func (r StridedVector) MlogSumExpRows(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if m == 0 {
+    panic("cannot reduce matrix with zero columns")
+  }
+  t := NullScalar(r.ElementType())
+  for i := 0; i < n; i++ {
+    s := r.At(i)
+    s.Set(a.ConstAt(i, 0))
+    for j := 1; j < m; j++ {
+      s.LogAdd(s, a.ConstAt(i, j), t)
+    }
+  }
+  return r
+}
+
+// Compute r_j = log sum_i exp(a_ij) for all columns of a. The result is
+// stored in r.
+func (r StridedVector) MlogSumExpCols(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if n == 0 {
+    panic("cannot reduce matrix with zero rows")
+  }
+  t := NullScalar(r.ElementType())
+  for j := 0; j < m; j++ {
+    s := r.At(j)
+    s.Set(a.ConstAt(0, j))
+    for i := 1; i < n; i++ {
+      s.LogAdd(s, a.ConstAt(i, j), t)
+    }
+  }
+  return r
+}
+
+// Store in r_i the column index of the largest element in row i of a. Ties
+// are resolved in favor of the smallest index.
+func (r StridedVector) MargmaxRows(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != n {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if m == 0 {
+    panic("cannot reduce matrix with zero columns")
+  }
+  for i := 0; i < n; i++ {
+    k := 0
+    for j := 1; j < m; j++ {
+      if a.Float64At(i, j) > a.Float64At(i, k) {
+        k = j
+      }
+    }
+    r.At(i).SetInt(k)
+  }
+  return r
+}
+
+// Store in r_j the row index of the largest element in column j of a. Ties
+// are resolved in favor of the smallest index.
+func (r StridedVector) MargmaxCols(a ConstMatrix) Vector {
+  n, m := a.Dims()
+  if r.Dim() != m {
+    panic("matrix/vector dimensions do not match!")
+  }
+  if n == 0 {
+    panic("cannot reduce matrix with zero rows")
+  }
+  for j := 0; j < m; j++ {
+    k := 0
+    for i := 1; i < n; i++ {
+      if a.Float64At(i, j) > a.Float64At(k, j) {
+        k = i
+      }
+    }
+    r.At(j).SetInt(k)
+  }
+  return r
+}
@@ -0,0 +1,109 @@
+/* Copyright (C) 2020 Philipp Benner
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autodiff
+
+/* -------------------------------------------------------------------------- */
+
+//import "fmt"
+import "testing"
+
+/* -------------------------------------------------------------------------- */
+
+func TestStridedVector(t *testing.T) {
+  m := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    4, 5, 6,
+    7, 8, 9 }, 3, 3)
+
+  r := RowView(m, 1)
+  c := ColView(m, 2)
+  d := DiagView(m)
+
+  if !r.Equals(NewDenseFloat64Vector([]float64{4, 5, 6}), 1e-12) {
+    t.Error("test failed")
+  }
+  if !c.Equals(NewDenseFloat64Vector([]float64{3, 6, 9}), 1e-12) {
+    t.Error("test failed")
+  }
+  if !d.Equals(NewDenseFloat64Vector([]float64{1, 5, 9}), 1e-12) {
+    t.Error("test failed")
+  }
+  // writes are visible in the matrix
+  c.VmulS(c, ConstFloat64(2.0))
+  d.Slice(0, 2).Reset()
+
+  if !m.Equals(NewDenseFloat64Matrix([]float64{
+    0, 2,  6,
+    4, 0, 12,
+    7, 8, 18 }, 3, 3), 1e-12) {
+    t.Error("test failed")
+  }
+  // matrix vector product with aliasing
+  r.VdotM(r, DenseIdentityMatrix(Float64Type, 3))
+  if !r.Equals(NewDenseFloat64Vector([]float64{4, 0, 12}), 1e-12) {
+    t.Error("test failed")
+  }
+  r.Sort(true)
+  if !RowView(m, 1).Equals(NewDenseFloat64Vector([]float64{12, 4, 0}), 1e-12) {
+    t.Error("test failed")
+  }
+}
+
+func TestStridedVectorSlice(t *testing.T) {
+  m := NewDenseFloat64Matrix([]float64{
+    1, 2, 3,
+    4, 5, 6,
+    7, 8, 9 }, 3, 3)
+
+  // view of a column of a transposed sub-block
+  c := ColView(m.Slice(1, 3, 1, 3).T(), 0)
+  if !c.Equals(NewDenseFloat64Vector([]float64{5, 6}), 1e-12) {
+    t.Error("test failed")
+  }
+  c.At(1).SetFloat64(-1)
+  if m.At(1, 2).GetFloat64() != -1 {
+    t.Error("test failed")
+  }
+  // sparse matrices
+  s := NullSparseFloat64Matrix(3, 3)
+  ColView(s, 1).Set(NewDenseFloat64Vector([]float64{1, 2, 3}))
+  if !s.Equals(NewDenseFloat64Matrix([]float64{
+    0, 1, 0,
+    0, 2, 0,
+    0, 3, 0 }, 3, 3), 1e-12) {
+    t.Error("test failed")
+  }
+}
+
+func TestStridedVectorReal(t *testing.T) {
+  m := NewDenseReal64Matrix([]float64{
+    1, 2,
+    3, 4 }, 2, 2)
+  x := NewReal64(2.0)
+  x.Alloc(1, 1)
+  x.SetDerivative(0, 1.0)
+
+  d := DiagView(m)
+  d.VmulS(d, x)
+
+  if v := m.At(1, 1).GetDerivative(0); v != 4.0 {
+    t.Errorf("test failed: %v", v)
+  }
+  if v := m.At(0, 1).GetDerivative(0); v != 0.0 {
+    t.Errorf("test failed: %v", v)
+  }
+}